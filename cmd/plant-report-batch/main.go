@@ -0,0 +1,27 @@
+// Command plant-report-batch is the Lambda triggered by CSV uploads under batches/incoming/ in the
+// report bucket. It generates a report for each plant_id,lat,lon row and writes a manifest CSV
+// with every row's status and link under batches/manifests/.
+package main
+
+import (
+	"log"
+
+	"github.com/HealthyTechGuy/plant-report-app/internal/app"
+	"github.com/HealthyTechGuy/plant-report-app/internal/config"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/logger"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Error loading configuration: %v", err)
+	}
+	_, processor, err := app.BatchFromConfig(cfg)
+	if err != nil {
+		log.Fatalf("Error initialising batch processor: %v", err)
+	}
+	defer logger.SyncLogger()
+
+	lambda.Start(processor.HandleS3Event)
+}
@@ -0,0 +1,26 @@
+// Command plant-report-cleanup is the Lambda run on a schedule to delete stored reports that no
+// unexpired metadata record refers to, once their tier's retention has passed.
+package main
+
+import (
+	"log"
+
+	"github.com/HealthyTechGuy/plant-report-app/internal/app"
+	"github.com/HealthyTechGuy/plant-report-app/internal/config"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/logger"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Error loading configuration: %v", err)
+	}
+	cleaner, err := app.CleanupFromConfig(cfg)
+	if err != nil {
+		log.Fatalf("Error initialising cleanup: %v", err)
+	}
+	defer logger.SyncLogger()
+
+	lambda.Start(cleaner.Run)
+}
@@ -0,0 +1,135 @@
+// Command plant-report-cli generates a single report from the command line, using the same
+// wiring as the Lambda, and prints the JSON response. With -batch it generates a report for each
+// plant_id,lat,lon row of a CSV and writes a manifest CSV with every row's status and link. With
+// -cleanup it deletes stored reports whose metadata records have expired.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/HealthyTechGuy/plant-report-app/internal/app"
+	"github.com/HealthyTechGuy/plant-report-app/internal/batch"
+	"github.com/HealthyTechGuy/plant-report-app/internal/config"
+	"github.com/HealthyTechGuy/plant-report-app/internal/retention"
+	"github.com/HealthyTechGuy/plant-report-app/models"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/logger"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func main() {
+	var req models.Request
+	flag.StringVar(&req.PlantID, "plant", "", "plant ID to report on")
+	flag.Float64Var(&req.Location.Latitude, "lat", 0, "latitude of the growing location")
+	flag.Float64Var(&req.Location.Longitude, "lon", 0, "longitude of the growing location")
+	flag.StringVar(&req.Units, "units", "", "metric or imperial, defaults by location")
+	batchFile := flag.String("batch", "", "CSV of plant_id,lat,lon rows to generate reports for")
+	manifestFile := flag.String("manifest", "", "where to write the batch manifest, defaults to stdout")
+	cleanup := flag.Bool("cleanup", false, "delete stored reports without an unexpired metadata record")
+	dryRun := flag.Bool("dry-run", false, "with -cleanup, list what would be deleted without deleting it")
+	showConfig := flag.Bool("show-config", false, "print the effective configuration, with secrets redacted, and exit")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Error loading configuration: %v", err)
+	}
+	if *showConfig {
+		fmt.Print(cfg)
+		return
+	}
+
+	if *batchFile != "" {
+		if err := runBatch(cfg, *batchFile, *manifestFile); err != nil {
+			log.Fatalf("Error running batch: %v", err)
+		}
+		return
+	}
+
+	if *cleanup {
+		if err := runCleanup(cfg, *dryRun); err != nil {
+			log.Fatalf("Error running cleanup: %v", err)
+		}
+		return
+	}
+
+	a, err := app.FromConfig(cfg)
+	if err != nil {
+		log.Fatalf("Error initialising plant report app: %v", err)
+	}
+	defer logger.SyncLogger()
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		log.Fatalf("Error encoding request: %v", err)
+	}
+	response, err := a.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Path:       "/report",
+		Body:       string(body),
+	})
+	if err != nil {
+		log.Fatalf("Error generating report: %v", err)
+	}
+
+	fmt.Println(response.Body)
+	if response.StatusCode >= 400 {
+		os.Exit(1)
+	}
+}
+
+// runBatch generates the reports for a local batch CSV and writes its manifest
+func runBatch(cfg config.Config, batchFile, manifestFile string) error {
+	a, _, err := app.BatchFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+	defer logger.SyncLogger()
+
+	in, err := os.Open(batchFile)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	rows, err := batch.ReadRows(in)
+	if err != nil {
+		return err
+	}
+
+	out := os.Stdout
+	if manifestFile != "" {
+		if out, err = os.Create(manifestFile); err != nil {
+			return err
+		}
+		defer out.Close()
+	}
+
+	results := batch.Run(context.Background(), a.HandleRequest, rows, cfg.BatchWorkers)
+	if err := batch.WriteManifest(out, results); err != nil {
+		return err
+	}
+	log.Printf("Batch finished: %v", batch.Counts(results))
+	return nil
+}
+
+// runCleanup deletes orphaned reports and prints what was deleted
+func runCleanup(cfg config.Config, dryRun bool) error {
+	var opts []retention.Option
+	if dryRun {
+		opts = append(opts, retention.DryRun())
+	}
+	cleaner, err := app.CleanupFromConfig(cfg, opts...)
+	if err != nil {
+		return err
+	}
+	defer logger.SyncLogger()
+
+	result, err := cleaner.Run(context.Background())
+	out, _ := json.MarshalIndent(result, "", "  ")
+	fmt.Println(string(out))
+	return err
+}
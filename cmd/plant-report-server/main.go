@@ -0,0 +1,70 @@
+// Command plant-report-server serves the report API over plain HTTP for local development,
+// using the same wiring as the Lambda. Point AWS_ENDPOINT_URL at LocalStack to run it offline.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/HealthyTechGuy/plant-report-app/internal/app"
+	"github.com/HealthyTechGuy/plant-report-app/internal/config"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/apigw"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/logger"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Error loading configuration: %v", err)
+	}
+	deps, err := app.Dependencies(cfg)
+	if err != nil {
+		log.Fatalf("Error initialising plant report app: %v", err)
+	}
+	// Unlike the Lambda, the server keeps running after a response, so callbacks needn't hold it
+	deps.BackgroundCallbacks = true
+	a, err := app.New(deps)
+	if err != nil {
+		log.Fatalf("Error initialising plant report app: %v", err)
+	}
+	defer logger.SyncLogger()
+
+	server := &http.Server{Addr: *addr, Handler: routes(a.Handler())}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		log.Printf("Listening on %s", *addr)
+		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Server stopped: %v", err)
+		}
+	}()
+
+	// On interrupt, finish the requests in flight and deliver the callbacks they still owe
+	<-ctx.Done()
+	if err := server.Shutdown(context.Background()); err != nil {
+		log.Printf("Error shutting down: %v", err)
+	}
+	a.WaitForCallbacks()
+}
+
+// routes serves the API's routes through handle, the same handler the Lambda runs
+func routes(handle apigw.HandlerFunc) *http.ServeMux {
+	mux := http.NewServeMux()
+	handler := apigw.HTTPHandler(handle)
+	mux.Handle("POST /report", handler)
+	mux.Handle("GET /report/{id}", handler)
+	mux.Handle("GET /me/reports", handler)
+	mux.Handle("OPTIONS /report", handler)
+	mux.Handle("OPTIONS /report/{id}", handler)
+	mux.Handle("OPTIONS /me/reports", handler)
+	return mux
+}
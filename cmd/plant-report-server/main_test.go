@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/HealthyTechGuy/plant-report-app/internal/app"
+	"github.com/HealthyTechGuy/plant-report-app/internal/plant-service/mocks"
+	"github.com/HealthyTechGuy/plant-report-app/internal/reports"
+	"github.com/HealthyTechGuy/plant-report-app/models"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/apigw"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/climate"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/frost"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoutes_GetReportByID(t *testing.T) {
+	mockPlantService := new(mocks.MockPlantService)
+	mockPDFGenerator := new(mocks.MockPDFGenerator)
+	mockClimateProvider := new(mocks.MockClimateProvider)
+	mockPlantService.On("GetPlantInfo", mock.Anything, "kale").Return(models.PlantInfo{ID: "kale", Name: "Kale"}, nil)
+	mockClimateProvider.On("Normals", mock.Anything, mock.Anything).Return(climate.Normals{}, climate.ErrNoData)
+	mockPDFGenerator.On("GeneratePDF", mock.Anything).Return([]byte("%PDF-1.3 kale"), nil)
+
+	estimator, err := frost.NewEstimator()
+	require.NoError(t, err)
+	a, err := app.New(app.Config{
+		Catalog:    mockPlantService,
+		Renderer:   mockPDFGenerator,
+		Climate:    mockClimateProvider,
+		Frost:      estimator,
+		Store:      storage.NewMemoryStore(),
+		Reports:    reports.NewMemoryStore(),
+		Middleware: []apigw.Middleware{apigw.NewCORS([]string{"https://app.example.com"}).Middleware},
+	})
+	require.NoError(t, err)
+	server := httptest.NewServer(routes(a.Handler()))
+	t.Cleanup(server.Close)
+
+	response, err := http.Post(server.URL+"/report", "application/json",
+		strings.NewReader(`{"location":{"latitude":51.5,"longitude":-0.12},"plant_id":"kale"}`))
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	var summary models.Response
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&summary))
+	require.NotEmpty(t, summary.ReportID)
+
+	response, err = http.Get(server.URL + "/report/" + summary.ReportID)
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	var metadata models.ReportMetadata
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&metadata))
+	assert.Equal(t, summary.ReportID, metadata.ReportID)
+	assert.Equal(t, []string{"kale"}, metadata.PlantIDs)
+	assert.NotEmpty(t, metadata.DownloadURL)
+
+	request, err := http.NewRequest(http.MethodOptions, server.URL+"/report/"+summary.ReportID, nil)
+	require.NoError(t, err)
+	request.Header.Set("Origin", "https://app.example.com")
+	request.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	response, err = http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer response.Body.Close()
+	assert.Equal(t, http.StatusNoContent, response.StatusCode)
+	assert.Equal(t, "https://app.example.com", response.Header.Get("Access-Control-Allow-Origin"))
+}
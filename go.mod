@@ -5,6 +5,7 @@ go 1.22.2
 require (
 	github.com/aws/aws-lambda-go v1.47.0
 	github.com/aws/aws-sdk-go v1.55.5
+	golang.org/x/sync v0.10.0
 )
 
 require (
@@ -3,8 +3,16 @@ import { Construct } from 'constructs';
 import * as lambda from 'aws-cdk-lib/aws-lambda';
 import * as apigateway from 'aws-cdk-lib/aws-apigateway';
 import * as s3 from 'aws-cdk-lib/aws-s3';
+import * as s3n from 'aws-cdk-lib/aws-s3-notifications';
 import * as iam from 'aws-cdk-lib/aws-iam';
 import * as dynamodb from 'aws-cdk-lib/aws-dynamodb';  // Import DynamoDB
+import * as events from 'aws-cdk-lib/aws-events';
+import * as targets from 'aws-cdk-lib/aws-events-targets';
+
+// How many days each client tier's reports are kept, * covers every other tier
+const reportRetention = 'free=7,partner=90,user=30,*=30';
+// Backstop expiry for reports the cleanup job misses, keep it above the longest retention
+const maxRetentionDays = 365;
 
 export class PlantReportStack extends cdk.Stack {
     constructor(scope: Construct, id: string, props?: cdk.StackProps) {
@@ -16,6 +24,19 @@ export class PlantReportStack extends cdk.Stack {
             removalPolicy: cdk.RemovalPolicy.DESTROY,
             versioned: true,
             publicReadAccess: false,
+            lifecycleRules: [
+                {
+                    // Overwritten reports and those deleted by the cleanup job leave noncurrent
+                    // versions behind, which would otherwise be kept forever
+                    id: 'purge-old-versions',
+                    noncurrentVersionExpiration: cdk.Duration.days(1),
+                    expiredObjectDeleteMarker: true,
+                    abortIncompleteMultipartUploadAfter: cdk.Duration.days(1),
+                },
+                { id: 'expire-reports', prefix: 'reports/', expiration: cdk.Duration.days(maxRetentionDays) },
+                { id: 'expire-user-reports', prefix: 'users/', expiration: cdk.Duration.days(maxRetentionDays) },
+                { id: 'expire-batches', prefix: 'batches/', expiration: cdk.Duration.days(30) },
+            ],
         });
 
         // Create DynamoDB table
@@ -26,27 +47,114 @@ export class PlantReportStack extends cdk.Stack {
             removalPolicy: cdk.RemovalPolicy.DESTROY,  // Remove the table when the stack is deleted
         });
 
+        // API keys and bearer tokens, keyed by the SHA-256 of the credential
+        const apiKeysTable = new dynamodb.Table(this, 'PlantReportApiKeysTable', {
+            tableName: 'plant-report-api-keys',
+            partitionKey: { name: 'KeyHash', type: dynamodb.AttributeType.STRING },
+            billingMode: dynamodb.BillingMode.PAY_PER_REQUEST,
+            removalPolicy: cdk.RemovalPolicy.DESTROY,
+        });
+
+        // Daily report counts per client, expired by DynamoDB TTL
+        const usageTable = new dynamodb.Table(this, 'PlantReportUsageTable', {
+            tableName: 'plant-report-usage',
+            partitionKey: { name: 'UsageKey', type: dynamodb.AttributeType.STRING },
+            billingMode: dynamodb.BillingMode.PAY_PER_REQUEST,
+            timeToLiveAttribute: 'expires_at',
+            removalPolicy: cdk.RemovalPolicy.DESTROY,
+        });
+
+        // Reports generated by signed-in users, newest first within each user, expired by DynamoDB
+        // TTL once the user tier's retention has passed
+        const historyTable = new dynamodb.Table(this, 'PlantReportHistoryTable', {
+            tableName: 'plant-report-history',
+            partitionKey: { name: 'UserID', type: dynamodb.AttributeType.STRING },
+            sortKey: { name: 'CreatedReport', type: dynamodb.AttributeType.STRING },
+            billingMode: dynamodb.BillingMode.PAY_PER_REQUEST,
+            timeToLiveAttribute: 'expires_at',
+            removalPolicy: cdk.RemovalPolicy.DESTROY,
+        });
+
+        // Metadata of every generated report, looked up by GET /report/{id} and expired by DynamoDB
+        // TTL once the client tier's retention has passed
+        const reportsTable = new dynamodb.Table(this, 'PlantReportReportsTable', {
+            tableName: 'plant-report-reports',
+            partitionKey: { name: 'ReportID', type: dynamodb.AttributeType.STRING },
+            billingMode: dynamodb.BillingMode.PAY_PER_REQUEST,
+            timeToLiveAttribute: 'expires_at',
+            removalPolicy: cdk.RemovalPolicy.DESTROY,
+        });
+
+        // Token buckets shared by all Lambda instances, expired by DynamoDB TTL once full again
+        const rateLimitTable = new dynamodb.Table(this, 'PlantReportRateLimitTable', {
+            tableName: 'plant-report-rate-limits',
+            partitionKey: { name: 'BucketKey', type: dynamodb.AttributeType.STRING },
+            billingMode: dynamodb.BillingMode.PAY_PER_REQUEST,
+            timeToLiveAttribute: 'expires_at',
+            removalPolicy: cdk.RemovalPolicy.DESTROY,
+        });
+
         // Define Lambda function for handling the requests
         const plantReportLambda = new lambda.Function(this, 'PlantReportLambda', {
             runtime: lambda.Runtime.PROVIDED_AL2,
             code: lambda.Code.fromAsset('../dist/plant-report-lambda.zip'),
             handler: 'bootstrap',  // Dummy value, not used by custom runtime
+            timeout: cdk.Duration.seconds(30),
             environment: {
                 TABLE_NAME: plantReportTable.tableName,  // Use table name from the table resource
                 BUCKET_NAME: reportBucket.bucketName,
+                LOG_LEVEL: 'info',
+                API_KEYS_TABLE: apiKeysTable.tableName,
+                USAGE_TABLE: usageTable.tableName,
+                HISTORY_TABLE: historyTable.tableName,
+                REPORTS_TABLE: reportsTable.tableName,
+                REPORT_RETENTION: reportRetention,
+                RATE_LIMIT_TABLE: rateLimitTable.tableName,
+                // Set JWKS_FILE, JWT_ISSUER and JWT_AUDIENCE to accept user pool tokens
+                // Set MAIL_FROM to a verified SES identity to email reports
             },
         });
 
         // Create IAM policy for DynamoDB access
         const dynamoPolicy = new iam.PolicyStatement({
-            actions: ['dynamodb:GetItem'],
+            actions: ['dynamodb:GetItem', 'dynamodb:Scan'],
             resources: [
                 plantReportTable.tableArn  // Use the ARN of the table created in the stack
             ],
         });
 
+        const authPolicy = new iam.PolicyStatement({
+            actions: ['dynamodb:GetItem'],
+            resources: [apiKeysTable.tableArn],
+        });
+
+        const usagePolicy = new iam.PolicyStatement({
+            actions: ['dynamodb:UpdateItem'],
+            resources: [usageTable.tableArn],
+        });
+
+        const historyPolicy = new iam.PolicyStatement({
+            actions: ['dynamodb:PutItem', 'dynamodb:Query'],
+            resources: [historyTable.tableArn],
+        });
+
+        const reportsPolicy = new iam.PolicyStatement({
+            actions: ['dynamodb:PutItem', 'dynamodb:GetItem'],
+            resources: [reportsTable.tableArn],
+        });
+
+        const rateLimitPolicy = new iam.PolicyStatement({
+            actions: ['dynamodb:GetItem', 'dynamodb:PutItem'],
+            resources: [rateLimitTable.tableArn],
+        });
+
+        const sesPolicy = new iam.PolicyStatement({
+            actions: ['ses:SendRawEmail'],
+            resources: ['*'],
+        });
+
         const s3Policy = new iam.PolicyStatement({
-            actions: ['s3:PutObject'],
+            actions: ['s3:PutObject', 's3:GetObject', 's3:DeleteObject'],
             resources: [
                 'arn:aws:s3:::plant-report-bucket/*',
             ],
@@ -54,15 +162,92 @@ export class PlantReportStack extends cdk.Stack {
 
         // Attach the policy to the Lambda function's role
         plantReportLambda.addToRolePolicy(dynamoPolicy);
+        plantReportLambda.addToRolePolicy(authPolicy);
+        plantReportLambda.addToRolePolicy(usagePolicy);
+        plantReportLambda.addToRolePolicy(historyPolicy);
+        plantReportLambda.addToRolePolicy(reportsPolicy);
+        plantReportLambda.addToRolePolicy(rateLimitPolicy);
+        plantReportLambda.addToRolePolicy(sesPolicy);
         plantReportLambda.addToRolePolicy(s3Policy);
 
+        // Batch Lambda, run for each CSV uploaded under batches/incoming/. Manifests are written
+        // under batches/manifests/, outside the notification prefix, so they start no new batch.
+        const batchLambda = new lambda.Function(this, 'PlantReportBatchLambda', {
+            runtime: lambda.Runtime.PROVIDED_AL2,
+            code: lambda.Code.fromAsset('../dist/plant-report-batch.zip'),
+            handler: 'bootstrap',
+            timeout: cdk.Duration.minutes(15),
+            memorySize: 1024,
+            environment: {
+                TABLE_NAME: plantReportTable.tableName,
+                BUCKET_NAME: reportBucket.bucketName,
+                LOG_LEVEL: 'info',
+                AUTH: 'off',  // Rows are trusted, whoever can upload to the bucket may run batches
+                BATCH_WORKERS: '8',
+                REPORTS_TABLE: reportsTable.tableName,
+                REPORT_RETENTION: reportRetention,
+            },
+        });
+        batchLambda.addToRolePolicy(dynamoPolicy);
+        batchLambda.addToRolePolicy(reportsPolicy);
+        batchLambda.addToRolePolicy(s3Policy);
+        reportBucket.addEventNotification(
+            s3.EventType.OBJECT_CREATED,
+            new s3n.LambdaDestination(batchLambda),
+            { prefix: 'batches/incoming/', suffix: '.csv' },
+        );
+
+        // Cleanup Lambda, run daily to delete reports whose metadata records have expired
+        const cleanupLambda = new lambda.Function(this, 'PlantReportCleanupLambda', {
+            runtime: lambda.Runtime.PROVIDED_AL2,
+            code: lambda.Code.fromAsset('../dist/plant-report-cleanup.zip'),
+            handler: 'bootstrap',
+            timeout: cdk.Duration.minutes(15),
+            memorySize: 512,
+            environment: {
+                TABLE_NAME: plantReportTable.tableName,
+                BUCKET_NAME: reportBucket.bucketName,
+                LOG_LEVEL: 'info',
+                AUTH: 'off',
+                REPORTS_TABLE: reportsTable.tableName,
+            },
+        });
+        cleanupLambda.addToRolePolicy(new iam.PolicyStatement({
+            actions: ['dynamodb:Scan'],
+            resources: [reportsTable.tableArn],
+        }));
+        cleanupLambda.addToRolePolicy(new iam.PolicyStatement({
+            actions: ['s3:DeleteObject'],
+            resources: ['arn:aws:s3:::plant-report-bucket/*'],
+        }));
+        // Listing finds the stored reports under the report prefixes that no live record points at
+        cleanupLambda.addToRolePolicy(new iam.PolicyStatement({
+            actions: ['s3:ListBucket'],
+            resources: ['arn:aws:s3:::plant-report-bucket'],
+        }));
+        new events.Rule(this, 'PlantReportCleanupSchedule', {
+            schedule: events.Schedule.rate(cdk.Duration.days(1)),
+            targets: [new targets.LambdaFunction(cleanupLambda)],
+        });
+
         // Define API Gateway to trigger the Lambda
         const api = new apigateway.LambdaRestApi(this, 'PlantReportApi', {
             handler: plantReportLambda,
             proxy: false,
+            // Download mode returns base64 PDFs, decoded for clients sending Accept: application/pdf
+            binaryMediaTypes: ['application/pdf'],
         });
 
         const plantResource = api.root.addResource('report');
         plantResource.addMethod('POST');
+        plantResource.addMethod('OPTIONS');  // CORS preflight, answered by the Lambda
+
+        const reportByIdResource = plantResource.addResource('{id}');
+        reportByIdResource.addMethod('GET');
+        reportByIdResource.addMethod('OPTIONS');
+
+        const historyResource = api.root.addResource('me').addResource('reports');
+        historyResource.addMethod('GET');
+        historyResource.addMethod('OPTIONS');
     }
 }
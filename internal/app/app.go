@@ -0,0 +1,608 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HealthyTechGuy/plant-report-app/internal/history"
+	"github.com/HealthyTechGuy/plant-report-app/internal/pipeline"
+	plant "github.com/HealthyTechGuy/plant-report-app/internal/plant-service"
+	"github.com/HealthyTechGuy/plant-report-app/internal/reportcache"
+	"github.com/HealthyTechGuy/plant-report-app/internal/reports"
+	"github.com/HealthyTechGuy/plant-report-app/internal/retention"
+	"github.com/HealthyTechGuy/plant-report-app/internal/webhook"
+	models "github.com/HealthyTechGuy/plant-report-app/models" // Import shared models
+	"github.com/HealthyTechGuy/plant-report-app/pkg/apigw"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/climate"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/companion"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/frost"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/mailer"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/pdf"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/pests"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/storage"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/suitability"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/units"
+	"github.com/aws/aws-lambda-go/events"
+	"go.uber.org/zap"
+)
+
+// maxAlternatives is how many alternative plants are suggested for a poor fit
+const maxAlternatives = 3
+
+// maxNeighbours is how many other plants a request may say it grows alongside its plant
+const maxNeighbours = 10
+
+// maxCompanionSuggestions is how many plants are suggested to join a planting of several plants
+const maxCompanionSuggestions = 3
+
+// defaultReportKey is the object key reports are stored under when report caching is off
+const defaultReportKey = "file.pdf"
+
+// DefaultParallelism is how many lookups run at once while building a report
+const DefaultParallelism = 4
+
+// errCacheHit ends the report lookups once a cached report is found
+var errCacheHit = errors.New("cached report found")
+
+// defaultLinkTTL is how long report history download links last
+const defaultLinkTTL = time.Hour
+
+// Config lists the dependencies an App is built from
+type Config struct {
+	// Catalog looks up plant information
+	Catalog plant.PlantServiceInterface
+	// Renderer turns a report into a PDF
+	Renderer pdf.Renderer
+	// Store keeps generated reports when Cache is nil
+	Store storage.Store
+	// ReportKey is the object key uncached reports are written to, defaults to file.pdf
+	ReportKey string
+	// Cache serves previously generated reports, nil disables report caching
+	Cache *reportcache.Cache
+	// Climate provides climate normals for a location
+	Climate climate.ClimateProvider
+	// Frost estimates frost dates when no climate normals are available
+	Frost *frost.Estimator
+	// Logger defaults to a no-op logger
+	Logger *zap.Logger
+	// Clock defaults to time.Now
+	Clock func() time.Time
+	// Middleware wraps the routes in Handler, outermost first
+	Middleware []apigw.Middleware
+	// History records reports generated for signed-in users, nil disables GET /me/reports
+	History history.Store
+	// LinkTTL is how long download links handed out by GET /me/reports and oversized downloads
+	// last, defaults to an hour
+	LinkTTL time.Duration
+	// InlineLimit is the largest PDF in bytes returned directly in download mode, defaults to
+	// DefaultInlineLimit. Larger reports are answered with a link instead.
+	InlineLimit int
+	// Mailer emails reports to the address in the request, nil disables email delivery
+	Mailer mailer.Mailer
+	// AttachmentLimit is the largest PDF in bytes attached to an email, defaults to
+	// DefaultAttachmentLimit. Larger reports are emailed as a link.
+	AttachmentLimit int
+	// Webhooks notifies callback URLs when reports complete or fail, nil disables callback_url
+	Webhooks *webhook.Sender
+	// BackgroundCallbacks delivers callbacks after the response has been returned. Only long-running
+	// processes such as the local server should set it: Lambda freezes the sandbox once the handler
+	// returns, so there callbacks are delivered before returning, within the invocation deadline.
+	BackgroundCallbacks bool
+	// Parallelism is how many independent lookups run at once while building a report, defaults
+	// to DefaultParallelism. 1 runs them one after another.
+	Parallelism int
+	// Reports records the metadata of every report, nil disables GET /report/{id}
+	Reports reports.Store
+	// Retention sets when each report's metadata record expires by client tier, nil keeps
+	// records forever
+	Retention retention.Policy
+	// Companions adds a companion planting section built from the relationships recorded in the
+	// catalog, and lets requests list the neighbours their plant is grown with
+	Companions bool
+	// Pests ranks the plant's pests and diseases against the local climate, nil leaves the
+	// section out
+	Pests *pests.Catalog
+}
+
+// App handles report requests. It holds no global state, so any number of Apps
+// can serve requests side by side.
+type App struct {
+	catalog         plant.PlantServiceInterface
+	renderer        pdf.Renderer
+	store           storage.Store
+	reportKey       string
+	cache           *reportcache.Cache
+	climate         climate.ClimateProvider
+	frost           *frost.Estimator
+	logger          *zap.Logger
+	clock           func() time.Time
+	middleware      []apigw.Middleware
+	history         history.Store
+	linkTTL         time.Duration
+	inlineLimit     int
+	mailer          mailer.Mailer
+	attachmentLimit int
+	webhooks        *webhook.Sender
+	backgroundHooks bool
+	parallelism     int
+	reports         reports.Store
+	retention       retention.Policy
+	companions      bool
+	pests           *pests.Catalog
+
+	// callbacks tracks callback deliveries still in flight
+	callbacks sync.WaitGroup
+}
+
+// New creates an App, returning an error when a required dependency is missing
+func New(cfg Config) (*App, error) {
+	switch {
+	case cfg.Catalog == nil:
+		return nil, errors.New("app: a plant catalog is required")
+	case cfg.Renderer == nil:
+		return nil, errors.New("app: a renderer is required")
+	case cfg.Store == nil:
+		return nil, errors.New("app: a report store is required")
+	case cfg.Climate == nil:
+		return nil, errors.New("app: a climate provider is required")
+	case cfg.Frost == nil:
+		return nil, errors.New("app: a frost estimator is required")
+	}
+
+	a := &App{
+		catalog:         cfg.Catalog,
+		renderer:        cfg.Renderer,
+		store:           cfg.Store,
+		reportKey:       cfg.ReportKey,
+		cache:           cfg.Cache,
+		climate:         cfg.Climate,
+		frost:           cfg.Frost,
+		logger:          cfg.Logger,
+		clock:           cfg.Clock,
+		middleware:      cfg.Middleware,
+		history:         cfg.History,
+		linkTTL:         cfg.LinkTTL,
+		inlineLimit:     cfg.InlineLimit,
+		mailer:          cfg.Mailer,
+		attachmentLimit: cfg.AttachmentLimit,
+		webhooks:        cfg.Webhooks,
+		backgroundHooks: cfg.BackgroundCallbacks,
+		parallelism:     cfg.Parallelism,
+		reports:         cfg.Reports,
+		retention:       cfg.Retention,
+		companions:      cfg.Companions,
+		pests:           cfg.Pests,
+	}
+	if a.logger == nil {
+		a.logger = zap.NewNop()
+	}
+	if a.clock == nil {
+		a.clock = time.Now
+	}
+	if a.reportKey == "" {
+		a.reportKey = defaultReportKey
+	}
+	if a.linkTTL <= 0 {
+		a.linkTTL = defaultLinkTTL
+	}
+	if a.inlineLimit <= 0 {
+		a.inlineLimit = DefaultInlineLimit
+	}
+	if a.attachmentLimit <= 0 {
+		a.attachmentLimit = DefaultAttachmentLimit
+	}
+	if a.parallelism <= 0 {
+		a.parallelism = DefaultParallelism
+	}
+	return a, nil
+}
+
+// Handler returns the routes wrapped in the configured middleware, ready to serve requests
+func (a *App) Handler() apigw.HandlerFunc {
+	return apigw.Chain(a.route, a.middleware...)
+}
+
+// route dispatches a request by method and path. Requests without a method, such as
+// direct invocations, are treated as POST /report.
+func (a *App) route(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	path := strings.TrimSuffix(request.Path, "/")
+	switch {
+	case path == "/me/reports" && request.HTTPMethod == http.MethodGet:
+		return a.ListReports(ctx, request)
+	case path == "/report" && request.HTTPMethod == http.MethodPost,
+		request.HTTPMethod == "":
+		return a.HandleRequest(ctx, request)
+	case strings.HasPrefix(path, "/report/") && request.HTTPMethod == http.MethodGet:
+		return a.GetReport(ctx, strings.TrimPrefix(path, "/report/"))
+	case path == "/report" || path == "/me/reports" || strings.HasPrefix(path, "/report/"):
+		return responseWithError(405, "Method not allowed"), nil
+	default:
+		return responseWithError(404, "Not found"), nil
+	}
+}
+
+// HandleRequest handles a POST /report request from API Gateway
+func (a *App) HandleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (response events.APIGatewayProxyResponse, err error) {
+	var req models.Request
+
+	// Unmarshal the request body
+	if err := json.Unmarshal([]byte(request.Body), &req); err != nil {
+		a.logger.Info("invalid request body", zap.Error(err))
+		return responseWithError(400, "Invalid request body"), nil
+	}
+
+	// Validate the input (ensure PlantID and Location are provided)
+	if req.PlantID == "" || req.Location.Latitude == 0 || req.Location.Longitude == 0 {
+		a.logger.Info("invalid input: missing required fields")
+		return responseWithError(400, "Missing required fields: plant_id, latitude, and longitude"), nil
+	}
+
+	// Resolve the measurement system, defaulting by location when none was requested
+	system, err := units.Resolve(req.Units, req.Location.Latitude, req.Location.Longitude)
+	if err != nil {
+		a.logger.Info("invalid units preference", zap.Error(err))
+		return responseWithError(400, "Invalid units: must be metric or imperial"), nil
+	}
+
+	// Check the email address before doing any work, a report nobody receives is wasted
+	if req.Email != "" {
+		if a.mailer == nil {
+			return responseWithError(400, "Email delivery is not enabled"), nil
+		}
+		if req.Email, err = mailer.ValidateAddress(req.Email); err != nil {
+			a.logger.Info("invalid email address", zap.Error(err))
+			return responseWithError(400, "Invalid email address"), nil
+		}
+	}
+
+	// Partners can be called back when the report is ready instead of waiting on the response
+	var callbackSecret string
+	if req.CallbackURL != "" {
+		var problem string
+		if callbackSecret, problem = a.callbackSecret(ctx, req.CallbackURL); problem != "" {
+			return responseWithError(400, problem), nil
+		}
+	}
+
+	// Neighbours only mean something to the companion planting section
+	if len(req.Neighbours) > 0 {
+		if !a.companions {
+			return responseWithError(400, "Companion planting is not enabled"), nil
+		}
+		if len(req.Neighbours) > maxNeighbours {
+			return responseWithError(400, fmt.Sprintf("Too many neighbours: at most %d", maxNeighbours)), nil
+		}
+	}
+
+	// From here on the outcome is sent to the callback URL, whether the report was built or not
+	var summary models.Response
+	if req.CallbackURL != "" {
+		defer func() {
+			a.notifyCallback(ctx, req.CallbackURL, callbackSecret, req.PlantID, summary, response)
+		}()
+	}
+
+	// Get plant details from the catalog
+	plantInfo, err := a.catalog.GetPlantInfo(ctx, req.PlantID)
+	if cached, ok := a.catalog.(*plant.CachedPlantService); ok {
+		a.logger.Debug("plant cache", zap.Any("stats", cached.Stats()))
+	}
+	if err != nil {
+		a.logger.Error("error fetching plant info", zap.String("plant_id", req.PlantID), zap.Error(err))
+		if errors.Is(err, plant.ErrServiceUnavailable) {
+			return responseWithError(503, "Plant information is temporarily unavailable, please try again shortly"), nil
+		}
+		return responseWithError(500, "Failed to fetch plant information"), nil
+	}
+
+	usrLocation := models.UserLocation{
+		UserLatitude:  req.Location.Latitude,
+		UserLongitude: req.Location.Longitude,
+	}
+	now := a.clock()
+
+	// Reports are given an ID when something refers back to them: a signed-in user's history,
+	// a callback or the report metadata table
+	user, keepHistory := a.historyUser(ctx)
+	var reportID string
+	if keepHistory || req.CallbackURL != "" || a.reports != nil {
+		reportID = newReportID()
+	}
+
+	// With caching on, reports are built for the centre of the grid cell so nearby requests can share them
+	var cacheKey reportcache.Key
+	var locationRounding float64
+	if a.cache != nil {
+		cell := a.cache.Cell(req.Location.Latitude, req.Location.Longitude)
+		usrLocation.UserLatitude, usrLocation.UserLongitude = cell.Centre()
+		locationRounding = a.cache.CellSize()
+		cacheKey = reportcache.Key{
+			PlantID:         plantInfo.ID,
+			Fingerprint:     reportcache.Fingerprint(plantInfo, req.Neighbours...),
+			Cell:            cell,
+			Locale:          string(system),
+			Format:          "pdf",
+			TemplateVersion: pdf.TemplateVersion,
+			Date:            now.UTC().Format("2006-01-02"),
+		}
+	}
+
+	// The cached report and the climate normals are looked up at the same time. A cache hit ends
+	// the lookups early and cancels the climate request.
+	var (
+		entry   reportcache.Entry
+		cached  []byte
+		normals *climate.Normals
+	)
+	var steps []pipeline.Step
+	if a.cache != nil {
+		steps = append(steps, func(ctx context.Context) error {
+			e, hit, err := a.cache.Lookup(ctx, cacheKey)
+			if err != nil {
+				a.logger.Warn("error looking up cached report", zap.Error(err))
+				return nil
+			}
+			if !hit {
+				return nil
+			}
+			entry = e
+			// The PDF itself is only needed to download or attach it
+			if req.Download || req.Email != "" {
+				if cached, err = a.cache.Report(ctx, cacheKey); err != nil {
+					a.logger.Warn("error reading cached report, using its link", zap.Error(err))
+				}
+			}
+			return errCacheHit
+		})
+	}
+	steps = append(steps, func(ctx context.Context) error {
+		// The report is still useful without climate data
+		n, err := a.climate.Normals(ctx, usrLocation.UserLatitude, usrLocation.UserLongitude)
+		if err != nil {
+			if ctx.Err() == nil {
+				a.logger.Warn("climate data unavailable", zap.Error(err))
+			}
+			return nil
+		}
+		normals = &n
+		return nil
+	})
+
+	if err := pipeline.Run(ctx, a.parallelism, steps...); errors.Is(err, errCacheHit) {
+		a.logger.Info("serving cached report", zap.String("url", entry.URL))
+		storageKey := cacheKey.ObjectKey()
+		summary = models.Response{
+			ReportID:     reportID,
+			PDFUrl:       entry.URL,
+			Suitability:  entry.Suitability,
+			Alternatives: entry.Alternatives,
+			EmailStatus:  a.emailReport(ctx, req.Email, plantInfo, storageKey, entry.URL, cached, now),
+		}
+		if keepHistory {
+			a.recordHistory(ctx, user, summary, plantInfo.ID, usrLocation, storageKey, now)
+		}
+		a.recordReport(ctx, reportID, plantInfo.ID, entry.Alternatives, usrLocation, storedReport{
+			Size:     entry.Size,
+			Checksum: entry.Checksum,
+		}, storageKey, now)
+		if req.Download && cached != nil {
+			return a.downloadResponse(summary, plantInfo.ID, storageKey, cached), nil
+		}
+		return responseWithSuccess(200, summary), nil
+	}
+
+	// Work out concrete planting dates from the location's frost dates
+	schedule := a.planSchedule(plantInfo, normals, usrLocation.UserLatitude, usrLocation.UserLongitude, now)
+
+	// Score how well the plant suits the local climate
+	var assessment *suitability.Assessment
+	if normals != nil {
+		result := suitability.Assess(plantInfo.Requirements(), *normals, system)
+		assessment = &result
+	}
+
+	// The alternatives, pest risks and companion plan don't depend on each other, so they are
+	// worked out at the same time
+	var alternatives []models.Alternative
+	var pestRisks []pests.Risk
+	var companions *companion.Plan
+	err = pipeline.Run(ctx, a.parallelism,
+		func(ctx context.Context) error {
+			// Suggest similar plants that grow well here when this one is a poor fit
+			if assessment == nil || assessment.Verdict == suitability.Yes {
+				return nil
+			}
+			score := func(p models.PlantInfo) suitability.Assessment {
+				return suitability.Assess(p.Requirements(), *normals, system)
+			}
+			var err error
+			if alternatives, err = plant.RecommendAlternatives(ctx, a.catalog, plantInfo, score, maxAlternatives); err != nil {
+				a.logger.Warn("error recommending alternatives", zap.Error(err))
+			}
+			return nil
+		},
+		func(context.Context) error {
+			// Rank the plant's pests and diseases by how much the local climate favours them
+			if a.pests != nil {
+				pestRisks = a.pests.Assess(plantInfo.ID, normals)
+			}
+			return nil
+		},
+		func(ctx context.Context) error {
+			var err error
+			companions, err = a.planCompanions(ctx, plantInfo.ID, req.Neighbours)
+			return err
+		},
+	)
+	var unknown unknownNeighbourError
+	if errors.As(err, &unknown) {
+		return responseWithError(400, unknown.Error()), nil
+	}
+	if errors.Is(err, errCompanionsUnavailable) {
+		a.logger.Error("error checking neighbours", zap.Error(err))
+		return responseWithError(503, "Companion planting is temporarily unavailable, please try again shortly"), nil
+	}
+	if err != nil {
+		a.logger.Error("error building report sections", zap.Error(err))
+		return responseWithError(500, "Failed to build report"), nil
+	}
+
+	// Work out where the report is stored
+	var storageKey string
+	var upload func(ctx context.Context, body io.Reader) (string, error)
+	if a.cache != nil {
+		storageKey = cacheKey.ObjectKey()
+		entry := reportcache.Entry{
+			Suitability:  assessment,
+			Alternatives: alternatives,
+		}
+		upload = func(ctx context.Context, body io.Reader) (string, error) {
+			return a.cache.Put(ctx, cacheKey, body, "application/pdf", entry)
+		}
+	} else {
+		// Uncached reports share one key, so a report with an ID gets its own key to still be there
+		// when it is looked up again: from the user's history, by the partner called back or by ID
+		storageKey = a.reportKey
+		if keepHistory {
+			storageKey = "users/" + url.PathEscape(user.ID) + "/" + reportID + ".pdf"
+		} else if reportID != "" {
+			storageKey = "reports/" + reportID + ".pdf"
+		}
+		upload = func(ctx context.Context, body io.Reader) (string, error) {
+			return a.storeReport(ctx, storageKey, body)
+		}
+	}
+
+	// Generate the PDF report and store it. The PDF itself is only kept to download or attach it.
+	keep := 0
+	if req.Download {
+		keep = a.inlineLimit
+	}
+	if req.Email != "" && a.attachmentLimit > keep {
+		keep = a.attachmentLimit
+	}
+	stored, err := a.renderAndStore(ctx, models.Report{
+		Location:     usrLocation,
+		Plant:        plantInfo,
+		Units:        system,
+		Climate:      normals,
+		Schedule:     schedule,
+		Suitability:  assessment,
+		Alternatives: alternatives,
+		Companions:   companions,
+		PestRisks:    pestRisks,
+
+		LocationRounding: locationRounding,
+	}, keep, upload)
+	if errors.Is(err, errRender) {
+		a.logger.Error("error generating PDF report", zap.Error(err))
+		return responseWithError(500, "Failed to generate PDF report"), nil
+	}
+	if err != nil {
+		a.logger.Error("error storing PDF report", zap.Error(err))
+		return responseWithError(500, "Failed to store PDF report"), nil
+	}
+
+	a.logger.Info("uploaded PDF report", zap.String("url", stored.URL))
+	summary = models.Response{
+		ReportID:     reportID,
+		PDFUrl:       stored.URL,
+		Suitability:  assessment,
+		Alternatives: alternatives,
+		EmailStatus:  a.emailReport(ctx, req.Email, plantInfo, storageKey, stored.URL, stored.PDF, now),
+	}
+	if keepHistory {
+		a.recordHistory(ctx, user, summary, plantInfo.ID, usrLocation, storageKey, now)
+	}
+	a.recordReport(ctx, reportID, plantInfo.ID, alternatives, usrLocation, stored, storageKey, now)
+
+	if req.Download {
+		return a.downloadResponse(summary, plantInfo.ID, storageKey, stored.PDF), nil
+	}
+
+	// Return the success response with the PDF URL and growability score
+	return responseWithSuccess(200, summary), nil
+}
+
+// storeReport writes an uncached report to the store and returns its URL
+func (a *App) storeReport(ctx context.Context, key string, report io.Reader) (string, error) {
+	if err := a.store.Put(ctx, key, report, "application/pdf"); err != nil {
+		return "", fmt.Errorf("failed to store report: %w", err)
+	}
+	return a.store.URL(key), nil
+}
+
+// errCompanionsUnavailable marks a request listing neighbours that cannot be checked because the
+// companion planting graph could not be built
+var errCompanionsUnavailable = errors.New("companion planting unavailable")
+
+// unknownNeighbourError is a neighbour in the request that is missing from the catalog
+type unknownNeighbourError string
+
+func (e unknownNeighbourError) Error() string {
+	return "Unknown neighbour plant: " + string(e)
+}
+
+// planCompanions plans the companion planting for a plant grown with its neighbours, or returns
+// nil when companion planting is off. Without neighbours the report is still useful without the
+// plan, but neighbours that cannot be checked against the catalog fail the request, since they
+// also key the cached report.
+func (a *App) planCompanions(ctx context.Context, plantID string, neighbours []string) (*companion.Plan, error) {
+	if !a.companions {
+		return nil, nil
+	}
+	graph, err := plant.CompanionGraph(ctx, a.catalog)
+	if err != nil && len(neighbours) > 0 {
+		return nil, fmt.Errorf("%w: %w", errCompanionsUnavailable, err)
+	}
+	if err != nil {
+		a.logger.Warn("companion planting unavailable", zap.Error(err))
+		return nil, nil
+	}
+	for _, id := range neighbours {
+		if !graph.Has(id) {
+			return nil, unknownNeighbourError(id)
+		}
+	}
+	plan := graph.Plan(plantID, neighbours, maxCompanionSuggestions)
+	return &plan, nil
+}
+
+// planSchedule builds the planting schedule, preferring frost dates from the climate normals
+// and falling back to the nearest frost station when no climate data is available
+func (a *App) planSchedule(plantInfo models.PlantInfo, normals *climate.Normals, latitude, longitude float64, now time.Time) *frost.Schedule {
+	var dates frost.Dates
+	if normals != nil {
+		dates = normals.Frost
+	} else {
+		estimate, err := a.frost.Estimate(latitude, longitude)
+		if err != nil {
+			a.logger.Warn("frost dates unavailable", zap.Error(err))
+			return nil
+		}
+		dates = estimate.Dates
+	}
+
+	schedule := frost.Plan(plantInfo.DaysToMaturity, plantInfo.FrostTolerance, dates, now)
+	return &schedule
+}
+
+// responseWithSuccess creates a successful HTTP response from a report summary with the PDF URL,
+// suitability assessment and alternatives
+func responseWithSuccess(statusCode int, summary models.Response) events.APIGatewayProxyResponse {
+	summary.Message = "PDF report generated successfully"
+	return apigw.JSON(statusCode, summary, nil)
+}
+
+// responseWithError creates an HTTP error response
+func responseWithError(statusCode int, message string) events.APIGatewayProxyResponse {
+	return apigw.ErrorResponse(statusCode, message, nil)
+}
@@ -0,0 +1,405 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	plant "github.com/HealthyTechGuy/plant-report-app/internal/plant-service"
+	"github.com/HealthyTechGuy/plant-report-app/internal/plant-service/mocks"
+	"github.com/HealthyTechGuy/plant-report-app/internal/reportcache"
+	"github.com/HealthyTechGuy/plant-report-app/models"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/apigw"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/climate"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/frost"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/storage"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/units"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// testNow is the fixed clock used by every test App
+var testNow = time.Date(2026, time.January, 15, 12, 0, 0, 0, time.UTC)
+
+var loadEstimator = sync.OnceValues(frost.NewEstimator)
+
+// newTestApp builds an App from cfg, filling in mocks, an in-memory store, the bundled
+// frost data and a fixed clock for anything the test leaves unset
+func newTestApp(t testing.TB, cfg Config) *App {
+	t.Helper()
+	if cfg.Catalog == nil {
+		cfg.Catalog = new(mocks.MockPlantService)
+	}
+	if cfg.Renderer == nil {
+		cfg.Renderer = new(mocks.MockPDFGenerator)
+	}
+	if cfg.Climate == nil {
+		cfg.Climate = new(mocks.MockClimateProvider)
+	}
+	if cfg.Store == nil {
+		cfg.Store = storage.NewMemoryStore()
+	}
+	if cfg.Frost == nil {
+		estimator, err := loadEstimator()
+		require.NoError(t, err)
+		cfg.Frost = estimator
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = func() time.Time { return testNow }
+	}
+	a, err := New(cfg)
+	require.NoError(t, err)
+	t.Cleanup(a.WaitForCallbacks)
+	return a
+}
+
+func TestNew_RequiresDependencies(t *testing.T) {
+	t.Parallel()
+
+	_, err := New(Config{})
+	assert.ErrorContains(t, err, "catalog")
+
+	_, err = New(Config{Catalog: new(mocks.MockPlantService), Renderer: new(mocks.MockPDFGenerator)})
+	assert.ErrorContains(t, err, "store")
+}
+
+func TestHandleRequest_Success(t *testing.T) {
+	t.Parallel()
+	mockPlantService := new(mocks.MockPlantService)
+	mockPDFGenerator := new(mocks.MockPDFGenerator)
+	mockClimateProvider := new(mocks.MockClimateProvider)
+	store := storage.NewMemoryStore()
+
+	// Mock plant info
+	plantInfo := models.PlantInfo{
+		ID:              "blueberry",
+		Name:            "Blueberry Bush",
+		GrowingPeriod:   "6-8 months",
+		OptimalPlanting: "Spring",
+		HardinessZone:   "5-7",
+	}
+
+	// Mock plant service response
+	mockPlantService.On("GetPlantInfo", mock.Anything, "blueberry").Return(plantInfo, nil)
+
+	// Incomplete climate data scores as marginal, so the catalog is checked for alternatives
+	mockPlantService.On("ListPlants", mock.Anything).Return([]models.PlantInfo{}, nil)
+
+	// Mock climate lookup
+	normals := climate.Normals{Station: "Test Station"}
+	mockClimateProvider.On("Normals", 999.9, 999.9).Return(normals, nil)
+
+	// Mock PDF generation with []byte return type
+	mockPDFGenerator.On("GeneratePDF", mock.MatchedBy(func(r models.Report) bool { return assert.ObjectsAreEqual(plantInfo, r.Plant) })).Return([]byte("PDF content"), nil)
+
+	a := newTestApp(t, Config{
+		Catalog:  mockPlantService,
+		Renderer: mockPDFGenerator,
+		Climate:  mockClimateProvider,
+		Store:    store,
+	})
+
+	// Create a sample request
+	req := models.Request{
+		Location: struct {
+			Latitude  float64 `json:"latitude"`
+			Longitude float64 `json:"longitude"`
+		}{
+			Latitude:  999.9,
+			Longitude: 999.9,
+		},
+		PlantID: "blueberry",
+	}
+	reqBody, _ := json.Marshal(req)
+
+	// Call the handler
+	response, err := a.HandleRequest(context.TODO(), events.APIGatewayProxyRequest{
+		Body: string(reqBody),
+	})
+
+	// Assert no error occurred
+	assert.NoError(t, err)
+
+	// Assert response
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, "application/json", response.Headers["Content-Type"])
+	assert.Contains(t, response.Body, "PDF report generated successfully")
+	assert.Contains(t, response.Body, `"pdf_url":"memory://file.pdf"`)
+	assert.Contains(t, response.Body, `"suitability":{"score":50,"verdict":"marginal"`)
+
+	// Assert the report was rendered and stored
+	mockPlantService.AssertCalled(t, "GetPlantInfo", mock.Anything, "blueberry")
+	mockPDFGenerator.AssertCalled(t, "GeneratePDF", mock.MatchedBy(func(r models.Report) bool {
+		return r.Location == models.UserLocation{UserLatitude: 999.9, UserLongitude: 999.9} &&
+			assert.ObjectsAreEqual(plantInfo, r.Plant) &&
+			r.Units == units.Metric &&
+			r.Climate != nil && r.Climate.Station == normals.Station &&
+			r.Schedule != nil && r.Schedule.FrostFree &&
+			r.Suitability != nil
+	}))
+	stored, err := store.Get(context.TODO(), "file.pdf")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("PDF content"), stored)
+}
+
+func TestHandleRequest_InvalidRequestBody(t *testing.T) {
+	t.Parallel()
+	a := newTestApp(t, Config{})
+
+	// Call the handler with an invalid body
+	response, err := a.HandleRequest(context.TODO(), events.APIGatewayProxyRequest{
+		Body: "invalid",
+	})
+
+	// Assert an error occurred
+	assert.NoError(t, err)
+
+	// Assert response
+	assert.Equal(t, 400, response.StatusCode)
+	assert.Contains(t, response.Body, "Invalid request body")
+}
+
+func TestHandleRequest_MissingFields(t *testing.T) {
+	t.Parallel()
+	a := newTestApp(t, Config{})
+
+	// Create a sample request with missing fields
+	req := models.Request{
+		Location: struct {
+			Latitude  float64 `json:"latitude"`
+			Longitude float64 `json:"longitude"`
+		}{
+			Latitude:  0,
+			Longitude: 0,
+		},
+		PlantID: "blueberry",
+	}
+	reqBody, _ := json.Marshal(req)
+
+	// Call the handler
+	response, err := a.HandleRequest(context.TODO(), events.APIGatewayProxyRequest{
+		Body: string(reqBody),
+	})
+
+	// Assert an error occurred
+	assert.NoError(t, err)
+
+	// Assert response
+	assert.Equal(t, 400, response.StatusCode)
+	assert.Contains(t, response.Body, "Missing required fields: plant_id, latitude, and longitude")
+}
+
+func TestHandleRequest_FailedPlantInfo(t *testing.T) {
+	t.Parallel()
+	mockPlantService := new(mocks.MockPlantService)
+	plantInfo := models.PlantInfo{}
+	// Mock plant service to return an error
+	mockPlantService.On("GetPlantInfo", mock.Anything, "1").Return(plantInfo, assert.AnError)
+
+	a := newTestApp(t, Config{Catalog: mockPlantService})
+
+	// Create a sample request
+	req := models.Request{
+		Location: struct {
+			Latitude  float64 `json:"latitude"`
+			Longitude float64 `json:"longitude"`
+		}{
+			Latitude:  40.7128,
+			Longitude: -74.0060,
+		},
+		PlantID: "1",
+	}
+	reqBody, _ := json.Marshal(req)
+
+	// Call the handler
+	response, err := a.HandleRequest(context.TODO(), events.APIGatewayProxyRequest{
+		Body: string(reqBody),
+	})
+
+	// Assert no error occurred
+	assert.NoError(t, err)
+
+	// Assert response
+	assert.Equal(t, 500, response.StatusCode)
+	assert.Contains(t, response.Body, "Failed to fetch plant information")
+
+	// Assert that the plant service was called
+	mockPlantService.AssertCalled(t, "GetPlantInfo", mock.Anything, "1")
+}
+
+func TestHandleRequest_DefaultsToImperialInUS(t *testing.T) {
+	t.Parallel()
+	mockPlantService := new(mocks.MockPlantService)
+	mockPDFGenerator := new(mocks.MockPDFGenerator)
+	mockClimateProvider := new(mocks.MockClimateProvider)
+	plantInfo := models.PlantInfo{ID: "kale", Name: "Kale"}
+
+	mockPlantService.On("GetPlantInfo", mock.Anything, "kale").Return(plantInfo, nil)
+	mockPDFGenerator.On("GeneratePDF", mock.Anything).Return([]byte("PDF content"), nil)
+	mockClimateProvider.On("Normals", mock.Anything, mock.Anything).Return(climate.Normals{}, climate.ErrNoData)
+
+	a := newTestApp(t, Config{
+		Catalog:  mockPlantService,
+		Renderer: mockPDFGenerator,
+		Climate:  mockClimateProvider,
+	})
+
+	// New York, no explicit units preference
+	response, err := a.HandleRequest(context.TODO(), events.APIGatewayProxyRequest{
+		Body: `{"location":{"latitude":40.7128,"longitude":-74.0060},"plant_id":"kale"}`,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+	// Missing climate data is not fatal, frost dates come from the nearest frost station instead
+	mockPDFGenerator.AssertCalled(t, "GeneratePDF", mock.MatchedBy(func(r models.Report) bool {
+		return r.Units == units.Imperial && r.Climate == nil &&
+			r.Schedule != nil && r.Schedule.LastFrost.YearDay() == 91 &&
+			r.Schedule.LastFrost.Year() == testNow.Year()
+	}))
+}
+
+func TestHandleRequest_InvalidUnits(t *testing.T) {
+	t.Parallel()
+	mockPlantService := new(mocks.MockPlantService)
+	a := newTestApp(t, Config{Catalog: mockPlantService})
+
+	response, err := a.HandleRequest(context.TODO(), events.APIGatewayProxyRequest{
+		Body: `{"location":{"latitude":51.5,"longitude":-0.12},"plant_id":"kale","units":"cubits"}`,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 400, response.StatusCode)
+	assert.Contains(t, response.Body, "Invalid units")
+	mockPlantService.AssertNotCalled(t, "GetPlantInfo", mock.Anything, mock.Anything)
+}
+
+func TestHandleRequest_RecommendsAlternatives(t *testing.T) {
+	t.Parallel()
+	mockPlantService := new(mocks.MockPlantService)
+	mockPDFGenerator := new(mocks.MockPDFGenerator)
+	mockClimateProvider := new(mocks.MockClimateProvider)
+
+	provider, err := climate.NewEmbeddedProvider()
+	assert.NoError(t, err)
+	london, err := provider.Normals(context.TODO(), 51.5, -0.12)
+	assert.NoError(t, err)
+
+	orange := models.PlantInfo{ID: "orange", Name: "Orange Tree", HardinessZone: "9-11", Category: "Fruit Tree"}
+	apple := models.PlantInfo{ID: "apple", Name: "Apple Tree", HardinessZone: "4-8", Category: "Fruit Tree"}
+	kale := models.PlantInfo{ID: "kale", Name: "Kale", HardinessZone: "7-9", Category: "Vegetable"}
+
+	mockPlantService.On("GetPlantInfo", mock.Anything, "orange").Return(orange, nil)
+	mockPlantService.On("ListPlants", mock.Anything).Return([]models.PlantInfo{orange, apple, kale}, nil)
+	mockClimateProvider.On("Normals", 51.5, -0.12).Return(london, nil)
+	mockPDFGenerator.On("GeneratePDF", mock.Anything).Return([]byte("PDF content"), nil)
+
+	a := newTestApp(t, Config{
+		Catalog:  mockPlantService,
+		Renderer: mockPDFGenerator,
+		Climate:  mockClimateProvider,
+	})
+
+	response, err := a.HandleRequest(context.TODO(), events.APIGatewayProxyRequest{
+		Body: `{"location":{"latitude":51.5,"longitude":-0.12},"plant_id":"orange"}`,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+
+	var body models.Response
+	assert.NoError(t, json.Unmarshal([]byte(response.Body), &body))
+	assert.NotEqual(t, "yes", string(body.Suitability.Verdict))
+	if assert.Len(t, body.Alternatives, 1) {
+		assert.Equal(t, "apple", body.Alternatives[0].PlantID)
+		assert.Equal(t, "Also a fruit tree", body.Alternatives[0].Reason)
+	}
+	mockPDFGenerator.AssertCalled(t, "GeneratePDF", mock.MatchedBy(func(r models.Report) bool {
+		return len(r.Alternatives) == 1 && r.Alternatives[0].PlantID == "apple"
+	}))
+}
+
+func TestHandleRequest_ServesCachedReport(t *testing.T) {
+	t.Parallel()
+	mockPlantService := new(mocks.MockPlantService)
+	mockPDFGenerator := new(mocks.MockPDFGenerator)
+	mockClimateProvider := new(mocks.MockClimateProvider)
+	store := storage.NewMemoryStore()
+
+	kale := models.PlantInfo{ID: "kale", Name: "Kale"}
+	mockPlantService.On("GetPlantInfo", mock.Anything, "kale").Return(kale, nil)
+	mockClimateProvider.On("Normals", mock.Anything, mock.Anything).Return(climate.Normals{}, climate.ErrNoData)
+	mockPDFGenerator.On("GeneratePDF", mock.Anything).Return([]byte("PDF content"), nil)
+
+	a := newTestApp(t, Config{
+		Catalog:  mockPlantService,
+		Renderer: mockPDFGenerator,
+		Climate:  mockClimateProvider,
+		Store:    store,
+		Cache:    reportcache.New(store, 0.1),
+	})
+
+	// Two requests a few hundred metres apart
+	first, err := a.HandleRequest(context.TODO(), events.APIGatewayProxyRequest{
+		Body: `{"location":{"latitude":40.7128,"longitude":-74.0060},"plant_id":"kale"}`,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 200, first.StatusCode)
+
+	second, err := a.HandleRequest(context.TODO(), events.APIGatewayProxyRequest{
+		Body: `{"location":{"latitude":40.7150,"longitude":-74.0100},"plant_id":"kale"}`,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 200, second.StatusCode)
+	assert.Equal(t, first.Body, second.Body)
+
+	// Rendered once, for the centre of the shared grid cell, and stored under a dated cache key
+	mockPDFGenerator.AssertNumberOfCalls(t, "GeneratePDF", 1)
+	mockPDFGenerator.AssertCalled(t, "GeneratePDF", mock.MatchedBy(func(r models.Report) bool {
+		return math.Abs(r.Location.UserLatitude-40.75) < 1e-9 &&
+			math.Abs(r.Location.UserLongitude+74.05) < 1e-9 &&
+			r.LocationRounding == 0.1
+	}))
+	assert.Len(t, store.Keys(), 2)
+	assert.Contains(t, second.Body, "/2026-01-15/")
+}
+
+func TestHandleRequest_PlantServiceUnavailable(t *testing.T) {
+	t.Parallel()
+	mockPlantService := new(mocks.MockPlantService)
+	mockPlantService.On("GetPlantInfo", mock.Anything, "kale").Return(models.PlantInfo{}, plant.ErrServiceUnavailable)
+
+	a := newTestApp(t, Config{Catalog: mockPlantService})
+
+	response, err := a.HandleRequest(context.TODO(), events.APIGatewayProxyRequest{
+		Body: `{"location":{"latitude":51.5,"longitude":-0.12},"plant_id":"kale"}`,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 503, response.StatusCode)
+	assert.Contains(t, response.Body, "temporarily unavailable")
+}
+
+func TestHandler_AppliesMiddleware(t *testing.T) {
+	t.Parallel()
+	reject := func(next apigw.HandlerFunc) apigw.HandlerFunc {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			return apigw.ErrorResponse(401, "Missing API key or bearer token", nil), nil
+		}
+	}
+	mockPlantService := new(mocks.MockPlantService)
+	a := newTestApp(t, Config{Catalog: mockPlantService, Middleware: []apigw.Middleware{reject}})
+
+	response, err := a.Handler()(context.TODO(), events.APIGatewayProxyRequest{
+		Body: `{"location":{"latitude":51.5,"longitude":-0.12},"plant_id":"kale"}`,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 401, response.StatusCode)
+	mockPlantService.AssertNotCalled(t, "GetPlantInfo", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,137 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/HealthyTechGuy/plant-report-app/internal/plant-service/mocks"
+	"github.com/HealthyTechGuy/plant-report-app/models"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/climate"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/companion"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var tomato = models.PlantInfo{ID: "tomato", Name: "Tomato", Companions: []companion.Link{
+	{PlantID: "basil", Relation: companion.Good, Reason: "Repels whitefly"},
+	{PlantID: "potato", Relation: companion.Bad, Reason: "Shares blight"},
+}}
+
+// newCompanionTestApp returns an App with companion planting on that reports on tomato. Listing
+// the catalog fails with listErr when it is set.
+func newCompanionTestApp(t *testing.T, listErr error) (*App, *mocks.MockPDFGenerator) {
+	t.Helper()
+	mockPlantService := new(mocks.MockPlantService)
+	mockPDFGenerator := new(mocks.MockPDFGenerator)
+	mockClimateProvider := new(mocks.MockClimateProvider)
+
+	catalog := []models.PlantInfo{
+		tomato,
+		{ID: "basil", Name: "Basil"},
+		{ID: "potato", Name: "Potato"},
+		{ID: "carrot", Name: "Carrot", Companions: []companion.Link{
+			{PlantID: "tomato", Relation: companion.Good, Reason: "Loosens the soil"},
+			{PlantID: "potato", Relation: companion.Good, Reason: "Shares the bed well"},
+		}},
+	}
+	if listErr != nil {
+		catalog = nil
+	}
+	mockPlantService.On("GetPlantInfo", mock.Anything, "tomato").Return(tomato, nil)
+	mockPlantService.On("ListPlants", mock.Anything).Return(catalog, listErr)
+	mockClimateProvider.On("Normals", mock.Anything, mock.Anything).Return(climate.Normals{}, climate.ErrNoData)
+	mockPDFGenerator.On("GeneratePDF", mock.Anything).Return([]byte("%PDF-1.3 tomato"), nil)
+
+	return newTestApp(t, Config{
+		Catalog:    mockPlantService,
+		Renderer:   mockPDFGenerator,
+		Climate:    mockClimateProvider,
+		Companions: true,
+	}), mockPDFGenerator
+}
+
+func companionBody(neighbours ...string) string {
+	var list string
+	if len(neighbours) > 0 {
+		list = `,"neighbours":["` + strings.Join(neighbours, `","`) + `"]`
+	}
+	return `{"location":{"latitude":51.5,"longitude":-0.12},"plant_id":"tomato"` + list + `}`
+}
+
+func TestHandleRequest_CompanionPlanting(t *testing.T) {
+	t.Parallel()
+	a, renderer := newCompanionTestApp(t, nil)
+
+	response, err := a.HandleRequest(context.TODO(), events.APIGatewayProxyRequest{Body: companionBody("potato")})
+	require.NoError(t, err)
+	require.Equal(t, 200, response.StatusCode)
+
+	renderer.AssertCalled(t, "GeneratePDF", mock.MatchedBy(func(r models.Report) bool {
+		return assert.ObjectsAreEqual(&companion.Plan{
+			Neighbours: []companion.Neighbour{
+				{PlantID: "basil", Name: "Basil", Relation: companion.Good, Reason: "Repels whitefly"},
+				{PlantID: "carrot", Name: "Carrot", Relation: companion.Good, Reason: "Loosens the soil"},
+				{PlantID: "potato", Name: "Potato", Relation: companion.Bad, Reason: "Shares blight"},
+			},
+			Conflicts: []companion.Conflict{
+				{PlantID: "tomato", Name: "Tomato", OtherID: "potato", OtherName: "Potato", Reason: "Shares blight"},
+			},
+			Suggestions: []companion.Candidate{
+				{PlantID: "carrot", Name: "Carrot", Partners: []string{"tomato", "potato"}, Reason: "Loosens the soil"},
+				{PlantID: "basil", Name: "Basil", Partners: []string{"tomato"}, Reason: "Repels whitefly"},
+			},
+		}, r.Companions)
+	}))
+}
+
+func TestHandleRequest_CompanionPlantingUnavailable(t *testing.T) {
+	t.Parallel()
+	a, renderer := newCompanionTestApp(t, errors.New("scan failed"))
+
+	// Without neighbours the report is still built, without the section
+	response, err := a.HandleRequest(context.TODO(), events.APIGatewayProxyRequest{Body: companionBody()})
+	require.NoError(t, err)
+	require.Equal(t, 200, response.StatusCode)
+	renderer.AssertCalled(t, "GeneratePDF", mock.MatchedBy(func(r models.Report) bool { return r.Companions == nil }))
+
+	// Neighbours that cannot be checked fail the request rather than going into the report unchecked
+	response, err = a.HandleRequest(context.TODO(), events.APIGatewayProxyRequest{Body: companionBody("potato")})
+	require.NoError(t, err)
+	assert.Equal(t, 503, response.StatusCode)
+	assert.Contains(t, response.Body, "Companion planting is temporarily unavailable")
+	renderer.AssertNumberOfCalls(t, "GeneratePDF", 1)
+}
+
+func TestHandleRequest_RejectsNeighbours(t *testing.T) {
+	t.Parallel()
+	enabled, _ := newCompanionTestApp(t, nil)
+	disabled := newTestApp(t, Config{})
+	tooMany := make([]string, maxNeighbours+1)
+	for i := range tooMany {
+		tooMany[i] = "basil"
+	}
+
+	tests := []struct {
+		name    string
+		app     *App
+		body    string
+		message string
+	}{
+		{"companions disabled", disabled, companionBody("potato"), "Companion planting is not enabled"},
+		{"unknown neighbour", enabled, companionBody("basil", "leek"), "Unknown neighbour plant: leek"},
+		{"too many neighbours", enabled, companionBody(tooMany...), "Too many neighbours: at most 10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := tt.app.HandleRequest(context.TODO(), events.APIGatewayProxyRequest{Body: tt.body})
+			require.NoError(t, err)
+			assert.Equal(t, 400, response.StatusCode)
+			assert.Contains(t, response.Body, tt.message)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package app
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/HealthyTechGuy/plant-report-app/models"
+	"github.com/aws/aws-lambda-go/events"
+	"go.uber.org/zap"
+)
+
+// DefaultInlineLimit is the largest PDF returned directly in download mode. Lambda responses are
+// capped at 6 MB and base64 adds a third, so this leaves room for the headers and JSON envelope.
+const DefaultInlineLimit = 4 << 20
+
+// downloadResponse returns the PDF itself for download mode. Reports over the inline limit are
+// answered like a normal request, with a time-limited link in pdf_url when the store supports one.
+func (a *App) downloadResponse(summary models.Response, plantID, storageKey string, report []byte) events.APIGatewayProxyResponse {
+	// A nil report was streamed to storage without being kept, as it was over the limit
+	if report == nil || len(report) > a.inlineLimit {
+		a.logger.Info("report too large to return directly, sending a link",
+			zap.Int("bytes", len(report)), zap.Int("limit", a.inlineLimit))
+		if link, err := a.downloadURL(storageKey); err != nil {
+			a.logger.Warn("error creating download link", zap.Error(err))
+		} else {
+			summary.PDFUrl = link
+		}
+		return responseWithSuccess(200, summary)
+	}
+
+	headers := map[string]string{
+		"Content-Type":        "application/pdf",
+		"Content-Disposition": fmt.Sprintf(`attachment; filename="%s"`, reportFilename(plantID)),
+	}
+	if summary.ReportID != "" {
+		headers["X-Report-Id"] = summary.ReportID
+	}
+	if summary.EmailStatus != "" {
+		headers["X-Email-Status"] = summary.EmailStatus
+	}
+	return events.APIGatewayProxyResponse{
+		StatusCode:      200,
+		Headers:         headers,
+		Body:            base64.StdEncoding.EncodeToString(report),
+		IsBase64Encoded: true,
+	}
+}
+
+// reportFilename names a downloaded report after its plant, keeping only characters that are
+// safe in a Content-Disposition header
+func reportFilename(plantID string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '-'
+		}
+	}, plantID)
+	if name == "" {
+		name = "plant"
+	}
+	return name + "-report.pdf"
+}
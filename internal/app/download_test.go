@@ -0,0 +1,89 @@
+package app
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/HealthyTechGuy/plant-report-app/internal/plant-service/mocks"
+	"github.com/HealthyTechGuy/plant-report-app/internal/reportcache"
+	"github.com/HealthyTechGuy/plant-report-app/models"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/climate"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/storage"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newDownloadTestApp returns an App rendering fixed PDF content for kale
+func newDownloadTestApp(t *testing.T, cfg Config) *App {
+	t.Helper()
+	mockPlantService := new(mocks.MockPlantService)
+	mockPDFGenerator := new(mocks.MockPDFGenerator)
+	mockClimateProvider := new(mocks.MockClimateProvider)
+
+	mockPlantService.On("GetPlantInfo", mock.Anything, "kale").Return(models.PlantInfo{ID: "kale", Name: "Kale"}, nil)
+	mockClimateProvider.On("Normals", mock.Anything, mock.Anything).Return(climate.Normals{}, climate.ErrNoData)
+	mockPDFGenerator.On("GeneratePDF", mock.Anything).Return([]byte("%PDF-1.3 kale"), nil)
+
+	cfg.Catalog = mockPlantService
+	cfg.Renderer = mockPDFGenerator
+	cfg.Climate = mockClimateProvider
+	return newTestApp(t, cfg)
+}
+
+const downloadBody = `{"location":{"latitude":51.5,"longitude":-0.12},"plant_id":"kale","download":true}`
+
+func TestHandleRequest_Download(t *testing.T) {
+	t.Parallel()
+	a := newDownloadTestApp(t, Config{})
+
+	response, err := a.HandleRequest(context.TODO(), events.APIGatewayProxyRequest{Body: downloadBody})
+	require.NoError(t, err)
+
+	assert.Equal(t, 200, response.StatusCode)
+	assert.True(t, response.IsBase64Encoded)
+	assert.Equal(t, "application/pdf", response.Headers["Content-Type"])
+	assert.Equal(t, `attachment; filename="kale-report.pdf"`, response.Headers["Content-Disposition"])
+	pdf, err := base64.StdEncoding.DecodeString(response.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "%PDF-1.3 kale", string(pdf))
+}
+
+func TestHandleRequest_DownloadFromCache(t *testing.T) {
+	t.Parallel()
+	store := storage.NewMemoryStore()
+	a := newDownloadTestApp(t, Config{Store: store, Cache: reportcache.New(store, 0.1)})
+
+	for i := 0; i < 2; i++ {
+		response, err := a.HandleRequest(context.TODO(), events.APIGatewayProxyRequest{Body: downloadBody})
+		require.NoError(t, err)
+		assert.True(t, response.IsBase64Encoded)
+		assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("%PDF-1.3 kale")), response.Body)
+	}
+	a.renderer.(*mocks.MockPDFGenerator).AssertNumberOfCalls(t, "GeneratePDF", 1)
+}
+
+func TestHandleRequest_DownloadTooLargeFallsBackToLink(t *testing.T) {
+	t.Parallel()
+	a := newDownloadTestApp(t, Config{InlineLimit: 8})
+
+	response, err := a.HandleRequest(context.TODO(), events.APIGatewayProxyRequest{Body: downloadBody})
+	require.NoError(t, err)
+
+	assert.Equal(t, 200, response.StatusCode)
+	assert.False(t, response.IsBase64Encoded)
+	assert.Equal(t, "application/json", response.Headers["Content-Type"])
+	var body models.Response
+	require.NoError(t, json.Unmarshal([]byte(response.Body), &body))
+	assert.Equal(t, "memory://file.pdf?expires_in=1h0m0s", body.PDFUrl)
+}
+
+func TestReportFilename(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "blueberry-report.pdf", reportFilename("blueberry"))
+	assert.Equal(t, "a-b--c-report.pdf", reportFilename(`a"b\;c`))
+	assert.Equal(t, "plant-report.pdf", reportFilename(""))
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/HealthyTechGuy/plant-report-app/models"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/mailer"
+	"go.uber.org/zap"
+)
+
+// DefaultAttachmentLimit is the largest PDF attached to an email. SES accepts 10 MB messages and
+// base64 adds a third, so this leaves room for the rest of the message.
+const DefaultAttachmentLimit = 7 << 20
+
+// Email delivery statuses returned in email_status and kept in the report history
+const (
+	emailSent   = "sent"
+	emailFailed = "failed"
+)
+
+// emailReport emails a report to to, attaching the PDF when it is small enough and linking to it
+// otherwise, and returns the delivery status. A nil report is always linked. Failures are logged
+// and reported in the status, the report itself was still generated.
+func (a *App) emailReport(ctx context.Context, to string, plantInfo models.PlantInfo, storageKey, reportURL string, report []byte, now time.Time) string {
+	if to == "" {
+		return ""
+	}
+
+	msg := mailer.Message{
+		To:      to,
+		Subject: fmt.Sprintf("Your %s growing report", plantInfo.Name),
+	}
+	if report != nil && len(report) <= a.attachmentLimit {
+		msg.Text = fmt.Sprintf("Hello,\n\nYour growing report for %s is attached.\n\nHappy growing!\n", plantInfo.Name)
+		msg.Attachments = []mailer.Attachment{{
+			Filename:    reportFilename(plantInfo.ID),
+			ContentType: "application/pdf",
+			Data:        report,
+		}}
+	} else {
+		link, err := a.downloadURL(storageKey)
+		if err != nil {
+			a.logger.Warn("error creating download link", zap.Error(err))
+			link = reportURL
+		}
+		msg.Text = fmt.Sprintf("Hello,\n\nYour growing report for %s is ready to download:\n\n%s\n\nThe link expires at %s.\n\nHappy growing!\n",
+			plantInfo.Name, link, now.Add(a.linkTTL).UTC().Format("15:04 MST on 2 January 2006"))
+	}
+
+	if err := a.mailer.Send(ctx, msg); err != nil {
+		a.logger.Error("error emailing report", zap.String("plant_id", plantInfo.ID), zap.Error(err))
+		return emailFailed
+	}
+	a.logger.Info("emailed report", zap.String("plant_id", plantInfo.ID), zap.Bool("attached", len(msg.Attachments) > 0))
+	return emailSent
+}
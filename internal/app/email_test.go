@@ -0,0 +1,117 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/HealthyTechGuy/plant-report-app/internal/auth"
+	"github.com/HealthyTechGuy/plant-report-app/internal/history"
+	"github.com/HealthyTechGuy/plant-report-app/models"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/mailer"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeMailer records sent messages and fails with err when set
+type fakeMailer struct {
+	mu   sync.Mutex
+	sent []mailer.Message
+	err  error
+}
+
+func (m *fakeMailer) Send(ctx context.Context, msg mailer.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+const emailBody = `{"location":{"latitude":51.5,"longitude":-0.12},"plant_id":"kale","email":"gardener@example.com"}`
+
+func TestHandleRequest_EmailsAttachment(t *testing.T) {
+	t.Parallel()
+	m := &fakeMailer{}
+	reports := history.NewMemoryStore()
+	a := newDownloadTestApp(t, Config{Mailer: m, History: reports})
+
+	ctx := auth.WithUser(context.TODO(), auth.User{ID: "user-123"})
+	response, err := a.HandleRequest(ctx, events.APIGatewayProxyRequest{Body: emailBody})
+	require.NoError(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Contains(t, response.Body, `"email_status":"sent"`)
+
+	require.Len(t, m.sent, 1)
+	assert.Equal(t, "gardener@example.com", m.sent[0].To)
+	assert.Equal(t, "Your Kale growing report", m.sent[0].Subject)
+	require.Len(t, m.sent[0].Attachments, 1)
+	assert.Equal(t, "kale-report.pdf", m.sent[0].Attachments[0].Filename)
+	assert.Equal(t, "%PDF-1.3 kale", string(m.sent[0].Attachments[0].Data))
+
+	// The delivery status is kept with the report history
+	records, err := reports.List(context.TODO(), "user-123", 0)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "sent", records[0].EmailStatus)
+}
+
+func TestHandleRequest_EmailsLinkForLargeReports(t *testing.T) {
+	t.Parallel()
+	m := &fakeMailer{}
+	a := newDownloadTestApp(t, Config{Mailer: m, AttachmentLimit: 4})
+
+	response, err := a.HandleRequest(context.TODO(), events.APIGatewayProxyRequest{Body: emailBody})
+	require.NoError(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+
+	require.Len(t, m.sent, 1)
+	assert.Empty(t, m.sent[0].Attachments)
+	assert.Contains(t, m.sent[0].Text, "memory://file.pdf?expires_in=1h0m0s")
+	assert.Contains(t, m.sent[0].Text, "13:00 UTC on 15 January 2026")
+}
+
+func TestHandleRequest_EmailFailureStillReturnsReport(t *testing.T) {
+	t.Parallel()
+	a := newDownloadTestApp(t, Config{Mailer: &fakeMailer{err: errors.New("connection refused")}})
+
+	response, err := a.HandleRequest(context.TODO(), events.APIGatewayProxyRequest{Body: emailBody})
+	require.NoError(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+
+	var body models.Response
+	require.NoError(t, json.Unmarshal([]byte(response.Body), &body))
+	assert.Equal(t, "failed", body.EmailStatus)
+	assert.Equal(t, "memory://file.pdf", body.PDFUrl)
+}
+
+func TestHandleRequest_RejectsEmail(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		mailer  mailer.Mailer
+		email   string
+		message string
+	}{
+		{"invalid address", &fakeMailer{}, "gardener@", "Invalid email address"},
+		{"header injection", &fakeMailer{}, "a@example.com\\r\\nBcc: b@example.com", "Invalid email address"},
+		{"email disabled", nil, "gardener@example.com", "Email delivery is not enabled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t, Config{Mailer: tt.mailer})
+			response, err := a.HandleRequest(context.TODO(), events.APIGatewayProxyRequest{
+				Body: `{"location":{"latitude":51.5,"longitude":-0.12},"plant_id":"kale","email":"` + tt.email + `"}`,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, 400, response.StatusCode)
+			assert.Contains(t, response.Body, tt.message)
+		})
+	}
+}
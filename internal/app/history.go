@@ -0,0 +1,116 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/HealthyTechGuy/plant-report-app/internal/auth"
+	"github.com/HealthyTechGuy/plant-report-app/internal/history"
+	"github.com/HealthyTechGuy/plant-report-app/models"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/apigw"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/storage"
+	"github.com/aws/aws-lambda-go/events"
+	"go.uber.org/zap"
+)
+
+// maxHistoryLimit caps the limit query parameter of GET /me/reports
+const maxHistoryLimit = 100
+
+// ListReports handles GET /me/reports, listing the signed-in user's reports with fresh download
+// links. Reports past their retention are left out even before the table's TTL removes them.
+func (a *App) ListReports(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	user, ok := auth.UserFrom(ctx)
+	if !ok {
+		return responseWithError(401, "Report history requires a signed-in user"), nil
+	}
+	if a.history == nil {
+		return responseWithError(404, "Report history is not enabled"), nil
+	}
+
+	limit := history.DefaultLimit
+	if v := request.QueryStringParameters["limit"]; v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxHistoryLimit {
+			return responseWithError(400, "Invalid limit: must be between 1 and 100"), nil
+		}
+		limit = n
+	}
+
+	records, err := a.history.List(ctx, user.ID, limit)
+	if err != nil {
+		a.logger.Error("error listing report history", zap.String("user_id", user.ID), zap.Error(err))
+		return responseWithError(500, "Failed to list reports"), nil
+	}
+
+	response := models.ReportHistoryResponse{Reports: make([]models.ReportSummary, 0, len(records))}
+	now := a.clock()
+	for _, record := range records {
+		if record.Expired(now) {
+			continue
+		}
+		link, err := a.downloadURL(record.StorageKey)
+		if err != nil {
+			a.logger.Warn("error creating download link", zap.String("report_id", record.ReportID), zap.Error(err))
+			continue
+		}
+		response.Reports = append(response.Reports, models.ReportSummary{
+			ReportID:    record.ReportID,
+			PlantID:     record.PlantID,
+			Latitude:    record.Latitude,
+			Longitude:   record.Longitude,
+			CreatedAt:   record.CreatedAt,
+			DownloadURL: link,
+			EmailStatus: record.EmailStatus,
+		})
+	}
+
+	return apigw.JSON(200, response, nil), nil
+}
+
+// historyUser returns the signed-in user when their reports should be recorded
+func (a *App) historyUser(ctx context.Context) (auth.User, bool) {
+	if a.history == nil {
+		return auth.User{}, false
+	}
+	return auth.UserFrom(ctx)
+}
+
+// recordHistory adds a report to the user's history, expiring it as the retention policy sets for
+// the client's tier. Failures are logged, the report itself was still delivered.
+func (a *App) recordHistory(ctx context.Context, user auth.User, summary models.Response, plantID string, location models.UserLocation, storageKey string, now time.Time) {
+	client, _ := auth.ClientFrom(ctx)
+	err := a.history.Add(ctx, history.Record{
+		UserID:      user.ID,
+		ReportID:    summary.ReportID,
+		PlantID:     plantID,
+		Latitude:    location.UserLatitude,
+		Longitude:   location.UserLongitude,
+		StorageKey:  storageKey,
+		EmailStatus: summary.EmailStatus,
+		CreatedAt:   now,
+		ExpiresAt:   a.retention.ExpiresAt(client.Tier, now),
+	})
+	if err != nil {
+		a.logger.Error("error recording report history", zap.String("user_id", user.ID), zap.Error(err))
+	}
+}
+
+// downloadURL returns a time-limited link when the store supports them and its plain URL otherwise
+func (a *App) downloadURL(key string) (string, error) {
+	if presigner, ok := a.store.(storage.Presigner); ok {
+		return presigner.PresignURL(key, a.linkTTL)
+	}
+	return a.store.URL(key), nil
+}
+
+// newReportID returns a random 128-bit hex report ID
+func newReportID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic("crypto/rand failed: " + err.Error())
+	}
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,189 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/HealthyTechGuy/plant-report-app/internal/auth"
+	"github.com/HealthyTechGuy/plant-report-app/internal/history"
+	"github.com/HealthyTechGuy/plant-report-app/internal/plant-service/mocks"
+	"github.com/HealthyTechGuy/plant-report-app/internal/retention"
+	"github.com/HealthyTechGuy/plant-report-app/models"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/climate"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/storage"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleRequest_RecordsUserHistory(t *testing.T) {
+	t.Parallel()
+	mockPlantService := new(mocks.MockPlantService)
+	mockPDFGenerator := new(mocks.MockPDFGenerator)
+	mockClimateProvider := new(mocks.MockClimateProvider)
+	store := storage.NewMemoryStore()
+	reports := history.NewMemoryStore()
+	policy, err := retention.ParsePolicy([]string{"user=30", "*=7"})
+	require.NoError(t, err)
+
+	mockPlantService.On("GetPlantInfo", mock.Anything, "kale").Return(models.PlantInfo{ID: "kale", Name: "Kale"}, nil)
+	mockClimateProvider.On("Normals", mock.Anything, mock.Anything).Return(climate.Normals{}, climate.ErrNoData)
+	mockPDFGenerator.On("GeneratePDF", mock.Anything).Return([]byte("PDF content"), nil)
+
+	a := newTestApp(t, Config{
+		Catalog:   mockPlantService,
+		Renderer:  mockPDFGenerator,
+		Climate:   mockClimateProvider,
+		Store:     store,
+		History:   reports,
+		Retention: policy,
+	})
+
+	// The auth middleware signs users in as a client of the user tier
+	ctx := auth.WithUser(context.TODO(), auth.User{ID: "user-123"})
+	ctx = auth.WithClient(ctx, auth.Client{ID: "user:user-123", Tier: "user"})
+	response, err := a.HandleRequest(ctx, events.APIGatewayProxyRequest{
+		Body: `{"location":{"latitude":51.5,"longitude":-0.12},"plant_id":"kale"}`,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 200, response.StatusCode)
+
+	var body models.Response
+	require.NoError(t, json.Unmarshal([]byte(response.Body), &body))
+	assert.Len(t, body.ReportID, 32)
+
+	// Stored under a per-user key so later reports don't overwrite it
+	records, err := reports.List(context.TODO(), "user-123", 0)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, body.ReportID, records[0].ReportID)
+	assert.Equal(t, "kale", records[0].PlantID)
+	assert.Equal(t, testNow, records[0].CreatedAt)
+	assert.Equal(t, testNow.AddDate(0, 0, 30), records[0].ExpiresAt)
+	assert.Equal(t, "users/user-123/"+body.ReportID+".pdf", records[0].StorageKey)
+	assert.Equal(t, []string{records[0].StorageKey}, store.Keys())
+}
+
+func TestHandleRequest_NoHistoryWithoutUser(t *testing.T) {
+	t.Parallel()
+	mockPlantService := new(mocks.MockPlantService)
+	mockPDFGenerator := new(mocks.MockPDFGenerator)
+	mockClimateProvider := new(mocks.MockClimateProvider)
+	reports := history.NewMemoryStore()
+
+	mockPlantService.On("GetPlantInfo", mock.Anything, "kale").Return(models.PlantInfo{ID: "kale", Name: "Kale"}, nil)
+	mockClimateProvider.On("Normals", mock.Anything, mock.Anything).Return(climate.Normals{}, climate.ErrNoData)
+	mockPDFGenerator.On("GeneratePDF", mock.Anything).Return([]byte("PDF content"), nil)
+
+	a := newTestApp(t, Config{
+		Catalog:  mockPlantService,
+		Renderer: mockPDFGenerator,
+		Climate:  mockClimateProvider,
+		History:  reports,
+	})
+
+	response, err := a.HandleRequest(context.TODO(), events.APIGatewayProxyRequest{
+		Body: `{"location":{"latitude":51.5,"longitude":-0.12},"plant_id":"kale"}`,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.NotContains(t, response.Body, "report_id")
+}
+
+func TestListReports(t *testing.T) {
+	t.Parallel()
+	reports := history.NewMemoryStore()
+	for i, id := range []string{"older", "newer"} {
+		require.NoError(t, reports.Add(context.TODO(), history.Record{
+			UserID:     "user-123",
+			ReportID:   id,
+			PlantID:    "kale",
+			StorageKey: "users/user-123/" + id + ".pdf",
+			CreatedAt:  testNow.AddDate(0, 0, i),
+		}))
+	}
+	require.NoError(t, reports.Add(context.TODO(), history.Record{UserID: "someone-else", ReportID: "theirs"}))
+	require.NoError(t, reports.Add(context.TODO(), history.Record{
+		UserID:     "user-123",
+		ReportID:   "expired",
+		PlantID:    "kale",
+		StorageKey: "users/user-123/expired.pdf",
+		CreatedAt:  testNow.AddDate(0, 0, -31),
+		ExpiresAt:  testNow.AddDate(0, 0, -1),
+	}))
+
+	a := newTestApp(t, Config{History: reports})
+	ctx := auth.WithUser(context.TODO(), auth.User{ID: "user-123"})
+
+	response, err := a.Handler()(ctx, events.APIGatewayProxyRequest{HTTPMethod: "GET", Path: "/me/reports"})
+	require.NoError(t, err)
+	require.Equal(t, 200, response.StatusCode)
+
+	var body models.ReportHistoryResponse
+	require.NoError(t, json.Unmarshal([]byte(response.Body), &body))
+	require.Len(t, body.Reports, 2)
+	assert.Equal(t, "newer", body.Reports[0].ReportID)
+	assert.Equal(t, "memory://users/user-123/newer.pdf?expires_in=1h0m0s", body.Reports[0].DownloadURL)
+	assert.Equal(t, "older", body.Reports[1].ReportID)
+
+	response, err = a.Handler()(ctx, events.APIGatewayProxyRequest{
+		HTTPMethod:            "GET",
+		Path:                  "/me/reports",
+		QueryStringParameters: map[string]string{"limit": "1"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal([]byte(response.Body), &body))
+	assert.Len(t, body.Reports, 1)
+}
+
+func TestListReports_Errors(t *testing.T) {
+	t.Parallel()
+	signedIn := auth.WithUser(context.TODO(), auth.User{ID: "user-123"})
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		history history.Store
+		request events.APIGatewayProxyRequest
+		status  int
+	}{
+		{"anonymous", context.TODO(), history.NewMemoryStore(), events.APIGatewayProxyRequest{}, 401},
+		{"history disabled", signedIn, nil, events.APIGatewayProxyRequest{}, 404},
+		{"limit too large", signedIn, history.NewMemoryStore(), events.APIGatewayProxyRequest{
+			QueryStringParameters: map[string]string{"limit": "500"},
+		}, 400},
+		{"limit not a number", signedIn, history.NewMemoryStore(), events.APIGatewayProxyRequest{
+			QueryStringParameters: map[string]string{"limit": "ten"},
+		}, 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t, Config{History: tt.history})
+			response, err := a.ListReports(tt.ctx, tt.request)
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, response.StatusCode)
+		})
+	}
+}
+
+func TestHandler_Routes(t *testing.T) {
+	t.Parallel()
+	a := newTestApp(t, Config{})
+
+	tests := []struct {
+		method, path string
+		status       int
+	}{
+		{"DELETE", "/report", 405},
+		{"POST", "/me/reports", 405},
+		{"GET", "/nowhere", 404},
+	}
+	for _, tt := range tests {
+		response, err := a.Handler()(context.TODO(), events.APIGatewayProxyRequest{HTTPMethod: tt.method, Path: tt.path})
+		require.NoError(t, err)
+		assert.Equal(t, tt.status, response.StatusCode, "%s %s", tt.method, tt.path)
+	}
+}
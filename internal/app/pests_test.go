@@ -0,0 +1,73 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/HealthyTechGuy/plant-report-app/internal/plant-service/mocks"
+	"github.com/HealthyTechGuy/plant-report-app/models"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/climate"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/pests"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/units"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newPestCatalog(t *testing.T) *pests.Catalog {
+	t.Helper()
+	catalog, err := pests.NewCatalog([]pests.Entry{
+		{ID: "aphids", Name: "Aphids", Kind: pests.Pest, PlantIDs: []string{"kale"},
+			Conditions: pests.Conditions{MinTemperature: units.Celsius(10), MaxTemperature: units.Celsius(26)}},
+		{ID: "frost-mould", Name: "Frost mould", Kind: pests.Disease, PlantIDs: []string{"kale"},
+			Conditions: pests.Conditions{MinTemperature: units.Celsius(-20), MaxTemperature: units.Celsius(0)}},
+	})
+	require.NoError(t, err)
+	return catalog
+}
+
+func TestHandleRequest_RanksPestRisks(t *testing.T) {
+	t.Parallel()
+	provider, err := climate.NewEmbeddedProvider()
+	require.NoError(t, err)
+
+	mockPlantService := new(mocks.MockPlantService)
+	mockPDFGenerator := new(mocks.MockPDFGenerator)
+	mockPlantService.On("GetPlantInfo", mock.Anything, "kale").Return(models.PlantInfo{ID: "kale", Name: "Kale"}, nil)
+	// Kale has no requirements to score, so the catalog is checked for alternatives
+	mockPlantService.On("ListPlants", mock.Anything).Return([]models.PlantInfo{}, nil)
+	mockPDFGenerator.On("GeneratePDF", mock.Anything).Return([]byte("%PDF-1.3 kale"), nil)
+
+	a := newTestApp(t, Config{
+		Catalog:  mockPlantService,
+		Renderer: mockPDFGenerator,
+		Climate:  provider,
+		Pests:    newPestCatalog(t),
+	})
+	response, err := a.HandleRequest(context.TODO(), events.APIGatewayProxyRequest{
+		Body: `{"location":{"latitude":51.5,"longitude":-0.12},"plant_id":"kale"}`,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 200, response.StatusCode)
+
+	mockPDFGenerator.AssertCalled(t, "GeneratePDF", mock.MatchedBy(func(r models.Report) bool {
+		return len(r.PestRisks) == 2 &&
+			r.PestRisks[0].ID == "aphids" && r.PestRisks[0].Level == pests.High &&
+			r.PestRisks[1].ID == "frost-mould" && r.PestRisks[1].Level == pests.Low
+	}))
+}
+
+func TestHandleRequest_PestRisksWithoutClimate(t *testing.T) {
+	t.Parallel()
+	a := newDownloadTestApp(t, Config{Pests: newPestCatalog(t)})
+	response, err := a.HandleRequest(context.TODO(), events.APIGatewayProxyRequest{
+		Body: `{"location":{"latitude":51.5,"longitude":-0.12},"plant_id":"kale"}`,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 200, response.StatusCode)
+
+	renderer := a.renderer.(*mocks.MockPDFGenerator)
+	renderer.AssertCalled(t, "GeneratePDF", mock.MatchedBy(func(r models.Report) bool {
+		return len(r.PestRisks) == 2 && r.PestRisks[0].Level == pests.Unknown && r.PestRisks[1].Level == pests.Unknown
+	}))
+}
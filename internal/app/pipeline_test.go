@@ -0,0 +1,110 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/HealthyTechGuy/plant-report-app/internal/plant-service/mocks"
+	"github.com/HealthyTechGuy/plant-report-app/internal/reportcache"
+	"github.com/HealthyTechGuy/plant-report-app/models"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/climate"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/pdf"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/storage"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// slowClimate answers after delay, like a remote climate API, and counts cancelled lookups
+type slowClimate struct {
+	delay     time.Duration
+	cancelled atomic.Int32
+}
+
+func (c *slowClimate) Normals(ctx context.Context, latitude, longitude float64) (climate.Normals, error) {
+	select {
+	case <-time.After(c.delay):
+		return climate.Normals{}, climate.ErrNoData
+	case <-ctx.Done():
+		c.cancelled.Add(1)
+		return climate.Normals{}, ctx.Err()
+	}
+}
+
+// slowStore adds latency to reads, like S3, and never finds anything so every report is a cache miss
+type slowStore struct {
+	storage.Store
+	delay time.Duration
+}
+
+func (s slowStore) Get(ctx context.Context, key string) ([]byte, error) {
+	time.Sleep(s.delay)
+	return nil, storage.ErrNotFound
+}
+
+func newPipelineTestApp(tb testing.TB, cfg Config) *App {
+	mockPlantService := new(mocks.MockPlantService)
+	mockPDFGenerator := new(mocks.MockPDFGenerator)
+	mockPlantService.On("GetPlantInfo", mock.Anything, "kale").Return(models.PlantInfo{ID: "kale", Name: "Kale"}, nil)
+	mockPDFGenerator.On("GeneratePDF", mock.Anything).Return([]byte("%PDF-1.3 kale"), nil)
+	cfg.Catalog = mockPlantService
+	cfg.Renderer = mockPDFGenerator
+	return newTestApp(tb, cfg)
+}
+
+func TestHandleRequest_CacheHitCancelsClimateLookup(t *testing.T) {
+	t.Parallel()
+	provider := &slowClimate{delay: 10 * time.Second}
+	store := storage.NewMemoryStore()
+	cache := reportcache.New(store, 0.1)
+	a := newPipelineTestApp(t, Config{Climate: provider, Store: store, Cache: cache})
+
+	// Seed the cache with the report the request will hit
+	key := reportcache.Key{
+		PlantID:         "kale",
+		Fingerprint:     reportcache.Fingerprint(models.PlantInfo{ID: "kale", Name: "Kale"}),
+		Cell:            cache.Cell(51.5, -0.12),
+		Locale:          "metric",
+		Format:          "pdf",
+		Date:            "2026-01-15",
+		TemplateVersion: pdf.TemplateVersion,
+	}
+	_, err := cache.Put(context.TODO(), key, strings.NewReader("%PDF-1.3 kale"), "application/pdf", reportcache.Entry{})
+	require.NoError(t, err)
+
+	response, err := a.HandleRequest(context.TODO(), events.APIGatewayProxyRequest{
+		Body: `{"location":{"latitude":51.5,"longitude":-0.12},"plant_id":"kale"}`,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, int32(1), provider.cancelled.Load())
+}
+
+// BenchmarkHandleRequest_Parallelism generates uncached reports with a 5ms climate API and a 5ms
+// cache lookup, run one after another and through the concurrent pipeline
+func BenchmarkHandleRequest_Parallelism(b *testing.B) {
+	for _, parallelism := range []int{1, DefaultParallelism} {
+		b.Run(fmt.Sprintf("parallelism=%d", parallelism), func(b *testing.B) {
+			store := slowStore{Store: storage.NewMemoryStore(), delay: 5 * time.Millisecond}
+			a := newPipelineTestApp(b, Config{
+				Climate:     &slowClimate{delay: 5 * time.Millisecond},
+				Store:       store,
+				Cache:       reportcache.New(store, 0.1),
+				Parallelism: parallelism,
+			})
+			request := events.APIGatewayProxyRequest{Body: `{"location":{"latitude":51.5,"longitude":-0.12},"plant_id":"kale"}`}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if response, err := a.HandleRequest(context.Background(), request); err != nil || response.StatusCode != 200 {
+					b.Fatalf("request failed: %v %d", err, response.StatusCode)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/HealthyTechGuy/plant-report-app/models"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/pdf"
+)
+
+// errRender marks failures to render a report, as opposed to failures to store it
+var errRender = errors.New("failed to render report")
+
+// storedReport is a rendered report once it has been stored
+type storedReport struct {
+	URL string
+	// PDF is the report itself, when it was kept
+	PDF []byte
+	// Size is the report's length in bytes and Checksum its hex SHA-256
+	Size     int64
+	Checksum string
+}
+
+// renderAndStore renders report and hands it to upload, returning where it was stored and what
+// was stored. When the renderer can stream, the PDF is uploaded while it is rendered and is only
+// kept in memory when keep allows: a report over keep bytes comes back without its PDF. Other
+// renderers build the whole PDF in memory first and it is always returned.
+func (a *App) renderAndStore(ctx context.Context, report models.Report, keep int, upload func(ctx context.Context, body io.Reader) (string, error)) (storedReport, error) {
+	digest := &digestWriter{hash: sha256.New()}
+	streamer, ok := a.renderer.(pdf.StreamRenderer)
+	if !ok {
+		data, err := a.renderer.GeneratePDF(report)
+		if err != nil {
+			return storedReport{}, fmt.Errorf("%w: %w", errRender, err)
+		}
+		digest.Write(data)
+		url, err := upload(ctx, bytes.NewReader(data))
+		if err != nil {
+			return storedReport{}, err
+		}
+		return digest.stored(url, data), nil
+	}
+
+	pr, pw := io.Pipe()
+	rendered := make(chan error, 1)
+	go func() {
+		err := streamer.RenderPDF(pw, report)
+		pw.CloseWithError(err)
+		rendered <- err
+	}()
+
+	kept := &cappedBuffer{limit: keep}
+	url, err := upload(ctx, io.TeeReader(pr, io.MultiWriter(digest, kept)))
+	// Unblocks the renderer when the upload gave up before reading everything
+	pr.Close()
+
+	if renderErr := <-rendered; renderErr != nil && !errors.Is(renderErr, io.ErrClosedPipe) {
+		return storedReport{}, fmt.Errorf("%w: %w", errRender, renderErr)
+	}
+	if err != nil {
+		return storedReport{}, err
+	}
+	return digest.stored(url, kept.Bytes()), nil
+}
+
+// digestWriter measures and hashes what is written to it
+type digestWriter struct {
+	hash hash.Hash
+	size int64
+}
+
+func (d *digestWriter) Write(p []byte) (int, error) {
+	d.size += int64(len(p))
+	return d.hash.Write(p)
+}
+
+// stored describes the report written through d
+func (d *digestWriter) stored(url string, pdf []byte) storedReport {
+	return storedReport{URL: url, PDF: pdf, Size: d.size, Checksum: hex.EncodeToString(d.hash.Sum(nil))}
+}
+
+// cappedBuffer keeps what is written to it up to limit bytes, and nothing once more is written
+type cappedBuffer struct {
+	limit    int
+	buf      bytes.Buffer
+	overflow bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if !b.overflow {
+		if b.buf.Len()+len(p) > b.limit {
+			b.overflow = true
+			b.buf = bytes.Buffer{}
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+// Bytes returns what was written, or nil when it went over the limit or nothing was written
+func (b *cappedBuffer) Bytes() []byte {
+	if b.overflow || b.buf.Len() == 0 {
+		return nil
+	}
+	return b.buf.Bytes()
+}
@@ -0,0 +1,126 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/HealthyTechGuy/plant-report-app/internal/plant-service/mocks"
+	"github.com/HealthyTechGuy/plant-report-app/models"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/climate"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/storage"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// streamRenderer writes pdf in small chunks, failing with err after it when set
+type streamRenderer struct {
+	pdf []byte
+	err error
+}
+
+func (r streamRenderer) GeneratePDF(report models.Report) ([]byte, error) {
+	panic("streaming renderers are not asked for the whole PDF")
+}
+
+func (r streamRenderer) RenderPDF(w io.Writer, report models.Report) error {
+	for _, chunk := range bytes.SplitAfter(r.pdf, []byte(" ")) {
+		if _, err := w.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return r.err
+}
+
+// failingStore refuses every upload without reading it
+type failingStore struct {
+	storage.Store
+}
+
+func (failingStore) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	return errors.New("bucket unavailable")
+}
+
+func newStreamingTestApp(t *testing.T, renderer streamRenderer, cfg Config) *App {
+	t.Helper()
+	mockPlantService := new(mocks.MockPlantService)
+	mockClimateProvider := new(mocks.MockClimateProvider)
+	mockPlantService.On("GetPlantInfo", mock.Anything, "kale").Return(models.PlantInfo{ID: "kale", Name: "Kale"}, nil)
+	mockClimateProvider.On("Normals", mock.Anything, mock.Anything).Return(climate.Normals{}, climate.ErrNoData)
+	cfg.Catalog = mockPlantService
+	cfg.Renderer = renderer
+	cfg.Climate = mockClimateProvider
+	return newTestApp(t, cfg)
+}
+
+const streamedPDF = "%PDF-1.3 kale streamed in chunks %%EOF"
+
+func TestHandleRequest_StreamsReportToStorage(t *testing.T) {
+	t.Parallel()
+	store := storage.NewMemoryStore()
+	a := newStreamingTestApp(t, streamRenderer{pdf: []byte(streamedPDF)}, Config{Store: store})
+
+	response, err := a.HandleRequest(context.TODO(), events.APIGatewayProxyRequest{Body: downloadBody})
+	require.NoError(t, err)
+	require.Equal(t, 200, response.StatusCode)
+	pdf, err := base64.StdEncoding.DecodeString(response.Body)
+	require.NoError(t, err)
+	assert.Equal(t, streamedPDF, string(pdf))
+
+	stored, err := store.Get(context.TODO(), "file.pdf")
+	require.NoError(t, err)
+	assert.Equal(t, streamedPDF, string(stored))
+}
+
+func TestHandleRequest_StreamedReportOverInlineLimit(t *testing.T) {
+	t.Parallel()
+	store := storage.NewMemoryStore()
+	a := newStreamingTestApp(t, streamRenderer{pdf: []byte(streamedPDF)}, Config{Store: store, InlineLimit: 10})
+
+	response, err := a.HandleRequest(context.TODO(), events.APIGatewayProxyRequest{Body: downloadBody})
+	require.NoError(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.False(t, response.IsBase64Encoded)
+	assert.Contains(t, response.Body, `"pdf_url":"memory://file.pdf?expires_in=1h0m0s"`)
+
+	// Only the copy kept for the response was capped, the stored report is whole
+	stored, err := store.Get(context.TODO(), "file.pdf")
+	require.NoError(t, err)
+	assert.Equal(t, streamedPDF, string(stored))
+}
+
+func TestHandleRequest_StreamingErrors(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name     string
+		renderer streamRenderer
+		store    storage.Store
+		message  string
+	}{
+		{"render fails midway", streamRenderer{pdf: []byte(streamedPDF), err: errors.New("font missing")}, storage.NewMemoryStore(), "Failed to generate PDF report"},
+		{"upload refused", streamRenderer{pdf: bytes.Repeat([]byte("%PDF "), 1<<16)}, failingStore{}, "Failed to store PDF report"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := newStreamingTestApp(t, tc.renderer, Config{Store: tc.store})
+			response, err := a.HandleRequest(context.TODO(), events.APIGatewayProxyRequest{
+				Body: `{"location":{"latitude":51.5,"longitude":-0.12},"plant_id":"kale"}`,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, 500, response.StatusCode)
+			assert.Contains(t, response.Body, tc.message)
+		})
+	}
+
+	// A failed render leaves nothing behind
+	store := storage.NewMemoryStore()
+	a := newStreamingTestApp(t, streamRenderer{pdf: []byte(streamedPDF), err: errors.New("font missing")}, Config{Store: store})
+	_, err := a.HandleRequest(context.TODO(), events.APIGatewayProxyRequest{Body: downloadBody})
+	require.NoError(t, err)
+	assert.Empty(t, store.Keys())
+}
@@ -0,0 +1,101 @@
+package app
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/HealthyTechGuy/plant-report-app/internal/auth"
+	"github.com/HealthyTechGuy/plant-report-app/internal/reports"
+	"github.com/HealthyTechGuy/plant-report-app/models"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/apigw"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/pdf"
+	"github.com/aws/aws-lambda-go/events"
+	"go.uber.org/zap"
+)
+
+// GetReport handles GET /report/{id}, returning a report's metadata with a fresh download link.
+// Reports belonging to another principal are answered as not found, so IDs cannot be probed.
+func (a *App) GetReport(ctx context.Context, reportID string) (events.APIGatewayProxyResponse, error) {
+	if a.reports == nil {
+		return responseWithError(404, "Report lookup is not enabled"), nil
+	}
+	// Report IDs are 32 hex characters, anything else cannot be a report
+	if _, err := hex.DecodeString(reportID); err != nil || len(reportID) != 32 {
+		return responseWithError(404, "Report not found"), nil
+	}
+
+	record, err := a.reports.Get(ctx, reportID)
+	if errors.Is(err, reports.ErrNotFound) || err == nil && (record.Expired(a.clock()) || record.OwnerID != reportOwner(ctx)) {
+		return responseWithError(404, "Report not found"), nil
+	}
+	if err != nil {
+		a.logger.Error("error fetching report record", zap.String("report_id", reportID), zap.Error(err))
+		return responseWithError(500, "Failed to fetch report"), nil
+	}
+
+	link, err := a.downloadURL(record.StorageKey)
+	if err != nil {
+		a.logger.Error("error creating download link", zap.String("report_id", reportID), zap.Error(err))
+		return responseWithError(500, "Failed to create download link"), nil
+	}
+
+	return apigw.JSON(200, models.ReportMetadata{
+		ReportID:        record.ReportID,
+		PlantIDs:        record.PlantIDs,
+		Latitude:        record.Latitude,
+		Longitude:       record.Longitude,
+		Format:          record.Format,
+		Language:        record.Language,
+		TemplateVersion: record.TemplateVersion,
+		Size:            record.Size,
+		Checksum:        record.Checksum,
+		CreatedAt:       record.CreatedAt,
+		DownloadURL:     link,
+	}, nil), nil
+}
+
+// reportOwner identifies the principal a request acts for: the signed-in user, otherwise the API
+// client, or nobody when the request is unauthenticated
+func reportOwner(ctx context.Context) string {
+	if user, ok := auth.UserFrom(ctx); ok {
+		return "user:" + user.ID
+	}
+	if client, ok := auth.ClientFrom(ctx); ok {
+		return "client:" + client.ID
+	}
+	return ""
+}
+
+// recordReport records a stored report's metadata, expiring it as the retention policy sets for
+// the client's tier. Failures are logged, the report itself was still delivered.
+func (a *App) recordReport(ctx context.Context, reportID, plantID string, alternatives []models.Alternative, location models.UserLocation, stored storedReport, storageKey string, now time.Time) {
+	if a.reports == nil {
+		return
+	}
+	plantIDs := []string{plantID}
+	for _, alt := range alternatives {
+		plantIDs = append(plantIDs, alt.PlantID)
+	}
+	latitude, longitude := reports.RoundLocation(location.UserLatitude, location.UserLongitude)
+	client, _ := auth.ClientFrom(ctx)
+	err := a.reports.Put(ctx, reports.Record{
+		ReportID:        reportID,
+		OwnerID:         reportOwner(ctx),
+		PlantIDs:        plantIDs,
+		Latitude:        latitude,
+		Longitude:       longitude,
+		Format:          "pdf",
+		Language:        pdf.Language,
+		TemplateVersion: pdf.TemplateVersion,
+		Size:            stored.Size,
+		Checksum:        stored.Checksum,
+		StorageKey:      storageKey,
+		CreatedAt:       now,
+		ExpiresAt:       a.retention.ExpiresAt(client.Tier, now),
+	})
+	if err != nil {
+		a.logger.Error("error recording report metadata", zap.String("report_id", reportID), zap.Error(err))
+	}
+}
@@ -0,0 +1,189 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/HealthyTechGuy/plant-report-app/internal/auth"
+	"github.com/HealthyTechGuy/plant-report-app/internal/reportcache"
+	"github.com/HealthyTechGuy/plant-report-app/internal/reports"
+	"github.com/HealthyTechGuy/plant-report-app/internal/retention"
+	"github.com/HealthyTechGuy/plant-report-app/models"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/pdf"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/storage"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// kaleChecksum is the SHA-256 of the PDF rendered by newDownloadTestApp
+const kaleChecksum = "d8c365877fe4cc82efc934d8bb2dc8127a341ba71a3f6174b7e77c01dc72af9d"
+
+// generateReport posts a kale report through the routes and returns its ID
+func generateReport(t *testing.T, a *App) string {
+	t.Helper()
+	response, err := a.Handler()(context.TODO(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Path:       "/report",
+		Body:       `{"location":{"latitude":51.50735,"longitude":-0.12776},"plant_id":"kale"}`,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 200, response.StatusCode)
+	var summary models.Response
+	require.NoError(t, json.Unmarshal([]byte(response.Body), &summary))
+	require.Len(t, summary.ReportID, 32)
+	return summary.ReportID
+}
+
+func getReport(t *testing.T, a *App, reportID string) events.APIGatewayProxyResponse {
+	t.Helper()
+	return getReportAs(t, context.TODO(), a, reportID)
+}
+
+// getReportAs looks up a report on behalf of the principal in ctx
+func getReportAs(t *testing.T, ctx context.Context, a *App, reportID string) events.APIGatewayProxyResponse {
+	t.Helper()
+	response, err := a.Handler()(ctx, events.APIGatewayProxyRequest{HTTPMethod: "GET", Path: "/report/" + reportID})
+	require.NoError(t, err)
+	return response
+}
+
+func TestGetReport(t *testing.T) {
+	t.Parallel()
+	records := reports.NewMemoryStore()
+	a := newDownloadTestApp(t, Config{Reports: records})
+	reportID := generateReport(t, a)
+
+	response := getReport(t, a, reportID)
+	require.Equal(t, 200, response.StatusCode)
+	var metadata models.ReportMetadata
+	require.NoError(t, json.Unmarshal([]byte(response.Body), &metadata))
+	assert.Equal(t, models.ReportMetadata{
+		ReportID:        reportID,
+		PlantIDs:        []string{"kale"},
+		Latitude:        51.51,
+		Longitude:       -0.13,
+		Format:          "pdf",
+		Language:        "en",
+		TemplateVersion: pdf.TemplateVersion,
+		Size:            int64(len("%PDF-1.3 kale")),
+		Checksum:        kaleChecksum,
+		CreatedAt:       testNow,
+		DownloadURL:     "memory://reports/" + reportID + ".pdf?expires_in=1h0m0s",
+	}, metadata)
+
+	// The record points at where the report was stored
+	record, err := records.Get(context.TODO(), reportID)
+	require.NoError(t, err)
+	assert.Equal(t, "reports/"+reportID+".pdf", record.StorageKey)
+}
+
+func TestGetReport_CachedReport(t *testing.T) {
+	t.Parallel()
+	store := storage.NewMemoryStore()
+	records := reports.NewMemoryStore()
+	a := newDownloadTestApp(t, Config{Store: store, Cache: reportcache.New(store, 0.1), Reports: records})
+
+	// Both reports are served from the same cached PDF and described by the same metadata
+	first, second := generateReport(t, a), generateReport(t, a)
+	require.NotEqual(t, first, second)
+	firstRecord, err := records.Get(context.TODO(), first)
+	require.NoError(t, err)
+	secondRecord, err := records.Get(context.TODO(), second)
+	require.NoError(t, err)
+	assert.Equal(t, kaleChecksum, secondRecord.Checksum)
+	assert.Equal(t, firstRecord.Size, secondRecord.Size)
+	assert.Equal(t, firstRecord.StorageKey, secondRecord.StorageKey)
+}
+
+func TestGetReport_Retention(t *testing.T) {
+	t.Parallel()
+	records := reports.NewMemoryStore()
+	policy, err := retention.ParsePolicy([]string{"free=7", "*=30"})
+	require.NoError(t, err)
+	a := newDownloadTestApp(t, Config{Reports: records, Retention: policy})
+
+	acme := auth.WithClient(context.TODO(), auth.Client{ID: "acme", Tier: "free"})
+	response, err := a.HandleRequest(acme, events.APIGatewayProxyRequest{
+		Body: `{"location":{"latitude":51.5,"longitude":-0.12},"plant_id":"kale"}`,
+	})
+	require.NoError(t, err)
+	var summary models.Response
+	require.NoError(t, json.Unmarshal([]byte(response.Body), &summary))
+	record, err := records.Get(context.TODO(), summary.ReportID)
+	require.NoError(t, err)
+	assert.Equal(t, testNow.AddDate(0, 0, 7), record.ExpiresAt)
+
+	// Requests without a client fall back to the * entry
+	record, err = records.Get(context.TODO(), generateReport(t, a))
+	require.NoError(t, err)
+	assert.Equal(t, testNow.AddDate(0, 0, 30), record.ExpiresAt)
+
+	// Expired records are gone even before the table's TTL removes them
+	later := newDownloadTestApp(t, Config{Reports: records, Clock: func() time.Time { return testNow.AddDate(0, 0, 8) }})
+	assert.Equal(t, 404, getReportAs(t, acme, later, summary.ReportID).StatusCode)
+	assert.Equal(t, 200, getReport(t, later, record.ReportID).StatusCode)
+}
+
+func TestGetReport_Owner(t *testing.T) {
+	t.Parallel()
+	records := reports.NewMemoryStore()
+	a := newDownloadTestApp(t, Config{Reports: records})
+
+	acme := auth.WithClient(context.TODO(), auth.Client{ID: "acme"})
+	response, err := a.HandleRequest(acme, events.APIGatewayProxyRequest{
+		Body: `{"location":{"latitude":51.5,"longitude":-0.12},"plant_id":"kale"}`,
+	})
+	require.NoError(t, err)
+	var summary models.Response
+	require.NoError(t, json.Unmarshal([]byte(response.Body), &summary))
+	record, err := records.Get(context.TODO(), summary.ReportID)
+	require.NoError(t, err)
+	assert.Equal(t, "client:acme", record.OwnerID)
+
+	assert.Equal(t, 200, getReportAs(t, acme, a, summary.ReportID).StatusCode)
+
+	// Anyone else is told the report does not exist
+	others := map[string]context.Context{
+		"other client":    auth.WithClient(context.TODO(), auth.Client{ID: "globex"}),
+		"user":            auth.WithUser(context.TODO(), auth.User{ID: "acme"}),
+		"unauthenticated": context.TODO(),
+	}
+	for name, ctx := range others {
+		t.Run(name, func(t *testing.T) {
+			response := getReportAs(t, ctx, a, summary.ReportID)
+			assert.Equal(t, 404, response.StatusCode)
+			assert.Contains(t, response.Body, "Report not found")
+		})
+	}
+}
+
+func TestGetReport_Errors(t *testing.T) {
+	t.Parallel()
+	enabled := newDownloadTestApp(t, Config{Reports: reports.NewMemoryStore()})
+	disabled := newDownloadTestApp(t, Config{})
+
+	cases := []struct {
+		name     string
+		app      *App
+		reportID string
+		message  string
+	}{
+		{"disabled", disabled, "0123456789abcdef0123456789abcdef", "Report lookup is not enabled"},
+		{"unknown", enabled, "0123456789abcdef0123456789abcdef", "Report not found"},
+		{"malformed", enabled, "../file", "Report not found"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := getReport(t, tc.app, tc.reportID)
+			assert.Equal(t, 404, response.StatusCode)
+			assert.Contains(t, response.Body, tc.message)
+		})
+	}
+
+	response, err := enabled.Handler()(context.TODO(), events.APIGatewayProxyRequest{HTTPMethod: "DELETE", Path: "/report/0123456789abcdef0123456789abcdef"})
+	require.NoError(t, err)
+	assert.Equal(t, 405, response.StatusCode)
+}
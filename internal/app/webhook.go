@@ -0,0 +1,96 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/HealthyTechGuy/plant-report-app/internal/auth"
+	"github.com/HealthyTechGuy/plant-report-app/internal/webhook"
+	"github.com/HealthyTechGuy/plant-report-app/models"
+	"github.com/aws/aws-lambda-go/events"
+	"go.uber.org/zap"
+)
+
+// callbackTimeout bounds a callback delivery including its retries
+const callbackTimeout = 20 * time.Second
+
+// callbackMargin is kept back from the invocation deadline to return the response after a callback
+const callbackMargin = 500 * time.Millisecond
+
+// callbackSecret checks a request's callback URL and returns the secret its payloads are signed
+// with, or the reason the callback cannot be used
+func (a *App) callbackSecret(ctx context.Context, callbackURL string) (string, string) {
+	if a.webhooks == nil {
+		return "", "Callbacks are not enabled"
+	}
+	if err := webhook.ValidateURL(callbackURL); err != nil {
+		a.logger.Info("invalid callback URL", zap.Error(err))
+		return "", "Invalid callback_url: must be an absolute http or https URL"
+	}
+	client, ok := auth.ClientFrom(ctx)
+	if !ok || client.WebhookSecret == "" {
+		return "", "Callbacks need an API key with a webhook secret"
+	}
+	return client.WebhookSecret, ""
+}
+
+// notifyCallback tells the callback URL how the request ended: report.completed with the report
+// summary, or report.failed with the error returned to the caller. The payload is delivered before
+// returning, within the request's deadline, unless callbacks are delivered in the background.
+// Delivery failures are only logged.
+func (a *App) notifyCallback(ctx context.Context, callbackURL, secret, plantID string, summary models.Response, response events.APIGatewayProxyResponse) {
+	payload := webhook.Payload{
+		DeliveryID: newReportID(),
+		Event:      webhook.EventReportCompleted,
+		ReportID:   summary.ReportID,
+		PlantID:    plantID,
+		CreatedAt:  a.clock().UTC(),
+	}
+	if response.StatusCode >= 200 && response.StatusCode <= 299 {
+		payload.PDFUrl = summary.PDFUrl
+		payload.Suitability = summary.Suitability
+	} else {
+		var failure models.Response
+		_ = json.Unmarshal([]byte(response.Body), &failure)
+		payload.Event = webhook.EventReportFailed
+		payload.Error = failure.Message
+	}
+
+	deliver := func(ctx context.Context) {
+		if err := a.webhooks.Deliver(ctx, callbackURL, secret, payload); err != nil {
+			a.logger.Error("error notifying callback URL", zap.String("event", payload.Event), zap.Error(err))
+		}
+	}
+
+	// The caller hangs up once it has its response, the callback is still owed
+	if a.backgroundHooks {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), callbackTimeout)
+		a.callbacks.Add(1)
+		go func() {
+			defer a.callbacks.Done()
+			defer cancel()
+			deliver(ctx)
+		}()
+		return
+	}
+
+	// Otherwise the callback has until the invocation deadline, less time to send the response
+	timeout := callbackTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = min(timeout, time.Until(deadline)-callbackMargin)
+	}
+	if timeout <= 0 {
+		a.logger.Error("no time left to notify callback URL", zap.String("event", payload.Event))
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+	deliver(ctx)
+}
+
+// WaitForCallbacks blocks until every callback delivered in the background has been delivered or
+// given up on. Call it before the process exits so owed callbacks are not dropped.
+func (a *App) WaitForCallbacks() {
+	a.callbacks.Wait()
+}
@@ -0,0 +1,203 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/HealthyTechGuy/plant-report-app/internal/auth"
+	plant "github.com/HealthyTechGuy/plant-report-app/internal/plant-service"
+	"github.com/HealthyTechGuy/plant-report-app/internal/plant-service/mocks"
+	"github.com/HealthyTechGuy/plant-report-app/internal/webhook"
+	"github.com/HealthyTechGuy/plant-report-app/models"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/climate"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newCallbackReceiver starts a server that checks each callback's signature and passes its payload on
+func newCallbackReceiver(t *testing.T, secret string) (string, <-chan webhook.Payload) {
+	t.Helper()
+	payloads := make(chan webhook.Payload, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !webhook.Verify(secret, r.Header.Get(webhook.TimestampHeader), body, r.Header.Get(webhook.SignatureHeader)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var payload webhook.Payload
+		assert.NoError(t, json.Unmarshal(body, &payload))
+		payloads <- payload
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL + "/hooks", payloads
+}
+
+func partnerContext() context.Context {
+	return auth.WithClient(context.TODO(), auth.Client{ID: "acme", WebhookSecret: "partner-secret"})
+}
+
+func callbackBody(callbackURL string) string {
+	return `{"location":{"latitude":51.5,"longitude":-0.12},"plant_id":"kale","callback_url":"` + callbackURL + `"}`
+}
+
+func TestHandleRequest_CallbackOnCompletion(t *testing.T) {
+	t.Parallel()
+	callbackURL, payloads := newCallbackReceiver(t, "partner-secret")
+	a := newDownloadTestApp(t, Config{Webhooks: webhook.NewSender(nil, webhook.AllowPrivateNetworks())})
+
+	response, err := a.HandleRequest(partnerContext(), events.APIGatewayProxyRequest{Body: callbackBody(callbackURL)})
+	require.NoError(t, err)
+	require.Equal(t, 200, response.StatusCode)
+
+	var summary models.Response
+	require.NoError(t, json.Unmarshal([]byte(response.Body), &summary))
+	require.Len(t, summary.ReportID, 32)
+
+	payload := <-payloads
+	assert.Equal(t, webhook.EventReportCompleted, payload.Event)
+	assert.Equal(t, summary.ReportID, payload.ReportID)
+	assert.Equal(t, "kale", payload.PlantID)
+	assert.Equal(t, "memory://reports/"+summary.ReportID+".pdf", payload.PDFUrl)
+	assert.NotEmpty(t, payload.DeliveryID)
+	assert.Empty(t, payload.Error)
+}
+
+func TestHandleRequest_CallbackOnFailure(t *testing.T) {
+	t.Parallel()
+	callbackURL, payloads := newCallbackReceiver(t, "partner-secret")
+
+	mockPlantService := new(mocks.MockPlantService)
+	mockPDFGenerator := new(mocks.MockPDFGenerator)
+	mockClimateProvider := new(mocks.MockClimateProvider)
+	mockPlantService.On("GetPlantInfo", mock.Anything, "kale").Return(models.PlantInfo{ID: "kale", Name: "Kale"}, nil)
+	mockClimateProvider.On("Normals", mock.Anything, mock.Anything).Return(climate.Normals{}, climate.ErrNoData)
+	mockPDFGenerator.On("GeneratePDF", mock.Anything).Return([]byte(nil), errors.New("renderer down"))
+	a := newTestApp(t, Config{
+		Catalog:  mockPlantService,
+		Renderer: mockPDFGenerator,
+		Climate:  mockClimateProvider,
+		Webhooks: webhook.NewSender(nil, webhook.AllowPrivateNetworks()),
+	})
+
+	response, err := a.HandleRequest(partnerContext(), events.APIGatewayProxyRequest{Body: callbackBody(callbackURL)})
+	require.NoError(t, err)
+	assert.Equal(t, 500, response.StatusCode)
+
+	payload := <-payloads
+	assert.Equal(t, webhook.EventReportFailed, payload.Event)
+	assert.Equal(t, "kale", payload.PlantID)
+	assert.Equal(t, "Failed to generate PDF report", payload.Error)
+	assert.Empty(t, payload.PDFUrl)
+}
+
+func TestHandleRequest_CallbackOnPlantLookupFailure(t *testing.T) {
+	t.Parallel()
+	callbackURL, payloads := newCallbackReceiver(t, "partner-secret")
+
+	mockPlantService := new(mocks.MockPlantService)
+	mockPlantService.On("GetPlantInfo", mock.Anything, "kale").Return(models.PlantInfo{}, plant.ErrServiceUnavailable)
+	a := newTestApp(t, Config{
+		Catalog:  mockPlantService,
+		Webhooks: webhook.NewSender(nil, webhook.AllowPrivateNetworks()),
+	})
+
+	response, err := a.HandleRequest(partnerContext(), events.APIGatewayProxyRequest{Body: callbackBody(callbackURL)})
+	require.NoError(t, err)
+	assert.Equal(t, 503, response.StatusCode)
+
+	payload := <-payloads
+	assert.Equal(t, webhook.EventReportFailed, payload.Event)
+	assert.Equal(t, "kale", payload.PlantID)
+	assert.Equal(t, "Plant information is temporarily unavailable, please try again shortly", payload.Error)
+}
+
+func TestHandleRequest_CallbackDoesNotHoldResponse(t *testing.T) {
+	t.Parallel()
+	// The receiver answers only once the response has been returned
+	release := make(chan struct{})
+	delivered := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusNoContent)
+		close(delivered)
+	}))
+	t.Cleanup(server.Close)
+	a := newDownloadTestApp(t, Config{
+		Webhooks:            webhook.NewSender(nil, webhook.AllowPrivateNetworks()),
+		BackgroundCallbacks: true,
+	})
+
+	response, err := a.HandleRequest(partnerContext(), events.APIGatewayProxyRequest{Body: callbackBody(server.URL + "/hooks")})
+	require.NoError(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+
+	close(release)
+	a.WaitForCallbacks()
+	select {
+	case <-delivered:
+	default:
+		t.Fatal("callback was not delivered")
+	}
+}
+
+func TestHandleRequest_CallbackWithinDeadline(t *testing.T) {
+	t.Parallel()
+	// The receiver never answers, the callback must give up before the invocation deadline
+	attempted := make(chan struct{}, 8)
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempted <- struct{}{}
+		<-done
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(done) })
+	a := newDownloadTestApp(t, Config{Webhooks: webhook.NewSender(nil, webhook.AllowPrivateNetworks())})
+
+	ctx, cancel := context.WithTimeout(partnerContext(), time.Second)
+	defer cancel()
+	response, err := a.HandleRequest(ctx, events.APIGatewayProxyRequest{Body: callbackBody(server.URL + "/hooks")})
+	require.NoError(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+
+	// Delivered before returning, and given up on in time to send the response
+	assert.NotEmpty(t, attempted)
+	deadline, _ := ctx.Deadline()
+	assert.True(t, time.Now().Before(deadline), "callback ran past the invocation deadline")
+}
+
+func TestHandleRequest_CallbackErrors(t *testing.T) {
+	t.Parallel()
+	enabled := newDownloadTestApp(t, Config{Webhooks: webhook.NewSender(nil)})
+	disabled := newDownloadTestApp(t, Config{})
+	noSecret := auth.WithClient(context.TODO(), auth.Client{ID: "acme"})
+
+	cases := []struct {
+		name    string
+		app     *App
+		ctx     context.Context
+		url     string
+		message string
+	}{
+		{"disabled", disabled, partnerContext(), "https://partner.example.com/hooks", "Callbacks are not enabled"},
+		{"invalid URL", enabled, partnerContext(), "partner.example.com/hooks", "Invalid callback_url: must be an absolute http or https URL"},
+		{"no secret", enabled, noSecret, "https://partner.example.com/hooks", "Callbacks need an API key with a webhook secret"},
+		{"no client", enabled, context.TODO(), "https://partner.example.com/hooks", "Callbacks need an API key with a webhook secret"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			response, err := tc.app.HandleRequest(tc.ctx, events.APIGatewayProxyRequest{Body: callbackBody(tc.url)})
+			require.NoError(t, err)
+			assert.Equal(t, 400, response.StatusCode)
+			assert.Contains(t, response.Body, tc.message)
+		})
+	}
+}
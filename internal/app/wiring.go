@@ -0,0 +1,233 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/HealthyTechGuy/plant-report-app/internal/auth"
+	"github.com/HealthyTechGuy/plant-report-app/internal/batch"
+	"github.com/HealthyTechGuy/plant-report-app/internal/config"
+	"github.com/HealthyTechGuy/plant-report-app/internal/history"
+	plant "github.com/HealthyTechGuy/plant-report-app/internal/plant-service"
+	"github.com/HealthyTechGuy/plant-report-app/internal/ratelimit"
+	"github.com/HealthyTechGuy/plant-report-app/internal/reportcache"
+	"github.com/HealthyTechGuy/plant-report-app/internal/reports"
+	"github.com/HealthyTechGuy/plant-report-app/internal/retention"
+	"github.com/HealthyTechGuy/plant-report-app/internal/webhook"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/apigw"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/awsclient"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/climate"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/frost"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/logger"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/mailer"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/pdf"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/pests"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/storage"
+	"github.com/aws/aws-sdk-go/aws"
+	"go.uber.org/zap"
+)
+
+// FromEnv loads and validates the configuration, then builds the production App from it.
+// The Lambda, the local server and the CLI all start from here so they share the same wiring.
+func FromEnv() (*App, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	return FromConfig(cfg)
+}
+
+// FromConfig builds the production App from a loaded configuration
+func FromConfig(cfg config.Config) (*App, error) {
+	deps, err := Dependencies(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return New(deps)
+}
+
+// BatchFromConfig builds the production App and a batch Processor over its report store. Batches
+// need the report cache, without it every report in a batch would be stored under the same key.
+func BatchFromConfig(cfg config.Config) (*App, *batch.Processor, error) {
+	if !cfg.ReportCache {
+		return nil, nil, errors.New("batch mode needs REPORT_CACHE on")
+	}
+	deps, err := Dependencies(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	a, err := New(deps)
+	if err != nil {
+		return nil, nil, err
+	}
+	return a, batch.NewProcessor(a.HandleRequest, deps.Store, cfg.BatchWorkers, deps.Logger), nil
+}
+
+// CleanupFromConfig builds the cleanup job deleting stored reports whose metadata records have
+// expired. It needs the reports table, without it no report has a record and all would be deleted.
+func CleanupFromConfig(cfg config.Config, opts ...retention.Option) (*retention.Cleaner, error) {
+	if cfg.ReportsTable == "" {
+		return nil, errors.New("cleanup needs REPORTS_TABLE")
+	}
+	deps, err := Dependencies(cfg)
+	if err != nil {
+		return nil, err
+	}
+	opts = append([]retention.Option{retention.WithGracePeriod(cfg.CleanupGracePeriod)}, opts...)
+	return retention.NewCleaner(deps.Store, deps.Reports, deps.Logger, opts...), nil
+}
+
+// Dependencies wires the production dependencies: DynamoDB for the catalog, S3 for reports
+// and the climate and frost datasets
+func Dependencies(cfg config.Config) (Config, error) {
+	logger.InitLogger(cfg.LogLevel)
+	logger.Debug("effective configuration", zap.Any("config", cfg.Redacted()))
+
+	// One AWS session is shared by every client and reused across warm invocations
+	awsClients, err := awsclient.NewFactory(cfg.AWS())
+	if err != nil {
+		return Config{}, err
+	}
+
+	frostEstimator, err := frost.NewEstimator()
+	if err != nil {
+		return Config{}, fmt.Errorf("error loading frost station data: %w", err)
+	}
+	climateProvider, err := newClimateProvider(cfg, frostEstimator)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var pestCatalog *pests.Catalog
+	if cfg.PestRisk {
+		if pestCatalog, err = pests.NewEmbeddedCatalog(); err != nil {
+			return Config{}, fmt.Errorf("error loading pest data: %w", err)
+		}
+	}
+
+	// PlantService retries DynamoDB calls itself, so the SDK's retryer is turned off for its client
+	dynamoDBClient := awsClients.DynamoDB(aws.NewConfig().WithMaxRetries(0))
+	retry := plant.DefaultRetryPolicy
+	retry.MaxAttempts = cfg.DynamoDBMaxAttempts
+	plantService := plant.NewPlantService(dynamoDBClient, cfg.TableName,
+		plant.WithRetryPolicy(retry),
+		plant.WithConsistentRead(cfg.DynamoDBConsistentRead),
+	)
+
+	var store storage.Store = storage.NewS3Store(awsClients.S3(), cfg.BucketName, awsClients.ObjectURL)
+	if cfg.LocalStorageDir != "" {
+		if store, err = storage.NewFileStore(cfg.LocalStorageDir); err != nil {
+			return Config{}, err
+		}
+	}
+	var reportCache *reportcache.Cache
+	if cfg.ReportCache {
+		reportCache = reportcache.New(store, cfg.ReportCacheCellDegrees)
+	}
+
+	// CORS runs first so preflights and error responses reach browsers, then the per-IP rate limit
+	// refuses floods before any credential lookup. The per-client limit runs after authentication,
+	// keyed on the client it verified.
+	newLimiter := func(perMinute float64, burst int) ratelimit.Limiter {
+		limit := ratelimit.PerMinute(perMinute, burst)
+		if cfg.RateLimitTable != "" {
+			return ratelimit.NewDynamoLimiter(awsClients.DynamoDB(), cfg.RateLimitTable, limit)
+		}
+		return ratelimit.NewMemoryLimiter(limit)
+	}
+	var middleware []apigw.Middleware
+	if len(cfg.CORSAllowedOrigins) > 0 {
+		middleware = append(middleware, apigw.NewCORS(cfg.CORSAllowedOrigins).Middleware)
+	}
+	if cfg.RateLimitIPPerMinute > 0 {
+		limiter := newLimiter(cfg.RateLimitIPPerMinute, cfg.RateLimitIPBurst)
+		middleware = append(middleware, ratelimit.Middleware(limiter, ratelimit.IPKey, logger.Logger))
+	}
+	if cfg.Auth {
+		var authOpts []auth.Option
+		if cfg.JWKSFile != "" {
+			keys, err := auth.LoadJWKSFile(cfg.JWKSFile)
+			if err != nil {
+				return Config{}, err
+			}
+			authOpts = append(authOpts, auth.WithJWTVerifier(auth.NewJWTVerifier(keys, cfg.JWTIssuer, cfg.JWTAudience)))
+		}
+		dynamoDB := awsClients.DynamoDB()
+		authenticator := auth.NewAuthenticator(
+			auth.NewDynamoCredentialStore(dynamoDB, cfg.APIKeysTable),
+			auth.NewDynamoQuotaStore(dynamoDB, cfg.UsageTable),
+			logger.Logger,
+			authOpts...,
+		)
+		middleware = append(middleware, authenticator.Middleware)
+		if cfg.RateLimitPerMinute > 0 {
+			limiter := newLimiter(cfg.RateLimitPerMinute, cfg.RateLimitBurst)
+			middleware = append(middleware, ratelimit.Middleware(limiter, ratelimit.ClientKey, logger.Logger))
+		}
+	}
+
+	var reportMailer mailer.Mailer
+	switch {
+	case cfg.SMTPAddr != "":
+		reportMailer = mailer.NewSMTPMailer(cfg.SMTPAddr, cfg.MailFrom, cfg.SMTPUsername, cfg.SMTPPassword)
+	case cfg.MailFrom != "":
+		reportMailer = mailer.NewSESMailer(awsClients.SES(), cfg.MailFrom)
+	}
+
+	var webhooks *webhook.Sender
+	if cfg.Webhooks {
+		webhooks = webhook.NewSender(logger.Logger)
+	}
+
+	var reportHistory history.Store
+	if cfg.HistoryTable != "" {
+		reportHistory = history.NewDynamoStore(awsClients.DynamoDB(), cfg.HistoryTable)
+	}
+
+	var reportRecords reports.Store
+	if cfg.ReportsTable != "" {
+		reportRecords = reports.NewDynamoStore(awsClients.DynamoDB(), cfg.ReportsTable)
+	}
+	// Validate has already checked the policy
+	reportRetention, _ := retention.ParsePolicy(cfg.ReportRetention)
+
+	return Config{
+		Catalog: plant.NewCachedPlantService(plantService, plant.CacheOptions{
+			TTL:        cfg.PlantCacheTTL,
+			MaxEntries: cfg.PlantCacheSize,
+		}),
+		Renderer:    pdf.NewPDFService(),
+		Store:       store,
+		ReportKey:   cfg.ReportKey,
+		Cache:       reportCache,
+		Climate:     climateProvider,
+		Frost:       frostEstimator,
+		Logger:      logger.Logger,
+		Clock:       time.Now,
+		Middleware:  middleware,
+		History:     reportHistory,
+		LinkTTL:     cfg.ReportLinkTTL,
+		Mailer:      reportMailer,
+		Webhooks:    webhooks,
+		Parallelism: cfg.PipelineParallelism,
+		Reports:     reportRecords,
+		Retention:   reportRetention,
+		Companions:  cfg.CompanionPlanting,
+		Pests:       pestCatalog,
+	}, nil
+}
+
+// newClimateProvider picks the climate data source, using CLIMATE_API_URL when set
+// and falling back to the embedded offline dataset otherwise. The frost estimator fills in frost
+// dates the climate service leaves out.
+func newClimateProvider(cfg config.Config, frostEstimator *frost.Estimator) (climate.ClimateProvider, error) {
+	if cfg.ClimateAPIURL != "" {
+		return climate.NewHTTPProvider(cfg.ClimateAPIURL, nil, frostEstimator), nil
+	}
+	provider, err := climate.NewEmbeddedProvider()
+	if err != nil {
+		return nil, fmt.Errorf("error loading embedded climate data: %w", err)
+	}
+	return provider, nil
+}
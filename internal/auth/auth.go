@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HealthyTechGuy/plant-report-app/pkg/apigw"
+	"github.com/aws/aws-lambda-go/events"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrUnknownCredential is returned when an API key or token is not recognised
+	ErrUnknownCredential = errors.New("unknown credential")
+	// ErrQuotaExceeded is returned when a client has used up its daily quota
+	ErrQuotaExceeded = errors.New("daily quota exceeded")
+)
+
+// Client is an API consumer identified by an API key or bearer token
+type Client struct {
+	ID   string
+	Name string
+	// Tier is the client's plan, e.g. "free" or "partner"
+	Tier string
+	// DailyQuota is how many reports the client may generate per UTC day, 0 means unlimited
+	DailyQuota int
+	// Disabled clients are recognised but refused
+	Disabled bool
+	// WebhookSecret signs the client's report callbacks, clients without one cannot use callback_url
+	WebhookSecret string
+}
+
+type clientKey struct{}
+
+// WithClient returns a context carrying the authenticated client
+func WithClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+// ClientFrom returns the authenticated client stored in ctx
+func ClientFrom(ctx context.Context) (Client, bool) {
+	client, ok := ctx.Value(clientKey{}).(Client)
+	return client, ok
+}
+
+// Authenticator checks credentials and daily quotas before a request reaches the handler
+type Authenticator struct {
+	credentials CredentialStore
+	quotas      QuotaStore
+	jwt         *JWTVerifier
+	logger      *zap.Logger
+	now         func() time.Time
+}
+
+// Option configures optional Authenticator behaviour
+type Option func(*Authenticator)
+
+// WithJWTVerifier accepts bearer tokens that are JWTs signed by the verifier's keys. The token's
+// subject becomes the request's User, and the user acts as a client of its own with no daily quota.
+func WithJWTVerifier(verifier *JWTVerifier) Option {
+	return func(a *Authenticator) {
+		a.jwt = verifier
+	}
+}
+
+// NewAuthenticator creates an Authenticator. A nil logger logs nothing.
+func NewAuthenticator(credentials CredentialStore, quotas QuotaStore, logger *zap.Logger, opts ...Option) *Authenticator {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	a := &Authenticator{
+		credentials: credentials,
+		quotas:      quotas,
+		logger:      logger,
+		now:         time.Now,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Middleware rejects requests without a valid API key (X-Api-Key header) or bearer token
+// with 401, disabled clients with 403 and report requests from clients over their daily quota
+// with 429. Accepted requests carry the client, and the user for JWTs, in their context, and
+// report requests the quota in RateLimit-* headers.
+func (a *Authenticator) Middleware(next apigw.HandlerFunc) apigw.HandlerFunc {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		credential := Credential(request)
+		if credential == "" {
+			return apigw.ErrorResponse(401, "Missing API key or bearer token", map[string]string{
+				"WWW-Authenticate": `Bearer realm="plant-report"`,
+			}), nil
+		}
+
+		if a.jwt != nil && looksLikeJWT(credential) {
+			user, err := a.jwt.Verify(credential)
+			if err != nil {
+				a.logger.Info("rejected token", zap.Error(err))
+				return apigw.ErrorResponse(401, "Invalid API key or bearer token", map[string]string{
+					"WWW-Authenticate": `Bearer realm="plant-report", error="invalid_token"`,
+				}), nil
+			}
+			ctx = WithUser(ctx, user)
+			return next(WithClient(ctx, Client{ID: "user:" + user.ID, Name: user.Email, Tier: "user"}), request)
+		}
+
+		client, err := a.credentials.Lookup(ctx, credential)
+		if errors.Is(err, ErrUnknownCredential) {
+			return apigw.ErrorResponse(401, "Invalid API key or bearer token", map[string]string{
+				"WWW-Authenticate": `Bearer realm="plant-report", error="invalid_token"`,
+			}), nil
+		}
+		if err != nil {
+			a.logger.Error("error looking up credential", zap.Error(err))
+			return apigw.ErrorResponse(503, "Authentication is temporarily unavailable, please try again shortly", nil), nil
+		}
+		if client.Disabled {
+			a.logger.Info("refused disabled client", zap.String("client_id", client.ID))
+			return apigw.ErrorResponse(403, "API access has been disabled for this client", nil), nil
+		}
+
+		now := a.now().UTC()
+		day := now.Format("2006-01-02")
+		var headers map[string]string
+		counted := client.DailyQuota > 0 && generatesReport(request)
+		if counted {
+			usage, err := a.quotas.Reserve(ctx, client.ID, day, client.DailyQuota)
+			headers = quotaHeaders(client.DailyQuota, usage.Used, now)
+			if errors.Is(err, ErrQuotaExceeded) {
+				headers["Retry-After"] = headers["RateLimit-Reset"]
+				return apigw.ErrorResponse(429, "Daily report quota exceeded", headers), nil
+			}
+			if err != nil {
+				a.logger.Error("error reserving quota", zap.String("client_id", client.ID), zap.Error(err))
+				return apigw.ErrorResponse(503, "Authentication is temporarily unavailable, please try again shortly", nil), nil
+			}
+		}
+
+		response, err := next(WithClient(ctx, client), request)
+
+		// Only generated reports count against the quota
+		if counted && (err != nil || response.StatusCode >= 400) {
+			if releaseErr := a.quotas.Release(ctx, client.ID, day); releaseErr != nil {
+				a.logger.Warn("error releasing quota", zap.String("client_id", client.ID), zap.Error(releaseErr))
+			}
+		}
+		if response.Headers == nil && len(headers) > 0 {
+			response.Headers = make(map[string]string, len(headers))
+		}
+		for name, value := range headers {
+			response.Headers[name] = value
+		}
+		return response, err
+	}
+}
+
+// Credential extracts the API key or bearer token from a request, preferring X-Api-Key
+func Credential(request events.APIGatewayProxyRequest) string {
+	if key := strings.TrimSpace(apigw.Header(request, "X-Api-Key")); key != "" {
+		return key
+	}
+	scheme, token, ok := strings.Cut(strings.TrimSpace(apigw.Header(request, "Authorization")), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// generatesReport reports whether a request asks for a new report, POST /report or a direct
+// invocation without a method. Only those count against the daily quota; reading back reports
+// already generated is free.
+func generatesReport(request events.APIGatewayProxyRequest) bool {
+	return request.HTTPMethod == "" ||
+		request.HTTPMethod == http.MethodPost && strings.TrimSuffix(request.Path, "/") == "/report"
+}
+
+// quotaHeaders builds the RateLimit-* headers for a daily quota that resets at UTC midnight
+func quotaHeaders(limit, used int, now time.Time) map[string]string {
+	remaining := limit - used
+	if remaining < 0 {
+		remaining = 0
+	}
+	midnight := now.Truncate(24 * time.Hour).Add(24 * time.Hour)
+	reset := int(midnight.Sub(now).Seconds())
+	if reset < 1 {
+		reset = 1
+	}
+	return map[string]string{
+		"RateLimit-Limit":     strconv.Itoa(limit),
+		"RateLimit-Remaining": strconv.Itoa(remaining),
+		"RateLimit-Reset":     strconv.Itoa(reset),
+	}
+}
@@ -0,0 +1,201 @@
+package auth
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/HealthyTechGuy/plant-report-app/internal/plant-service/mocks"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/apigw"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestAuthenticator returns an Authenticator over in-memory stores with a fixed clock
+func newTestAuthenticator(clients map[string]Client) *Authenticator {
+	a := NewAuthenticator(NewMemoryCredentialStore(clients), NewMemoryQuotaStore(), nil)
+	a.now = func() time.Time { return time.Date(2026, time.May, 1, 23, 0, 0, 0, time.UTC) }
+	return a
+}
+
+// okHandler responds with status and records the client it was called for
+func okHandler(status int, seen *Client) apigw.HandlerFunc {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		*seen, _ = ClientFrom(ctx)
+		return events.APIGatewayProxyResponse{StatusCode: status}, nil
+	}
+}
+
+func TestMiddleware_MissingCredential(t *testing.T) {
+	var seen Client
+	handler := newTestAuthenticator(nil).Middleware(okHandler(200, &seen))
+
+	response, err := handler(context.TODO(), events.APIGatewayProxyRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, 401, response.StatusCode)
+	assert.Equal(t, `Bearer realm="plant-report"`, response.Headers["WWW-Authenticate"])
+	assert.Empty(t, seen.ID)
+}
+
+func TestMiddleware_InvalidCredential(t *testing.T) {
+	var seen Client
+	handler := newTestAuthenticator(map[string]Client{"good-key": {ID: "acme"}}).Middleware(okHandler(200, &seen))
+
+	response, err := handler(context.TODO(), events.APIGatewayProxyRequest{
+		Headers: map[string]string{"x-api-key": "bad-key"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 401, response.StatusCode)
+	assert.Contains(t, response.Headers["WWW-Authenticate"], `error="invalid_token"`)
+}
+
+func TestMiddleware_DisabledClient(t *testing.T) {
+	var seen Client
+	handler := newTestAuthenticator(map[string]Client{"key": {ID: "acme", Disabled: true}}).Middleware(okHandler(200, &seen))
+
+	response, err := handler(context.TODO(), events.APIGatewayProxyRequest{
+		Headers: map[string]string{"X-Api-Key": "key"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 403, response.StatusCode)
+	assert.Empty(t, seen.ID)
+}
+
+func TestMiddleware_BearerTokenAndQuota(t *testing.T) {
+	var seen Client
+	handler := newTestAuthenticator(map[string]Client{"token": {ID: "acme", DailyQuota: 2}}).Middleware(okHandler(200, &seen))
+	request := events.APIGatewayProxyRequest{
+		Headers: map[string]string{"Authorization": "Bearer token"},
+	}
+
+	for remaining := 1; remaining >= 0; remaining-- {
+		response, err := handler(context.TODO(), request)
+		require.NoError(t, err)
+		assert.Equal(t, 200, response.StatusCode)
+		assert.Equal(t, "2", response.Headers["RateLimit-Limit"])
+		assert.Equal(t, strconv.Itoa(remaining), response.Headers["RateLimit-Remaining"])
+		assert.Equal(t, "acme", seen.ID)
+	}
+
+	response, err := handler(context.TODO(), request)
+	require.NoError(t, err)
+	assert.Equal(t, 429, response.StatusCode)
+	assert.Equal(t, "0", response.Headers["RateLimit-Remaining"])
+	// An hour before UTC midnight
+	assert.Equal(t, "3600", response.Headers["Retry-After"])
+	assert.Equal(t, "3600", response.Headers["RateLimit-Reset"])
+}
+
+func TestMiddleware_FailedReportsDoNotCount(t *testing.T) {
+	var seen Client
+	a := newTestAuthenticator(map[string]Client{"key": {ID: "acme", DailyQuota: 1}})
+	request := events.APIGatewayProxyRequest{Headers: map[string]string{"X-Api-Key": "key"}}
+
+	response, err := a.Middleware(okHandler(500, &seen))(context.TODO(), request)
+	require.NoError(t, err)
+	assert.Equal(t, 500, response.StatusCode)
+
+	response, err = a.Middleware(okHandler(200, &seen))(context.TODO(), request)
+	require.NoError(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+}
+
+func TestMiddleware_ReadsDoNotCount(t *testing.T) {
+	var seen Client
+	quotas := NewMemoryQuotaStore()
+	a := NewAuthenticator(NewMemoryCredentialStore(map[string]Client{"key": {ID: "acme", DailyQuota: 1}}), quotas, nil)
+	handler := a.Middleware(okHandler(200, &seen))
+	headers := map[string]string{"X-Api-Key": "key"}
+
+	for _, request := range []events.APIGatewayProxyRequest{
+		{HTTPMethod: "GET", Path: "/me/reports", Headers: headers},
+		{HTTPMethod: "GET", Path: "/me/reports", Headers: headers},
+		{HTTPMethod: "GET", Path: "/report/abc", Headers: headers},
+	} {
+		response, err := handler(context.TODO(), request)
+		require.NoError(t, err)
+		assert.Equal(t, 200, response.StatusCode)
+		assert.NotContains(t, response.Headers, "RateLimit-Remaining")
+		assert.Equal(t, "acme", seen.ID)
+	}
+	assert.Empty(t, quotas.counts)
+
+	response, err := handler(context.TODO(), events.APIGatewayProxyRequest{HTTPMethod: "POST", Path: "/report", Headers: headers})
+	require.NoError(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, "0", response.Headers["RateLimit-Remaining"])
+}
+
+func TestMiddleware_UnlimitedClient(t *testing.T) {
+	var seen Client
+	handler := newTestAuthenticator(map[string]Client{"key": {ID: "internal"}}).Middleware(okHandler(200, &seen))
+
+	response, err := handler(context.TODO(), events.APIGatewayProxyRequest{Headers: map[string]string{"X-Api-Key": "key"}})
+	require.NoError(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.NotContains(t, response.Headers, "RateLimit-Limit")
+	assert.Equal(t, "internal", seen.ID)
+}
+
+func TestDynamoCredentialStore_Lookup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDynamoDB := mocks.NewMockDynamoDBAPI(ctrl)
+	store := NewDynamoCredentialStore(mockDynamoDB, "api-keys")
+
+	mockDynamoDB.EXPECT().GetItemWithContext(gomock.Any(), &dynamodb.GetItemInput{
+		TableName: aws.String("api-keys"),
+		Key: map[string]*dynamodb.AttributeValue{
+			"KeyHash": {S: aws.String(HashCredential("secret"))},
+		},
+	}).Return(&dynamodb.GetItemOutput{
+		Item: map[string]*dynamodb.AttributeValue{
+			"KeyHash":        {S: aws.String(HashCredential("secret"))},
+			"client_id":      {S: aws.String("acme")},
+			"name":           {S: aws.String("Acme Garden Centre")},
+			"tier":           {S: aws.String("partner")},
+			"daily_quota":    {N: aws.String("250")},
+			"webhook_secret": {S: aws.String("whsec")},
+		},
+	}, nil)
+	mockDynamoDB.EXPECT().GetItemWithContext(gomock.Any(), gomock.Any()).Return(&dynamodb.GetItemOutput{}, nil)
+
+	client, err := store.Lookup(context.TODO(), "secret")
+	require.NoError(t, err)
+	assert.Equal(t, Client{ID: "acme", Name: "Acme Garden Centre", Tier: "partner", DailyQuota: 250, WebhookSecret: "whsec"}, client)
+
+	_, err = store.Lookup(context.TODO(), "unknown")
+	assert.ErrorIs(t, err, ErrUnknownCredential)
+}
+
+func TestDynamoQuotaStore_Reserve(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDynamoDB := mocks.NewMockDynamoDBAPI(ctrl)
+	store := NewDynamoQuotaStore(mockDynamoDB, "usage")
+
+	mockDynamoDB.EXPECT().UpdateItemWithContext(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx aws.Context, in *dynamodb.UpdateItemInput, _ ...interface{}) (*dynamodb.UpdateItemOutput, error) {
+			assert.Equal(t, "acme#2026-05-01", aws.StringValue(in.Key["UsageKey"].S))
+			assert.Equal(t, "10", aws.StringValue(in.ExpressionAttributeValues[":limit"].N))
+			return &dynamodb.UpdateItemOutput{Attributes: map[string]*dynamodb.AttributeValue{"used": {N: aws.String("3")}}}, nil
+		})
+	mockDynamoDB.EXPECT().UpdateItemWithContext(gomock.Any(), gomock.Any()).Return(nil,
+		awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "limit reached", nil))
+
+	usage, err := store.Reserve(context.TODO(), "acme", "2026-05-01", 10)
+	require.NoError(t, err)
+	assert.Equal(t, 3, usage.Used)
+
+	usage, err = store.Reserve(context.TODO(), "acme", "2026-05-01", 10)
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+	assert.Equal(t, 10, usage.Used)
+}
@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// CredentialStore resolves API keys and bearer tokens to clients
+type CredentialStore interface {
+	// Lookup returns ErrUnknownCredential when the credential is not recognised
+	Lookup(ctx context.Context, credential string) (Client, error)
+}
+
+// HashCredential returns the hex SHA-256 of a credential. Stores only ever keep the hash,
+// so a leaked table does not leak usable keys.
+func HashCredential(credential string) string {
+	sum := sha256.Sum256([]byte(credential))
+	return hex.EncodeToString(sum[:])
+}
+
+// MemoryCredentialStore is an in-memory CredentialStore for tests and local development
+type MemoryCredentialStore struct {
+	mu      sync.RWMutex
+	clients map[string]Client
+}
+
+// NewMemoryCredentialStore creates a MemoryCredentialStore from credentials to clients
+func NewMemoryCredentialStore(clients map[string]Client) *MemoryCredentialStore {
+	s := &MemoryCredentialStore{clients: make(map[string]Client, len(clients))}
+	for credential, client := range clients {
+		s.Add(credential, client)
+	}
+	return s
+}
+
+// Add registers a credential for a client
+func (s *MemoryCredentialStore) Add(credential string, client Client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clients[HashCredential(credential)] = client
+}
+
+// Lookup returns the client registered for credential
+func (s *MemoryCredentialStore) Lookup(ctx context.Context, credential string) (Client, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	client, ok := s.clients[HashCredential(credential)]
+	if !ok {
+		return Client{}, ErrUnknownCredential
+	}
+	return client, nil
+}
+
+// DynamoCredentialStore reads clients from a DynamoDB table keyed by KeyHash, the
+// HashCredential of the API key or token
+type DynamoCredentialStore struct {
+	client    dynamodbiface.DynamoDBAPI
+	tableName string
+}
+
+// NewDynamoCredentialStore creates a DynamoCredentialStore
+func NewDynamoCredentialStore(client dynamodbiface.DynamoDBAPI, tableName string) *DynamoCredentialStore {
+	return &DynamoCredentialStore{client: client, tableName: tableName}
+}
+
+// Lookup reads the client registered for credential
+func (s *DynamoCredentialStore) Lookup(ctx context.Context, credential string) (Client, error) {
+	result, err := s.client.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.tableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"KeyHash": {S: aws.String(HashCredential(credential))},
+		},
+	})
+	if err != nil {
+		return Client{}, fmt.Errorf("failed to get credential from DynamoDB: %w", err)
+	}
+	if result.Item == nil {
+		return Client{}, ErrUnknownCredential
+	}
+
+	item := result.Item
+	client := Client{
+		ID:            aws.StringValue(attr(item, "client_id").S),
+		Name:          aws.StringValue(attr(item, "name").S),
+		Tier:          aws.StringValue(attr(item, "tier").S),
+		Disabled:      aws.BoolValue(attr(item, "disabled").BOOL),
+		WebhookSecret: aws.StringValue(attr(item, "webhook_secret").S),
+	}
+	if n := attr(item, "daily_quota").N; n != nil {
+		if client.DailyQuota, err = strconv.Atoi(*n); err != nil {
+			return Client{}, fmt.Errorf("invalid daily_quota for client %s: %w", client.ID, err)
+		}
+	}
+	return client, nil
+}
+
+// attr returns an attribute or an empty value when it is missing
+func attr(item map[string]*dynamodb.AttributeValue, name string) *dynamodb.AttributeValue {
+	if v, ok := item[name]; ok && v != nil {
+		return v
+	}
+	return &dynamodb.AttributeValue{}
+}
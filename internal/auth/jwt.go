@@ -0,0 +1,212 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrInvalidToken is returned when a JWT is malformed, badly signed, expired or meant for someone else
+var ErrInvalidToken = errors.New("invalid token")
+
+// clockSkew is how far token times may be off from our clock
+const clockSkew = time.Minute
+
+// User is the person identified by a verified JWT
+type User struct {
+	// ID is the token subject, stable for the lifetime of the account
+	ID    string
+	Email string
+}
+
+type userKey struct{}
+
+// WithUser returns a context carrying the authenticated user
+func WithUser(ctx context.Context, user User) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// UserFrom returns the authenticated user stored in ctx
+func UserFrom(ctx context.Context) (User, bool) {
+	user, ok := ctx.Value(userKey{}).(User)
+	return user, ok
+}
+
+// JWTVerifier verifies RS256 signed JWTs, such as Cognito ID and access tokens, against a JSON Web Key Set
+type JWTVerifier struct {
+	keys     map[string]*rsa.PublicKey
+	issuer   string
+	audience string
+	now      func() time.Time
+}
+
+// NewJWTVerifier creates a JWTVerifier. Empty issuer or audience skip that check.
+// For Cognito access tokens, which have no aud claim, the audience is matched against client_id.
+func NewJWTVerifier(keys map[string]*rsa.PublicKey, issuer, audience string) *JWTVerifier {
+	return &JWTVerifier{keys: keys, issuer: issuer, audience: audience, now: time.Now}
+}
+
+// LoadJWKSFile reads the RSA keys of a JSON Web Key Set file, keyed by kid
+func LoadJWKSFile(path string) (map[string]*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+	}
+	return ParseJWKS(data)
+}
+
+// ParseJWKS parses the RSA keys of a JSON Web Key Set, keyed by kid
+func ParseJWKS(data []byte) (map[string]*rsa.PublicKey, error) {
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse JWKS key %q: invalid modulus: %w", k.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse JWKS key %q: invalid exponent: %w", k.Kid, err)
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("failed to parse JWKS: no RSA signing keys")
+	}
+	return keys, nil
+}
+
+// claims are the registered and Cognito claims the verifier reads
+type claims struct {
+	Subject  string   `json:"sub"`
+	Issuer   string   `json:"iss"`
+	Audience audience `json:"aud"`
+	ClientID string   `json:"client_id"`
+	Expiry   *int64   `json:"exp"`
+	NotBef   *int64   `json:"nbf"`
+	Email    string   `json:"email"`
+}
+
+// audience accepts the aud claim as a single string or a list
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+// Verify checks a token's signature, expiry, issuer and audience and returns the user it identifies
+func (v *JWTVerifier) Verify(token string) (User, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return User{}, fmt.Errorf("%w: not a JWT", ErrInvalidToken)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return User{}, fmt.Errorf("%w: malformed header", ErrInvalidToken)
+	}
+	if header.Alg != "RS256" {
+		return User{}, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, header.Alg)
+	}
+	key, ok := v.keys[header.Kid]
+	if !ok {
+		return User{}, fmt.Errorf("%w: unknown key %q", ErrInvalidToken, header.Kid)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return User{}, fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return User{}, fmt.Errorf("%w: bad signature", ErrInvalidToken)
+	}
+
+	var c claims
+	if err := decodeSegment(parts[1], &c); err != nil {
+		return User{}, fmt.Errorf("%w: malformed claims", ErrInvalidToken)
+	}
+
+	now := v.now()
+	switch {
+	case c.Subject == "":
+		return User{}, fmt.Errorf("%w: missing subject", ErrInvalidToken)
+	case c.Expiry == nil:
+		return User{}, fmt.Errorf("%w: missing expiry", ErrInvalidToken)
+	case now.After(time.Unix(*c.Expiry, 0).Add(clockSkew)):
+		return User{}, fmt.Errorf("%w: expired", ErrInvalidToken)
+	case c.NotBef != nil && now.Add(clockSkew).Before(time.Unix(*c.NotBef, 0)):
+		return User{}, fmt.Errorf("%w: not valid yet", ErrInvalidToken)
+	case v.issuer != "" && c.Issuer != v.issuer:
+		return User{}, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidToken, c.Issuer)
+	case v.audience != "" && !c.hasAudience(v.audience):
+		return User{}, fmt.Errorf("%w: not issued for this audience", ErrInvalidToken)
+	}
+	return User{ID: c.Subject, Email: c.Email}, nil
+}
+
+func (c claims) hasAudience(want string) bool {
+	if c.ClientID == want {
+		return true
+	}
+	for _, aud := range c.Audience {
+		if aud == want {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeSegment decodes a base64url JSON segment of a JWT
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// looksLikeJWT reports whether a bearer token has the three-part shape of a JWT
+func looksLikeJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testTime = time.Date(2026, time.May, 1, 12, 0, 0, 0, time.UTC)
+
+// signJWT signs claims with key as an RS256 JWT
+func signJWT(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]any) string {
+	t.Helper()
+	segment := func(v any) string {
+		data, err := json.Marshal(v)
+		require.NoError(t, err)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signingInput := segment(map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid}) + "." + segment(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// writeJWKS writes the public half of key to a JWKS file and returns its path
+func writeJWKS(t *testing.T, key *rsa.PrivateKey, kid string) string {
+	t.Helper()
+	jwks, err := json.Marshal(map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"alg": "RS256",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, jwks, 0o600))
+	return path
+}
+
+// newTestVerifier returns a verifier trusting key, loaded through a JWKS file
+func newTestVerifier(t *testing.T, key *rsa.PrivateKey) *JWTVerifier {
+	t.Helper()
+	keys, err := LoadJWKSFile(writeJWKS(t, key, "test-key"))
+	require.NoError(t, err)
+	v := NewJWTVerifier(keys, "https://issuer.example.com", "plant-report-web")
+	v.now = func() time.Time { return testTime }
+	return v
+}
+
+func validClaims() map[string]any {
+	return map[string]any{
+		"sub":   "user-123",
+		"email": "gardener@example.com",
+		"iss":   "https://issuer.example.com",
+		"aud":   "plant-report-web",
+		"exp":   testTime.Add(time.Hour).Unix(),
+	}
+}
+
+func TestJWTVerifier_Verify(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	v := newTestVerifier(t, key)
+
+	user, err := v.Verify(signJWT(t, key, "test-key", validClaims()))
+	require.NoError(t, err)
+	assert.Equal(t, User{ID: "user-123", Email: "gardener@example.com"}, user)
+
+	// Cognito access tokens carry client_id instead of aud
+	accessToken := validClaims()
+	delete(accessToken, "aud")
+	accessToken["client_id"] = "plant-report-web"
+	_, err = v.Verify(signJWT(t, key, "test-key", accessToken))
+	assert.NoError(t, err)
+
+	forged := validClaims()
+	forged["sub"] = "someone-else"
+	genuine := strings.Split(signJWT(t, key, "test-key", validClaims()), ".")
+	tampered := strings.Split(signJWT(t, otherKey, "test-key", forged), ".")
+
+	tests := map[string]string{
+		"wrong key":     signJWT(t, otherKey, "test-key", validClaims()),
+		"unknown kid":   signJWT(t, key, "rotated-key", validClaims()),
+		"not a jwt":     "abc.def",
+		"tampered body": genuine[0] + "." + tampered[1] + "." + genuine[2],
+	}
+	for name, claimsChange := range map[string]func(map[string]any){
+		"expired":         func(c map[string]any) { c["exp"] = testTime.Add(-2 * time.Minute).Unix() },
+		"no expiry":       func(c map[string]any) { delete(c, "exp") },
+		"not yet valid":   func(c map[string]any) { c["nbf"] = testTime.Add(5 * time.Minute).Unix() },
+		"wrong issuer":    func(c map[string]any) { c["iss"] = "https://evil.example.com" },
+		"wrong audience":  func(c map[string]any) { c["aud"] = []string{"someone-else"} },
+		"missing subject": func(c map[string]any) { delete(c, "sub") },
+	} {
+		c := validClaims()
+		claimsChange(c)
+		tests[name] = signJWT(t, key, "test-key", c)
+	}
+
+	for name, token := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := v.Verify(token)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+}
+
+func TestJWTVerifier_RejectsOtherAlgorithms(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	v := newTestVerifier(t, key)
+
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","kid":"test-key"}`))
+	body, _ := json.Marshal(validClaims())
+	_, err = v.Verify(header + "." + base64.RawURLEncoding.EncodeToString(body) + ".")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestMiddleware_JWTUser(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	a := NewAuthenticator(NewMemoryCredentialStore(nil), NewMemoryQuotaStore(), nil, WithJWTVerifier(newTestVerifier(t, key)))
+
+	var seenUser User
+	var seenClient Client
+	handler := a.Middleware(func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		seenUser, _ = UserFrom(ctx)
+		seenClient, _ = ClientFrom(ctx)
+		return events.APIGatewayProxyResponse{StatusCode: 200}, nil
+	})
+
+	response, err := handler(context.TODO(), events.APIGatewayProxyRequest{
+		Headers: map[string]string{"Authorization": "Bearer " + signJWT(t, key, "test-key", validClaims())},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, "user-123", seenUser.ID)
+	assert.Equal(t, "user:user-123", seenClient.ID)
+
+	expired := validClaims()
+	expired["exp"] = testTime.Add(-time.Hour).Unix()
+	response, err = handler(context.TODO(), events.APIGatewayProxyRequest{
+		Headers: map[string]string{"Authorization": "Bearer " + signJWT(t, key, "test-key", expired)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 401, response.StatusCode)
+}
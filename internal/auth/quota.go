@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// usageRetention is how long daily usage counters are kept before DynamoDB expires them
+const usageRetention = 48 * time.Hour
+
+// Usage is a client's report count for one day
+type Usage struct {
+	Used int
+}
+
+// QuotaStore counts reports per client per day
+type QuotaStore interface {
+	// Reserve counts one report, returning ErrQuotaExceeded and the current usage once limit is reached
+	Reserve(ctx context.Context, clientID, day string, limit int) (Usage, error)
+	// Release gives back a reservation for a report that was not generated
+	Release(ctx context.Context, clientID, day string) error
+}
+
+// MemoryQuotaStore is an in-memory QuotaStore for tests and the local server
+type MemoryQuotaStore struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// NewMemoryQuotaStore creates an empty MemoryQuotaStore
+func NewMemoryQuotaStore() *MemoryQuotaStore {
+	return &MemoryQuotaStore{counts: make(map[string]int)}
+}
+
+// Reserve counts one report if the client is under limit
+func (s *MemoryQuotaStore) Reserve(ctx context.Context, clientID, day string, limit int) (Usage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := usageKey(clientID, day)
+	if s.counts[key] >= limit {
+		return Usage{Used: s.counts[key]}, ErrQuotaExceeded
+	}
+	s.counts[key]++
+	return Usage{Used: s.counts[key]}, nil
+}
+
+// Release gives back one reservation
+func (s *MemoryQuotaStore) Release(ctx context.Context, clientID, day string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if key := usageKey(clientID, day); s.counts[key] > 0 {
+		s.counts[key]--
+	}
+	return nil
+}
+
+// DynamoQuotaStore keeps daily counters in a DynamoDB table keyed by UsageKey ("client#day").
+// Counters are updated atomically with a condition, so concurrent Lambdas cannot overshoot the limit,
+// and carry an expires_at attribute for DynamoDB TTL.
+type DynamoQuotaStore struct {
+	client    dynamodbiface.DynamoDBAPI
+	tableName string
+	now       func() time.Time
+}
+
+// NewDynamoQuotaStore creates a DynamoQuotaStore
+func NewDynamoQuotaStore(client dynamodbiface.DynamoDBAPI, tableName string) *DynamoQuotaStore {
+	return &DynamoQuotaStore{client: client, tableName: tableName, now: time.Now}
+}
+
+// Reserve atomically increments the counter while it is below limit
+func (s *DynamoQuotaStore) Reserve(ctx context.Context, clientID, day string, limit int) (Usage, error) {
+	result, err := s.client.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(s.tableName),
+		Key:                 s.key(clientID, day),
+		UpdateExpression:    aws.String("ADD used :one SET expires_at = :expires"),
+		ConditionExpression: aws.String("attribute_not_exists(used) OR used < :limit"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":one":     {N: aws.String("1")},
+			":limit":   {N: aws.String(strconv.Itoa(limit))},
+			":expires": {N: aws.String(strconv.FormatInt(s.now().Add(usageRetention).Unix(), 10))},
+		},
+		ReturnValues: aws.String(dynamodb.ReturnValueUpdatedNew),
+	})
+	var aerr awserr.Error
+	if errors.As(err, &aerr) && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return Usage{Used: limit}, ErrQuotaExceeded
+	}
+	if err != nil {
+		return Usage{}, fmt.Errorf("failed to update usage in DynamoDB: %w", err)
+	}
+
+	var usage Usage
+	if n := attr(result.Attributes, "used").N; n != nil {
+		usage.Used, _ = strconv.Atoi(*n)
+	}
+	return usage, nil
+}
+
+// Release decrements the counter, never below zero
+func (s *DynamoQuotaStore) Release(ctx context.Context, clientID, day string) error {
+	_, err := s.client.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(s.tableName),
+		Key:                 s.key(clientID, day),
+		UpdateExpression:    aws.String("ADD used :minus"),
+		ConditionExpression: aws.String("used > :zero"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":minus": {N: aws.String("-1")},
+			":zero":  {N: aws.String("0")},
+		},
+	})
+	var aerr awserr.Error
+	if errors.As(err, &aerr) && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to release usage in DynamoDB: %w", err)
+	}
+	return nil
+}
+
+func (s *DynamoQuotaStore) key(clientID, day string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"UsageKey": {S: aws.String(usageKey(clientID, day))},
+	}
+}
+
+func usageKey(clientID, day string) string {
+	return clientID + "#" + day
+}
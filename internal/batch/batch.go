@@ -0,0 +1,204 @@
+// Package batch generates many reports from one CSV of plant_id,lat,lon rows and records the
+// outcome of each row in a manifest CSV
+package batch
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/HealthyTechGuy/plant-report-app/internal/pipeline"
+	"github.com/HealthyTechGuy/plant-report-app/models"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// MaxRows is the most rows one batch may hold, so a batch fits in a single invocation
+const MaxRows = 5000
+
+// DefaultWorkers is how many reports are generated at once when no worker count is given
+const DefaultWorkers = 8
+
+// Row statuses written to the manifest
+const (
+	StatusOK      = "ok"
+	StatusFailed  = "failed"
+	StatusInvalid = "invalid"
+	// StatusSkipped rows were not attempted because the batch ran out of time
+	StatusSkipped = "skipped"
+)
+
+// ErrTooManyRows is returned for CSVs with more than MaxRows rows
+var ErrTooManyRows = fmt.Errorf("batch has more than %d rows", MaxRows)
+
+// Handler generates one report, App.HandleRequest satisfies it
+type Handler func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
+
+// Row is one line of a batch CSV
+type Row struct {
+	// Line is the row's line number in the CSV, counting the header
+	Line      int
+	PlantID   string
+	Latitude  float64
+	Longitude float64
+	// Problem explains why the row could not be read, such rows are not generated
+	Problem string
+}
+
+// Result is the outcome of one row
+type Result struct {
+	Row
+	Status string
+	PDFUrl string
+	Error  string
+}
+
+// ReadRows reads plant_id,lat,lon rows from a CSV. A header row is optional. Rows that cannot be
+// read are returned with a Problem rather than failing the whole batch.
+func ReadRows(r io.Reader) ([]Row, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var rows []Row
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			rows = append(rows, Row{Line: parseErr.Line, Problem: parseErr.Err.Error()})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read batch CSV: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		if line == 1 && strings.EqualFold(strings.TrimSpace(record[0]), "plant_id") {
+			continue
+		}
+		if len(rows) == MaxRows {
+			return nil, ErrTooManyRows
+		}
+		rows = append(rows, parseRow(line, record))
+	}
+	return rows, nil
+}
+
+// parseRow checks one CSV record
+func parseRow(line int, record []string) Row {
+	row := Row{Line: line}
+	if len(record) != 3 {
+		row.Problem = fmt.Sprintf("expected 3 columns (plant_id,lat,lon), got %d", len(record))
+		return row
+	}
+	row.PlantID = strings.TrimSpace(record[0])
+	lat, latErr := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+	lon, lonErr := strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+	row.Latitude, row.Longitude = lat, lon
+	switch {
+	case row.PlantID == "":
+		row.Problem = "missing plant_id"
+	case latErr != nil || lat < -90 || lat > 90:
+		row.Problem = fmt.Sprintf("invalid latitude %q", record[1])
+	case lonErr != nil || lon < -180 || lon > 180:
+		row.Problem = fmt.Sprintf("invalid longitude %q", record[2])
+	}
+	return row
+}
+
+// Run generates a report for every readable row with at most workers running at once and returns
+// the results in row order. Rows not started before ctx is done, or cut off by it, are skipped.
+func Run(ctx context.Context, handler Handler, rows []Row, workers int) []Result {
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	// generate records failures in the result rather than returning them, so no row cancels the rest
+	results, _ := pipeline.Map(ctx, workers, rows, func(ctx context.Context, row Row) (Result, error) {
+		return generate(ctx, handler, row), nil
+	})
+	return results
+}
+
+// generate runs one row through the handler
+func generate(ctx context.Context, handler Handler, row Row) Result {
+	result := Result{Row: row}
+	if row.Problem != "" {
+		result.Status, result.Error = StatusInvalid, row.Problem
+		return result
+	}
+	if err := ctx.Err(); err != nil {
+		result.Status, result.Error = StatusSkipped, err.Error()
+		return result
+	}
+
+	req := models.Request{PlantID: row.PlantID}
+	req.Location.Latitude, req.Location.Longitude = row.Latitude, row.Longitude
+	body, err := json.Marshal(req)
+	if err != nil {
+		result.Status, result.Error = StatusFailed, err.Error()
+		return result
+	}
+	response, err := handler(ctx, events.APIGatewayProxyRequest{HTTPMethod: "POST", Path: "/report", Body: string(body)})
+	if ctxErr := ctx.Err(); ctxErr != nil && (err != nil || response.StatusCode != 200) {
+		result.Status, result.Error = StatusSkipped, ctxErr.Error()
+		return result
+	}
+	if err != nil {
+		result.Status, result.Error = StatusFailed, err.Error()
+		return result
+	}
+
+	var summary models.Response
+	_ = json.Unmarshal([]byte(response.Body), &summary)
+	if response.StatusCode != 200 {
+		result.Status, result.Error = StatusFailed, summary.Message
+		if result.Error == "" {
+			result.Error = fmt.Sprintf("status %d", response.StatusCode)
+		}
+		return result
+	}
+	result.Status, result.PDFUrl = StatusOK, summary.PDFUrl
+	return result
+}
+
+// manifestHeader names the manifest columns
+var manifestHeader = []string{"line", "plant_id", "lat", "lon", "status", "pdf_url", "error"}
+
+// WriteManifest writes one manifest line per result
+func WriteManifest(w io.Writer, results []Result) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(manifestHeader); err != nil {
+		return err
+	}
+	for _, r := range results {
+		if err := writer.Write([]string{
+			strconv.Itoa(r.Line),
+			r.PlantID,
+			strconv.FormatFloat(r.Latitude, 'f', -1, 64),
+			strconv.FormatFloat(r.Longitude, 'f', -1, 64),
+			r.Status,
+			r.PDFUrl,
+			r.Error,
+		}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// Counts tallies results by status
+func Counts(results []Result) map[string]int {
+	counts := make(map[string]int)
+	for _, r := range results {
+		counts[r.Status]++
+	}
+	return counts
+}
@@ -0,0 +1,156 @@
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/HealthyTechGuy/plant-report-app/models"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/apigw"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/storage"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeHandler answers like the report API: unknown plants get a 404, others a link per plant
+func fakeHandler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var req models.Request
+	if err := json.Unmarshal([]byte(request.Body), &req); err != nil {
+		return apigw.ErrorResponse(400, "Invalid request body", nil), nil
+	}
+	if req.PlantID == "triffid" {
+		return apigw.ErrorResponse(404, "Plant not found", nil), nil
+	}
+	return apigw.JSON(200, models.Response{PDFUrl: "https://example.com/" + req.PlantID + ".pdf"}, nil), nil
+}
+
+func TestReadRows(t *testing.T) {
+	rows, err := ReadRows(strings.NewReader("plant_id,lat,lon\nkale, 51.5, -0.12\n\ntomato,40.7,-74\nbasil,abc,1\n,1,1\nmint,1\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []Row{
+		{Line: 2, PlantID: "kale", Latitude: 51.5, Longitude: -0.12},
+		{Line: 4, PlantID: "tomato", Latitude: 40.7, Longitude: -74},
+		{Line: 5, PlantID: "basil", Latitude: 0, Longitude: 1, Problem: `invalid latitude "abc"`},
+		{Line: 6, Latitude: 1, Longitude: 1, Problem: "missing plant_id"},
+		{Line: 7, Problem: "expected 3 columns (plant_id,lat,lon), got 2"},
+	}, rows)
+
+	// The header is optional
+	rows, err = ReadRows(strings.NewReader("kale,51.5,-0.12\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []Row{{Line: 1, PlantID: "kale", Latitude: 51.5, Longitude: -0.12}}, rows)
+
+	_, err = ReadRows(strings.NewReader(strings.Repeat("kale,51.5,-0.12\n", MaxRows+1)))
+	assert.ErrorIs(t, err, ErrTooManyRows)
+}
+
+func TestRun_BoundsConcurrency(t *testing.T) {
+	var running, peak atomic.Int32
+	handler := func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		return fakeHandler(ctx, request)
+	}
+
+	rows := make([]Row, 20)
+	for i := range rows {
+		rows[i] = Row{Line: i + 1, PlantID: "kale", Latitude: 51.5, Longitude: -0.12}
+	}
+	results := Run(context.TODO(), handler, rows, 3)
+	require.Len(t, results, 20)
+	assert.LessOrEqual(t, peak.Load(), int32(3))
+	for i, r := range results {
+		assert.Equal(t, i+1, r.Line)
+		assert.Equal(t, StatusOK, r.Status)
+	}
+}
+
+func TestRun_SkipsRowsAfterDeadline(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var once sync.Once
+	handler := func(c context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		once.Do(cancel)
+		return fakeHandler(c, request)
+	}
+
+	rows := []Row{{Line: 1, PlantID: "kale", Latitude: 1, Longitude: 1}, {Line: 2, PlantID: "kale", Latitude: 1, Longitude: 1}}
+	results := Run(ctx, handler, rows, 1)
+	assert.Equal(t, StatusOK, results[0].Status)
+	assert.Equal(t, StatusSkipped, results[1].Status)
+}
+
+func TestProcessor_HandleS3Event(t *testing.T) {
+	store := storage.NewMemoryStore()
+	csv := "plant_id,lat,lon\nkale,51.5,-0.12\ntriffid,51.5,-0.12\nbasil,91,0\n"
+	require.NoError(t, store.Put(context.TODO(), "batches/incoming/acme/june.csv", strings.NewReader(csv), "text/csv"))
+
+	p := NewProcessor(fakeHandler, store, 2, nil)
+	err := p.HandleS3Event(context.TODO(), events.S3Event{Records: []events.S3EventRecord{
+		{S3: events.S3Entity{Object: events.S3Object{Key: "batches/incoming/acme/june.csv", URLDecodedKey: "batches/incoming/acme/june.csv"}}},
+		// Manifests land in the same bucket and must not start another batch
+		{S3: events.S3Entity{Object: events.S3Object{Key: "batches/manifests/acme/may-manifest.csv"}}},
+	}})
+	require.NoError(t, err)
+
+	manifest, err := store.Get(context.TODO(), "batches/manifests/acme/june-manifest.csv")
+	require.NoError(t, err)
+	assert.Equal(t, "line,plant_id,lat,lon,status,pdf_url,error\n"+
+		"2,kale,51.5,-0.12,ok,https://example.com/kale.pdf,\n"+
+		"3,triffid,51.5,-0.12,failed,,Plant not found\n"+
+		"4,basil,91,0,invalid,,\"invalid latitude \"\"91\"\"\"\n", string(manifest))
+	assert.Len(t, store.Keys(), 2)
+}
+
+func TestProcessor_StopsBeforeDeadline(t *testing.T) {
+	store := storage.NewMemoryStore()
+	csv := "kale,51.5,-0.12\nkale,52.5,-0.12\nkale,53.5,-0.12\n"
+	require.NoError(t, store.Put(context.TODO(), "batches/incoming/slow.csv", strings.NewReader(csv), "text/csv"))
+
+	// The first report is quick, the rest run until they are cut off
+	var calls atomic.Int32
+	handler := func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		if calls.Add(1) > 1 {
+			<-ctx.Done()
+			return events.APIGatewayProxyResponse{}, ctx.Err()
+		}
+		return fakeHandler(ctx, request)
+	}
+	p := NewProcessor(handler, store, 1, nil)
+	p.manifestTime = 200 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	manifestKey, err := p.Process(ctx, "batches/incoming/slow.csv")
+	require.NoError(t, err)
+	assert.NoError(t, ctx.Err(), "the manifest should be stored before the deadline")
+
+	manifest, err := store.Get(context.TODO(), manifestKey)
+	require.NoError(t, err)
+	assert.Equal(t, "line,plant_id,lat,lon,status,pdf_url,error\n"+
+		"1,kale,51.5,-0.12,ok,https://example.com/kale.pdf,\n"+
+		"2,kale,52.5,-0.12,skipped,,context deadline exceeded\n"+
+		"3,kale,53.5,-0.12,skipped,,context deadline exceeded\n", string(manifest))
+}
+
+func TestProcessor_MissingBatch(t *testing.T) {
+	p := NewProcessor(fakeHandler, storage.NewMemoryStore(), 2, nil)
+	_, err := p.Process(context.TODO(), "batches/incoming/missing.csv")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestManifestKey(t *testing.T) {
+	assert.Equal(t, "batches/manifests/june-manifest.csv", ManifestKey("batches/incoming/june.csv"))
+	assert.Equal(t, "batches/manifests/acme/june.2026-manifest.csv", ManifestKey("batches/incoming/acme/june.2026.CSV"))
+}
@@ -0,0 +1,103 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/HealthyTechGuy/plant-report-app/pkg/storage"
+	"github.com/aws/aws-lambda-go/events"
+	"go.uber.org/zap"
+)
+
+// Object key prefixes of batch uploads and the manifests written for them
+const (
+	IncomingPrefix = "batches/incoming/"
+	ManifestPrefix = "batches/manifests/"
+)
+
+// manifestTime is kept back from the invocation deadline to store the manifest, and bounds how
+// long storing it may take
+const manifestTime = 10 * time.Second
+
+// Processor runs batches uploaded to the report bucket
+type Processor struct {
+	handler      Handler
+	store        storage.Store
+	workers      int
+	logger       *zap.Logger
+	manifestTime time.Duration
+}
+
+// NewProcessor creates a Processor reading batches from and writing manifests to store
+func NewProcessor(handler Handler, store storage.Store, workers int, logger *zap.Logger) *Processor {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Processor{handler: handler, store: store, workers: workers, logger: logger, manifestTime: manifestTime}
+}
+
+// HandleS3Event processes every CSV under IncomingPrefix in an S3 ObjectCreated event. Other
+// objects are ignored, so the manifests written back to the bucket do not trigger new batches.
+func (p *Processor) HandleS3Event(ctx context.Context, event events.S3Event) error {
+	for _, record := range event.Records {
+		key := record.S3.Object.URLDecodedKey
+		if key == "" {
+			key = record.S3.Object.Key
+		}
+		if !strings.HasPrefix(key, IncomingPrefix) || !strings.EqualFold(path.Ext(key), ".csv") {
+			p.logger.Info("ignoring object outside the batch prefix", zap.String("key", key))
+			continue
+		}
+		if _, err := p.Process(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Process generates the reports for the batch CSV at key and returns the key of its manifest.
+// When ctx has a deadline, generation stops early enough to leave time to store the manifest,
+// and rows it did not get to are recorded as skipped.
+func (p *Processor) Process(ctx context.Context, key string) (string, error) {
+	data, err := p.store.Get(ctx, key)
+	if err != nil {
+		return "", fmt.Errorf("failed to read batch %s: %w", key, err)
+	}
+	rows, err := ReadRows(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to read batch %s: %w", key, err)
+	}
+
+	p.logger.Info("starting batch", zap.String("key", key), zap.Int("rows", len(rows)), zap.Int("workers", p.workers))
+	work, cancel := context.WithCancel(ctx)
+	if deadline, ok := ctx.Deadline(); ok {
+		work, cancel = context.WithDeadline(ctx, deadline.Add(-p.manifestTime))
+	}
+	results := Run(work, p.handler, rows, p.workers)
+	cancel()
+
+	var manifest bytes.Buffer
+	if err := WriteManifest(&manifest, results); err != nil {
+		return "", fmt.Errorf("failed to write manifest for %s: %w", key, err)
+	}
+	manifestKey := ManifestKey(key)
+	// The manifest is stored even when ctx has expired, it records which rows were skipped
+	storeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), p.manifestTime)
+	defer cancel()
+	if err := p.store.Put(storeCtx, manifestKey, &manifest, "text/csv"); err != nil {
+		return "", fmt.Errorf("failed to store manifest for %s: %w", key, err)
+	}
+	p.logger.Info("finished batch", zap.String("key", key), zap.String("manifest", manifestKey), zap.Any("statuses", Counts(results)))
+	return manifestKey, nil
+}
+
+// ManifestKey returns where the manifest of the batch at key is stored: batches/incoming/a/b.csv
+// becomes batches/manifests/a/b-manifest.csv
+func ManifestKey(key string) string {
+	name := strings.TrimPrefix(key, IncomingPrefix)
+	return ManifestPrefix + strings.TrimSuffix(name, path.Ext(name)) + "-manifest.csv"
+}
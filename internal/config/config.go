@@ -0,0 +1,412 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HealthyTechGuy/plant-report-app/internal/retention"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/awsclient"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/mailer"
+)
+
+// FileEnv names the environment variable pointing at an optional JSON config file
+const FileEnv = "CONFIG_FILE"
+
+// redacted replaces secret values in Redacted
+const redacted = "[REDACTED]"
+
+// Config is the typed application configuration. Each field is read from the environment
+// variable named by its env tag, falling back to the config file and then to the default tag.
+// Fields tagged secret are never printed by Redacted.
+type Config struct {
+	TableName  string `env:"TABLE_NAME"`
+	BucketName string `env:"BUCKET_NAME"`
+	// LocalStorageDir stores reports on the local filesystem instead of in BucketName, for local runs
+	LocalStorageDir string `env:"LOCAL_STORAGE_DIR"`
+	// ReportKey is the object key reports are written to when report caching is off
+	ReportKey string `env:"REPORT_KEY" default:"file.pdf"`
+	LogLevel  string `env:"LOG_LEVEL" default:"info"`
+
+	ClimateAPIURL string `env:"CLIMATE_API_URL"`
+
+	ReportCache            bool    `env:"REPORT_CACHE" default:"on"`
+	ReportCacheCellDegrees float64 `env:"REPORT_CACHE_CELL_DEGREES" default:"0.1"`
+
+	PlantCacheTTL  time.Duration `env:"PLANT_CACHE_TTL" default:"10m"`
+	PlantCacheSize int           `env:"PLANT_CACHE_SIZE" default:"500"`
+
+	// Auth requires an API key or bearer token on every request, checked against APIKeysTable
+	// with daily quotas counted in UsageTable
+	Auth         bool   `env:"AUTH" default:"on"`
+	APIKeysTable string `env:"API_KEYS_TABLE"`
+	UsageTable   string `env:"USAGE_TABLE"`
+	// JWKSFile enables signed-in users: bearer JWTs are verified against this JSON Web Key Set,
+	// checking JWTIssuer and JWTAudience when set
+	JWKSFile    string `env:"JWKS_FILE"`
+	JWTIssuer   string `env:"JWT_ISSUER"`
+	JWTAudience string `env:"JWT_AUDIENCE"`
+	// HistoryTable records signed-in users' reports for GET /me/reports
+	HistoryTable string `env:"HISTORY_TABLE"`
+	// ReportsTable records every report's metadata for GET /report/{id}
+	ReportsTable string `env:"REPORTS_TABLE"`
+	// ReportRetention lists how many days each client tier's reports are kept as tier=days
+	// entries, comma separated. The * entry covers other tiers and requests without a client.
+	// Records expire through the table's TTL and the cleanup job then deletes their reports.
+	ReportRetention []string `env:"REPORT_RETENTION" default:"free=7,partner=90,user=30,*=30"`
+	// CleanupGracePeriod is how old a report without a record must be before the cleanup job
+	// deletes it, so reports whose record is still being written are left alone
+	CleanupGracePeriod time.Duration `env:"CLEANUP_GRACE_PERIOD" default:"24h"`
+	// ReportLinkTTL is how long download links in GET /me/reports and GET /report/{id} last
+	ReportLinkTTL time.Duration `env:"REPORT_LINK_TTL" default:"1h"`
+
+	// RateLimitPerMinute limits requests per authenticated client, with bursts of up to
+	// RateLimitBurst. RateLimitIPPerMinute and RateLimitIPBurst limit requests per IP address
+	// before authentication, so floods of made-up credentials never reach the credential store.
+	// Buckets live in RateLimitTable when set and in memory otherwise. 0 turns a limit off.
+	RateLimitPerMinute   float64 `env:"RATE_LIMIT_PER_MINUTE" default:"30"`
+	RateLimitBurst       int     `env:"RATE_LIMIT_BURST" default:"10"`
+	RateLimitIPPerMinute float64 `env:"RATE_LIMIT_IP_PER_MINUTE" default:"120"`
+	RateLimitIPBurst     int     `env:"RATE_LIMIT_IP_BURST" default:"40"`
+	RateLimitTable       string  `env:"RATE_LIMIT_TABLE"`
+
+	// CORSAllowedOrigins lists the browser origins, such as https://app.example.com, that may call
+	// the API, comma separated. "*" allows any origin and an empty list sends no CORS headers.
+	CORSAllowedOrigins []string `env:"CORS_ALLOWED_ORIGINS"`
+
+	// MailFrom enables emailing reports from this address, through the SMTP server at SMTPAddr
+	// (host:port) when set and through SES otherwise
+	MailFrom     string `env:"MAIL_FROM"`
+	SMTPAddr     string `env:"SMTP_ADDR"`
+	SMTPUsername string `env:"SMTP_USERNAME"`
+	SMTPPassword string `env:"SMTP_PASSWORD" secret:"true"`
+
+	// Webhooks lets API clients with a webhook secret ask for a callback_url to be notified when
+	// their report completes or fails
+	Webhooks bool `env:"WEBHOOKS" default:"on"`
+
+	// CompanionPlanting adds a companion planting section built from the relationships recorded in
+	// the plant table, and lets requests list the neighbours their plant is grown with
+	CompanionPlanting bool `env:"COMPANION_PLANTING" default:"on"`
+
+	// PestRisk adds a section ranking the plant's pests and diseases by how much the local climate
+	// favours them
+	PestRisk bool `env:"PEST_RISK" default:"on"`
+
+	// PipelineParallelism is how many independent lookups, such as the report cache and the climate
+	// data, run at once while building a report. 1 runs them one after another.
+	PipelineParallelism int `env:"PIPELINE_PARALLELISM" default:"4"`
+
+	// BatchWorkers is how many reports a batch generates at once
+	BatchWorkers int `env:"BATCH_WORKERS" default:"8"`
+
+	DynamoDBMaxAttempts    int  `env:"DYNAMODB_MAX_ATTEMPTS" default:"4"`
+	DynamoDBConsistentRead bool `env:"DYNAMODB_CONSISTENT_READ" default:"false"`
+
+	AWSRegion     string `env:"AWS_REGION"`
+	AWSEndpoint   string `env:"AWS_ENDPOINT_URL"`
+	AWSMaxRetries int    `env:"AWS_MAX_RETRIES" default:"-1"`
+	// AWSS3ForcePathStyle is nil when unset, which turns path style on only with an endpoint override
+	AWSS3ForcePathStyle *bool `env:"AWS_S3_FORCE_PATH_STYLE"`
+}
+
+// Load reads the configuration from the environment and, when CONFIG_FILE is set, a JSON file
+// of the same variable names. Environment variables win over the file. The result is validated,
+// and every problem found is reported in a single error.
+func Load() (Config, error) {
+	return load(os.LookupEnv)
+}
+
+func load(lookupEnv func(string) (string, bool)) (Config, error) {
+	var file map[string]string
+	if path, ok := lookupEnv(FileEnv); ok && path != "" {
+		var err error
+		if file, err = readFile(path); err != nil {
+			return Config{}, err
+		}
+	}
+
+	lookup := func(name string) (string, bool) {
+		if v, ok := lookupEnv(name); ok {
+			return v, true
+		}
+		v, ok := file[name]
+		return v, ok
+	}
+
+	var cfg Config
+	var errs []error
+	v := reflect.ValueOf(&cfg).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("env")
+		raw, ok := lookup(name)
+		if !ok || raw == "" {
+			raw, ok = field.Tag.Lookup("default")
+		}
+		if !ok {
+			continue
+		}
+		if err := setField(v.Field(i), raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid value %q: %w", name, raw, err))
+		}
+	}
+	if len(errs) > 0 {
+		return cfg, configError(errs)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// readFile reads a JSON object of variable names to values. Values may be strings, numbers or booleans.
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	values := make(map[string]string, len(raw))
+	for name, value := range raw {
+		switch value.(type) {
+		case string, float64, bool:
+			values[name] = fmt.Sprint(value)
+		default:
+			return nil, fmt.Errorf("failed to parse config file %s: %s must be a string, number or boolean", path, name)
+		}
+	}
+	return values, nil
+}
+
+// setField parses raw into a field of one of the types used by Config
+func setField(field reflect.Value, raw string) error {
+	switch field.Interface().(type) {
+	case string:
+		field.SetString(raw)
+	case bool:
+		b, err := parseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case *bool:
+		b, err := parseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(&b))
+	case int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return errors.New("must be a whole number")
+		}
+		field.SetInt(int64(n))
+	case float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return errors.New("must be a number")
+		}
+		field.SetFloat(f)
+	case []string:
+		var list []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		field.Set(reflect.ValueOf(list))
+	case time.Duration:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return errors.New("must be a duration such as 30s or 10m")
+		}
+		field.SetInt(int64(d))
+	default:
+		return fmt.Errorf("unsupported config field type %s", field.Type())
+	}
+	return nil
+}
+
+// parseBool accepts on/off alongside the values understood by strconv.ParseBool
+func parseBool(raw string) (bool, error) {
+	switch strings.ToLower(raw) {
+	case "on":
+		return true, nil
+	case "off":
+		return false, nil
+	}
+	b, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, errors.New("must be true/false or on/off")
+	}
+	return b, nil
+}
+
+// Validate checks required values and ranges
+func (c Config) Validate() error {
+	var errs []error
+	require := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	require(c.TableName != "", "TABLE_NAME is required")
+	require(c.BucketName != "" || c.LocalStorageDir != "", "BUCKET_NAME is required")
+	require(c.ReportKey != "", "REPORT_KEY must not be empty")
+	switch c.LogLevel {
+	case "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, fmt.Errorf("LOG_LEVEL must be one of debug, info, warn or error, got %q", c.LogLevel))
+	}
+	require(c.ClimateAPIURL == "" || isURL(c.ClimateAPIURL), "CLIMATE_API_URL must be an absolute http(s) URL")
+	require(c.ReportCacheCellDegrees > 0 && c.ReportCacheCellDegrees <= 10, "REPORT_CACHE_CELL_DEGREES must be between 0 and 10")
+	require(c.PlantCacheTTL > 0, "PLANT_CACHE_TTL must be positive")
+	require(c.PlantCacheSize > 0, "PLANT_CACHE_SIZE must be positive")
+	require(!c.Auth || c.APIKeysTable != "", "API_KEYS_TABLE is required when AUTH is on")
+	require(!c.Auth || c.UsageTable != "", "USAGE_TABLE is required when AUTH is on")
+	require(c.JWKSFile == "" || c.Auth, "JWKS_FILE needs AUTH on")
+	require(c.ReportLinkTTL > 0 && c.ReportLinkTTL <= 7*24*time.Hour, "REPORT_LINK_TTL must be between 0 and 168h")
+	if _, err := retention.ParsePolicy(c.ReportRetention); err != nil {
+		errs = append(errs, fmt.Errorf("REPORT_RETENTION: %w", err))
+	}
+	require(c.CleanupGracePeriod >= time.Hour, "CLEANUP_GRACE_PERIOD must be at least 1h")
+	require(c.RateLimitPerMinute >= 0, "RATE_LIMIT_PER_MINUTE must not be negative")
+	require(c.RateLimitPerMinute == 0 || c.RateLimitBurst >= 1, "RATE_LIMIT_BURST must be at least 1")
+	require(c.RateLimitIPPerMinute >= 0, "RATE_LIMIT_IP_PER_MINUTE must not be negative")
+	require(c.RateLimitIPPerMinute == 0 || c.RateLimitIPBurst >= 1, "RATE_LIMIT_IP_BURST must be at least 1")
+	for _, origin := range c.CORSAllowedOrigins {
+		require(origin == "*" || isOrigin(origin), "CORS_ALLOWED_ORIGINS must be * or origins such as https://app.example.com, got %q", origin)
+	}
+	if c.MailFrom != "" {
+		_, err := mailer.ValidateAddress(c.MailFrom)
+		require(err == nil, "MAIL_FROM must be an email address such as reports@example.com")
+	}
+	require(c.PipelineParallelism > 0 && c.PipelineParallelism <= 16, "PIPELINE_PARALLELISM must be between 1 and 16")
+	require(c.BatchWorkers > 0 && c.BatchWorkers <= 64, "BATCH_WORKERS must be between 1 and 64")
+	require(c.SMTPAddr == "" || c.MailFrom != "", "SMTP_ADDR needs MAIL_FROM")
+	if c.SMTPAddr != "" {
+		_, port, err := net.SplitHostPort(c.SMTPAddr)
+		require(err == nil && port != "", "SMTP_ADDR must be host:port, got %q", c.SMTPAddr)
+	}
+	require(c.DynamoDBMaxAttempts >= 1, "DYNAMODB_MAX_ATTEMPTS must be at least 1")
+	require(c.AWSEndpoint == "" || isURL(c.AWSEndpoint), "AWS_ENDPOINT_URL must be an absolute http(s) URL")
+	require(c.AWSMaxRetries >= -1, "AWS_MAX_RETRIES must be -1 (SDK default) or more")
+
+	if len(errs) > 0 {
+		return configError(errs)
+	}
+	return nil
+}
+
+// AWS returns the settings for the shared AWS client factory
+func (c Config) AWS() awsclient.Config {
+	forcePathStyle := c.AWSEndpoint != ""
+	if c.AWSS3ForcePathStyle != nil {
+		forcePathStyle = *c.AWSS3ForcePathStyle
+	}
+	return awsclient.Config{
+		Region:           c.AWSRegion,
+		Endpoint:         c.AWSEndpoint,
+		MaxRetries:       c.AWSMaxRetries,
+		S3ForcePathStyle: forcePathStyle,
+	}
+}
+
+// Redacted returns the effective configuration keyed by variable name for debugging.
+// Secret values and passwords embedded in URLs are replaced.
+func (c Config) Redacted() map[string]string {
+	out := make(map[string]string)
+	v := reflect.ValueOf(c)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		value := v.Field(i)
+		var s string
+		switch {
+		case value.Kind() == reflect.Pointer && value.IsNil():
+			s = ""
+		case value.Kind() == reflect.Pointer:
+			s = fmt.Sprint(value.Elem().Interface())
+		case value.Kind() == reflect.Slice:
+			s = strings.Join(value.Interface().([]string), ",")
+		default:
+			s = fmt.Sprint(value.Interface())
+		}
+		switch {
+		case s == "":
+		case field.Tag.Get("secret") == "true":
+			s = redacted
+		case strings.Contains(s, "://"):
+			s = redactURL(s)
+		}
+		out[field.Tag.Get("env")] = s
+	}
+	return out
+}
+
+// String renders Redacted as sorted NAME=value lines
+func (c Config) String() string {
+	values := c.Redacted()
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s=%s\n", name, values[name])
+	}
+	return b.String()
+}
+
+// redactURL hides the password of a URL with credentials. The marker is added after the URL is
+// built, url.UserPassword would escape its brackets.
+func redactURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil || u.User == nil {
+		return s
+	}
+	if _, ok := u.User.Password(); !ok {
+		return u.String()
+	}
+	u.User = url.User(u.User.Username())
+	scheme, rest, _ := strings.Cut(u.String(), "://")
+	userinfo, host, _ := strings.Cut(rest, "@")
+	return scheme + "://" + userinfo + ":" + redacted + "@" + host
+}
+
+// isOrigin reports whether s is a browser origin: a scheme and host with no path
+func isOrigin(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && isURL(s) && u.Path == "" && u.RawQuery == "" && u.User == nil
+}
+
+func isURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// configError joins every configuration problem into one readable error
+func configError(errs []error) error {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = "  " + err.Error()
+	}
+	return fmt.Errorf("invalid configuration:\n%s", strings.Join(msgs, "\n"))
+}
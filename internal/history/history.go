@@ -0,0 +1,177 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// DefaultLimit is how many reports List returns when no limit is given
+const DefaultLimit = 50
+
+// Record is one report generated for a user
+type Record struct {
+	UserID     string
+	ReportID   string
+	PlantID    string
+	Latitude   float64
+	Longitude  float64
+	StorageKey string
+	// EmailStatus is the outcome of emailing the report, empty when it was not emailed
+	EmailStatus string
+	CreatedAt   time.Time
+	// ExpiresAt is when the report's retention ends, zero keeps it forever. The DynamoDB table
+	// removes expired records through its TTL, which can lag, so readers must check Expired.
+	ExpiresAt time.Time
+}
+
+// Expired reports whether the record's retention has ended at now
+func (r Record) Expired(now time.Time) bool {
+	return !r.ExpiresAt.IsZero() && !now.Before(r.ExpiresAt)
+}
+
+// Store keeps each user's report history
+type Store interface {
+	Add(ctx context.Context, record Record) error
+	// List returns a user's most recent reports first
+	List(ctx context.Context, userID string, limit int) ([]Record, error)
+}
+
+// MemoryStore is an in-memory Store for tests and the local server
+type MemoryStore struct {
+	mu      sync.RWMutex
+	records map[string][]Record
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string][]Record)}
+}
+
+// Add records a report
+func (s *MemoryStore) Add(ctx context.Context, record Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[record.UserID] = append(s.records[record.UserID], record)
+	return nil
+}
+
+// List returns a user's most recent reports first
+func (s *MemoryStore) List(ctx context.Context, userID string, limit int) ([]Record, error) {
+	s.mu.RLock()
+	records := append([]Record(nil), s.records[userID]...)
+	s.mu.RUnlock()
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].CreatedAt.After(records[j].CreatedAt)
+	})
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if len(records) > limit {
+		records = records[:limit]
+	}
+	return records, nil
+}
+
+// DynamoStore keeps history in a DynamoDB table partitioned by UserID and sorted by
+// CreatedReport, the RFC 3339 creation time followed by the report ID, with the expires_at
+// attribute, in Unix seconds, as the table's TTL
+type DynamoStore struct {
+	client    dynamodbiface.DynamoDBAPI
+	tableName string
+}
+
+// NewDynamoStore creates a DynamoStore
+func NewDynamoStore(client dynamodbiface.DynamoDBAPI, tableName string) *DynamoStore {
+	return &DynamoStore{client: client, tableName: tableName}
+}
+
+// Add records a report
+func (s *DynamoStore) Add(ctx context.Context, record Record) error {
+	created := record.CreatedAt.UTC().Format(time.RFC3339Nano)
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String(s.tableName),
+		Item: map[string]*dynamodb.AttributeValue{
+			"UserID":        {S: aws.String(record.UserID)},
+			"CreatedReport": {S: aws.String(created + "#" + record.ReportID)},
+			"report_id":     {S: aws.String(record.ReportID)},
+			"plant_id":      {S: aws.String(record.PlantID)},
+			"latitude":      {N: aws.String(strconv.FormatFloat(record.Latitude, 'f', -1, 64))},
+			"longitude":     {N: aws.String(strconv.FormatFloat(record.Longitude, 'f', -1, 64))},
+			"storage_key":   {S: aws.String(record.StorageKey)},
+			"created_at":    {S: aws.String(created)},
+		},
+	}
+	if record.EmailStatus != "" {
+		input.Item["email_status"] = &dynamodb.AttributeValue{S: aws.String(record.EmailStatus)}
+	}
+	if !record.ExpiresAt.IsZero() {
+		input.Item["expires_at"] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(record.ExpiresAt.Unix(), 10))}
+	}
+	_, err := s.client.PutItemWithContext(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to put history record in DynamoDB: %w", err)
+	}
+	return nil
+}
+
+// List returns a user's most recent reports first
+func (s *DynamoStore) List(ctx context.Context, userID string, limit int) ([]Record, error) {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	result, err := s.client.QueryWithContext(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(s.tableName),
+		KeyConditionExpression: aws.String("UserID = :user"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":user": {S: aws.String(userID)},
+		},
+		ScanIndexForward: aws.Bool(false),
+		Limit:            aws.Int64(int64(limit)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history from DynamoDB: %w", err)
+	}
+
+	records := make([]Record, 0, len(result.Items))
+	for _, item := range result.Items {
+		record := Record{
+			UserID:      userID,
+			ReportID:    stringAttr(item, "report_id"),
+			PlantID:     stringAttr(item, "plant_id"),
+			Latitude:    numberAttr(item, "latitude"),
+			Longitude:   numberAttr(item, "longitude"),
+			StorageKey:  stringAttr(item, "storage_key"),
+			EmailStatus: stringAttr(item, "email_status"),
+		}
+		record.CreatedAt, _ = time.Parse(time.RFC3339Nano, stringAttr(item, "created_at"))
+		if expires := int64(numberAttr(item, "expires_at")); expires > 0 {
+			record.ExpiresAt = time.Unix(expires, 0).UTC()
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func stringAttr(item map[string]*dynamodb.AttributeValue, name string) string {
+	if v, ok := item[name]; ok && v != nil {
+		return aws.StringValue(v.S)
+	}
+	return ""
+}
+
+func numberAttr(item map[string]*dynamodb.AttributeValue, name string) float64 {
+	if v, ok := item[name]; ok && v != nil && v.N != nil {
+		f, _ := strconv.ParseFloat(*v.N, 64)
+		return f
+	}
+	return 0
+}
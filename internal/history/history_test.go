@@ -0,0 +1,107 @@
+package history
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/HealthyTechGuy/plant-report-app/internal/plant-service/mocks"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var created = time.Date(2026, time.March, 3, 9, 30, 0, 0, time.UTC)
+
+func TestMemoryStore_List(t *testing.T) {
+	store := NewMemoryStore()
+	for i, id := range []string{"first", "second", "third"} {
+		require.NoError(t, store.Add(context.TODO(), Record{UserID: "u1", ReportID: id, CreatedAt: created.Add(time.Duration(i) * time.Hour)}))
+	}
+	require.NoError(t, store.Add(context.TODO(), Record{UserID: "u2", ReportID: "other", CreatedAt: created}))
+
+	records, err := store.List(context.TODO(), "u1", 2)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "third", records[0].ReportID)
+	assert.Equal(t, "second", records[1].ReportID)
+
+	records, err = store.List(context.TODO(), "nobody", 0)
+	require.NoError(t, err)
+	assert.Empty(t, records)
+}
+
+func TestDynamoStore_Add(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDynamoDB := mocks.NewMockDynamoDBAPI(ctrl)
+	store := NewDynamoStore(mockDynamoDB, "history")
+
+	mockDynamoDB.EXPECT().PutItemWithContext(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ aws.Context, input *dynamodb.PutItemInput, _ ...interface{}) (*dynamodb.PutItemOutput, error) {
+			assert.Equal(t, "history", aws.StringValue(input.TableName))
+			assert.Equal(t, "u1", aws.StringValue(input.Item["UserID"].S))
+			assert.Equal(t, "2026-03-03T09:30:00Z#r1", aws.StringValue(input.Item["CreatedReport"].S))
+			assert.Equal(t, "51.5", aws.StringValue(input.Item["latitude"].N))
+			assert.Equal(t, "users/u1/r1.pdf", aws.StringValue(input.Item["storage_key"].S))
+			assert.Equal(t, "1775122200", aws.StringValue(input.Item["expires_at"].N))
+			return &dynamodb.PutItemOutput{}, nil
+		})
+
+	err := store.Add(context.TODO(), Record{
+		UserID:     "u1",
+		ReportID:   "r1",
+		PlantID:    "kale",
+		Latitude:   51.5,
+		Longitude:  -0.12,
+		StorageKey: "users/u1/r1.pdf",
+		CreatedAt:  created,
+		ExpiresAt:  created.AddDate(0, 0, 30),
+	})
+	assert.NoError(t, err)
+}
+
+func TestDynamoStore_List(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDynamoDB := mocks.NewMockDynamoDBAPI(ctrl)
+	store := NewDynamoStore(mockDynamoDB, "history")
+
+	mockDynamoDB.EXPECT().QueryWithContext(gomock.Any(), &dynamodb.QueryInput{
+		TableName:              aws.String("history"),
+		KeyConditionExpression: aws.String("UserID = :user"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":user": {S: aws.String("u1")},
+		},
+		ScanIndexForward: aws.Bool(false),
+		Limit:            aws.Int64(DefaultLimit),
+	}).Return(&dynamodb.QueryOutput{Items: []map[string]*dynamodb.AttributeValue{{
+		"UserID":       {S: aws.String("u1")},
+		"report_id":    {S: aws.String("r1")},
+		"plant_id":     {S: aws.String("kale")},
+		"latitude":     {N: aws.String("51.5")},
+		"longitude":    {N: aws.String("-0.12")},
+		"storage_key":  {S: aws.String("users/u1/r1.pdf")},
+		"email_status": {S: aws.String("sent")},
+		"created_at":   {S: aws.String("2026-03-03T09:30:00Z")},
+		"expires_at":   {N: aws.String("1775122200")},
+	}}}, nil)
+
+	records, err := store.List(context.TODO(), "u1", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []Record{{
+		UserID:      "u1",
+		ReportID:    "r1",
+		PlantID:     "kale",
+		Latitude:    51.5,
+		Longitude:   -0.12,
+		StorageKey:  "users/u1/r1.pdf",
+		EmailStatus: "sent",
+		CreatedAt:   created,
+		ExpiresAt:   created.AddDate(0, 0, 30),
+	}}, records)
+}
@@ -0,0 +1,52 @@
+// Package pipeline runs the independent steps of report generation concurrently with bounded
+// parallelism. As with errgroup, the first step to fail cancels the context of the others.
+package pipeline
+
+import (
+	"context"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Step is one unit of work, it should stop early once ctx is done
+type Step func(ctx context.Context) error
+
+// Run runs steps with at most limit running at once, or all at once when limit is 0, and returns
+// the first error. Steps start in order, so with a limit of 1 they run one after another.
+func Run(ctx context.Context, limit int, steps ...Step) error {
+	g, ctx := group(ctx, limit)
+	for _, step := range steps {
+		g.Go(func() error { return step(ctx) })
+	}
+	return g.Wait()
+}
+
+// Map calls fn for every item with at most limit calls running at once, or all at once when limit
+// is 0, and returns the results in item order. The first error cancels the remaining calls.
+func Map[T, R any](ctx context.Context, limit int, items []T, fn func(ctx context.Context, item T) (R, error)) ([]R, error) {
+	results := make([]R, len(items))
+	g, ctx := group(ctx, limit)
+	for i, item := range items {
+		g.Go(func() error {
+			result, err := fn(ctx, item)
+			if err != nil {
+				return err
+			}
+			results[i] = result
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// group returns an errgroup limited to limit goroutines
+func group(ctx context.Context, limit int) (*errgroup.Group, context.Context) {
+	g, ctx := errgroup.WithContext(ctx)
+	if limit > 0 {
+		g.SetLimit(limit)
+	}
+	return g, ctx
+}
@@ -0,0 +1,104 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun_CancelsOnFirstError(t *testing.T) {
+	failed := errors.New("lookup failed")
+	var cancelled atomic.Bool
+	err := Run(context.Background(), 0,
+		func(ctx context.Context) error { return failed },
+		func(ctx context.Context) error {
+			<-ctx.Done()
+			cancelled.Store(true)
+			return nil
+		},
+	)
+	assert.ErrorIs(t, err, failed)
+	assert.True(t, cancelled.Load())
+}
+
+func TestRun_LimitOfOneRunsInOrder(t *testing.T) {
+	var order []int
+	var steps []Step
+	for i := 0; i < 5; i++ {
+		steps = append(steps, func(ctx context.Context) error {
+			order = append(order, i)
+			return nil
+		})
+	}
+	require.NoError(t, Run(context.Background(), 1, steps...))
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, order)
+}
+
+func TestMap_BoundsParallelismAndKeepsOrder(t *testing.T) {
+	var running, peak atomic.Int32
+	items := []int{1, 2, 3, 4, 5, 6, 7, 8}
+	results, err := Map(context.Background(), 3, items, func(ctx context.Context, n int) (int, error) {
+		now := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if now <= p || peak.CompareAndSwap(p, now) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		return n * n, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 4, 9, 16, 25, 36, 49, 64}, results)
+	assert.LessOrEqual(t, peak.Load(), int32(3))
+}
+
+func TestMap_ReturnsFirstError(t *testing.T) {
+	failed := errors.New("bad item")
+	results, err := Map(context.Background(), 2, []int{1, 2, 3}, func(ctx context.Context, n int) (int, error) {
+		if n == 2 {
+			return 0, failed
+		}
+		return n, nil
+	})
+	assert.ErrorIs(t, err, failed)
+	assert.Nil(t, results)
+}
+
+// slowSteps returns n steps that each wait d, like independent network lookups
+func slowSteps(n int, d time.Duration) []Step {
+	steps := make([]Step, n)
+	for i := range steps {
+		steps[i] = func(ctx context.Context) error {
+			select {
+			case <-time.After(d):
+			case <-ctx.Done():
+			}
+			return nil
+		}
+	}
+	return steps
+}
+
+// BenchmarkRun compares four 2ms lookups run one after another with the same lookups run together
+func BenchmarkRun(b *testing.B) {
+	steps := slowSteps(4, 2*time.Millisecond)
+	for _, bc := range []struct {
+		name  string
+		limit int
+	}{{"sequential", 1}, {"concurrent", 4}} {
+		b.Run(bc.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if err := Run(context.Background(), bc.limit, steps...); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
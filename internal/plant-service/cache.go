@@ -0,0 +1,198 @@
+package plantservice
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/HealthyTechGuy/plant-report-app/models"
+	"golang.org/x/sync/singleflight"
+)
+
+// Default cache settings, used when a CacheOptions field is left at zero
+const (
+	DefaultCacheTTL         = 10 * time.Minute
+	DefaultCacheNegativeTTL = time.Minute
+	DefaultCacheMaxEntries  = 500
+)
+
+// listKey is the singleflight key for catalog listings, chosen so it can't clash with a plant ID
+const listKey = "\x00list"
+
+// CacheOptions configures a CachedPlantService
+type CacheOptions struct {
+	// TTL is how long a plant is served from memory before being re-read
+	TTL time.Duration
+	// NegativeTTL is how long an ErrPlantNotFound result is remembered
+	NegativeTTL time.Duration
+	// MaxEntries bounds the number of cached plants, evicting the least recently used
+	MaxEntries int
+	// Now returns the current time, overridable in tests
+	Now func() time.Time
+}
+
+// CacheStats holds counters describing how well the cache is doing
+type CacheStats struct {
+	Hits         uint64 `json:"hits"`
+	NegativeHits uint64 `json:"negative_hits"`
+	Misses       uint64 `json:"misses"`
+	Evictions    uint64 `json:"evictions"`
+	Entries      int    `json:"entries"`
+}
+
+// cacheEntry is a cached lookup result, either a plant or ErrPlantNotFound
+type cacheEntry struct {
+	plantID   string
+	plantInfo models.PlantInfo
+	notFound  bool
+	expires   time.Time
+}
+
+// CachedPlantService is a PlantServiceInterface decorator that keeps recent lookups in memory,
+// so warm Lambdas skip repeated DynamoDB round trips. Concurrent lookups of the same plant share one call.
+type CachedPlantService struct {
+	next PlantServiceInterface
+	opts CacheOptions
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	catalog []models.PlantInfo
+	listed  time.Time
+
+	group singleflight.Group
+
+	hits, negativeHits, misses, evictions atomic.Uint64
+}
+
+// NewCachedPlantService wraps next with an in-memory cache
+func NewCachedPlantService(next PlantServiceInterface, opts CacheOptions) *CachedPlantService {
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultCacheTTL
+	}
+	if opts.NegativeTTL <= 0 {
+		opts.NegativeTTL = DefaultCacheNegativeTTL
+	}
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = DefaultCacheMaxEntries
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	return &CachedPlantService{
+		next:    next,
+		opts:    opts,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// GetPlantInfo returns the plant from memory when fresh, otherwise reads it through the wrapped service.
+// Concurrent misses share one read, made with the context of the caller that started it.
+func (c *CachedPlantService) GetPlantInfo(ctx context.Context, plantID string) (models.PlantInfo, error) {
+	if entry, ok := c.lookup(plantID); ok {
+		if entry.notFound {
+			c.negativeHits.Add(1)
+			return models.PlantInfo{}, ErrPlantNotFound
+		}
+		c.hits.Add(1)
+		return entry.plantInfo, nil
+	}
+	c.misses.Add(1)
+
+	v, err, _ := c.group.Do(plantID, func() (interface{}, error) {
+		plantInfo, err := c.next.GetPlantInfo(ctx, plantID)
+		switch {
+		case err == nil:
+			c.store(cacheEntry{plantID: plantID, plantInfo: plantInfo, expires: c.opts.Now().Add(c.opts.TTL)})
+		case errors.Is(err, ErrPlantNotFound):
+			c.store(cacheEntry{plantID: plantID, notFound: true, expires: c.opts.Now().Add(c.opts.NegativeTTL)})
+		}
+		return plantInfo, err
+	})
+	if err != nil {
+		return models.PlantInfo{}, err
+	}
+	return v.(models.PlantInfo), nil
+}
+
+// ListPlants returns the catalog from memory when fresh, otherwise lists it through the wrapped service
+func (c *CachedPlantService) ListPlants(ctx context.Context) ([]models.PlantInfo, error) {
+	c.mu.Lock()
+	if c.catalog != nil && c.opts.Now().Before(c.listed.Add(c.opts.TTL)) {
+		catalog := c.catalog
+		c.mu.Unlock()
+		c.hits.Add(1)
+		return catalog, nil
+	}
+	c.mu.Unlock()
+	c.misses.Add(1)
+
+	v, err, _ := c.group.Do(listKey, func() (interface{}, error) {
+		catalog, err := c.next.ListPlants(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.mu.Lock()
+		c.catalog, c.listed = catalog, c.opts.Now()
+		c.mu.Unlock()
+		return catalog, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]models.PlantInfo), nil
+}
+
+// Stats returns a snapshot of the cache counters
+func (c *CachedPlantService) Stats() CacheStats {
+	c.mu.Lock()
+	entries := c.lru.Len()
+	c.mu.Unlock()
+	return CacheStats{
+		Hits:         c.hits.Load(),
+		NegativeHits: c.negativeHits.Load(),
+		Misses:       c.misses.Load(),
+		Evictions:    c.evictions.Load(),
+		Entries:      entries,
+	}
+}
+
+// lookup returns an unexpired entry and marks it as recently used
+func (c *CachedPlantService) lookup(plantID string) (cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[plantID]
+	if !ok {
+		return cacheEntry{}, false
+	}
+	entry := el.Value.(cacheEntry)
+	if !c.opts.Now().Before(entry.expires) {
+		c.lru.Remove(el)
+		delete(c.entries, plantID)
+		return cacheEntry{}, false
+	}
+	c.lru.MoveToFront(el)
+	return entry, true
+}
+
+// store adds or replaces an entry, evicting the least recently used entries beyond MaxEntries
+func (c *CachedPlantService) store(entry cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[entry.plantID]; ok {
+		el.Value = entry
+		c.lru.MoveToFront(el)
+		return
+	}
+	c.entries[entry.plantID] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.opts.MaxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(cacheEntry).plantID)
+		c.evictions.Add(1)
+	}
+}
@@ -0,0 +1,143 @@
+package plantservice
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/HealthyTechGuy/plant-report-app/internal/plant-service/mocks"
+	"github.com/HealthyTechGuy/plant-report-app/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a manually advanced clock for TTL tests
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time          { return c.now }
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func TestCachedPlantService_HitsWithinTTL(t *testing.T) {
+	mockPlantService := new(mocks.MockPlantService)
+	kale := models.PlantInfo{ID: "kale", Name: "Kale"}
+	mockPlantService.On("GetPlantInfo", mock.Anything, "kale").Return(kale, nil)
+
+	clock := &fakeClock{now: time.Now()}
+	cache := NewCachedPlantService(mockPlantService, CacheOptions{TTL: time.Minute, Now: clock.Now})
+
+	for i := 0; i < 3; i++ {
+		plantInfo, err := cache.GetPlantInfo(context.TODO(), "kale")
+		require.NoError(t, err)
+		assert.Equal(t, kale, plantInfo)
+	}
+	mockPlantService.AssertNumberOfCalls(t, "GetPlantInfo", 1)
+
+	// Once the TTL passes the plant is read again
+	clock.Advance(time.Minute)
+	_, err := cache.GetPlantInfo(context.TODO(), "kale")
+	require.NoError(t, err)
+	mockPlantService.AssertNumberOfCalls(t, "GetPlantInfo", 2)
+
+	assert.Equal(t, CacheStats{Hits: 2, Misses: 2, Entries: 1}, cache.Stats())
+}
+
+func TestCachedPlantService_NegativeCaching(t *testing.T) {
+	mockPlantService := new(mocks.MockPlantService)
+	mockPlantService.On("GetPlantInfo", mock.Anything, "unknown").Return(models.PlantInfo{}, ErrPlantNotFound)
+
+	clock := &fakeClock{now: time.Now()}
+	cache := NewCachedPlantService(mockPlantService, CacheOptions{NegativeTTL: 30 * time.Second, Now: clock.Now})
+
+	_, err := cache.GetPlantInfo(context.TODO(), "unknown")
+	assert.Equal(t, ErrPlantNotFound, err)
+	_, err = cache.GetPlantInfo(context.TODO(), "unknown")
+	assert.Equal(t, ErrPlantNotFound, err)
+	mockPlantService.AssertNumberOfCalls(t, "GetPlantInfo", 1)
+	assert.Equal(t, uint64(1), cache.Stats().NegativeHits)
+
+	clock.Advance(30 * time.Second)
+	_, err = cache.GetPlantInfo(context.TODO(), "unknown")
+	assert.Equal(t, ErrPlantNotFound, err)
+	mockPlantService.AssertNumberOfCalls(t, "GetPlantInfo", 2)
+}
+
+func TestCachedPlantService_DoesNotCacheFailures(t *testing.T) {
+	mockPlantService := new(mocks.MockPlantService)
+	mockPlantService.On("GetPlantInfo", mock.Anything, "kale").Return(models.PlantInfo{}, errors.New("dynamo error"))
+
+	cache := NewCachedPlantService(mockPlantService, CacheOptions{})
+	_, err := cache.GetPlantInfo(context.TODO(), "kale")
+	assert.Error(t, err)
+	_, err = cache.GetPlantInfo(context.TODO(), "kale")
+	assert.Error(t, err)
+	mockPlantService.AssertNumberOfCalls(t, "GetPlantInfo", 2)
+	assert.Zero(t, cache.Stats().Entries)
+}
+
+func TestCachedPlantService_EvictsLeastRecentlyUsed(t *testing.T) {
+	mockPlantService := new(mocks.MockPlantService)
+	for _, id := range []string{"a", "b", "c"} {
+		mockPlantService.On("GetPlantInfo", mock.Anything, id).Return(models.PlantInfo{ID: id}, nil)
+	}
+	cache := NewCachedPlantService(mockPlantService, CacheOptions{MaxEntries: 2})
+
+	for _, id := range []string{"a", "b", "a", "c"} {
+		_, err := cache.GetPlantInfo(context.TODO(), id)
+		require.NoError(t, err)
+	}
+
+	// "b" was least recently used when "c" arrived
+	_, err := cache.GetPlantInfo(context.TODO(), "a")
+	require.NoError(t, err)
+	_, err = cache.GetPlantInfo(context.TODO(), "b")
+	require.NoError(t, err)
+	mockPlantService.AssertNumberOfCalls(t, "GetPlantInfo", 4)
+
+	stats := cache.Stats()
+	assert.Equal(t, 2, stats.Entries)
+	assert.Equal(t, uint64(2), stats.Evictions)
+}
+
+// slowPlantService blocks lookups until released, counting how many reach it
+type slowPlantService struct {
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (s *slowPlantService) GetPlantInfo(_ context.Context, plantID string) (models.PlantInfo, error) {
+	s.calls.Add(1)
+	<-s.release
+	return models.PlantInfo{ID: plantID}, nil
+}
+
+func (s *slowPlantService) ListPlants(context.Context) ([]models.PlantInfo, error) {
+	return nil, nil
+}
+
+func TestCachedPlantService_DeduplicatesConcurrentLookups(t *testing.T) {
+	slow := &slowPlantService{release: make(chan struct{})}
+	cache := NewCachedPlantService(slow, CacheOptions{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			plantInfo, err := cache.GetPlantInfo(context.TODO(), "kale")
+			assert.NoError(t, err)
+			assert.Equal(t, "kale", plantInfo.ID)
+		}()
+	}
+
+	// Wait for the first lookup to reach the slow service, then give the rest time to queue behind it
+	require.Eventually(t, func() bool { return slow.calls.Load() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	close(slow.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), slow.calls.Load())
+}
@@ -0,0 +1,22 @@
+package plantservice
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/HealthyTechGuy/plant-report-app/pkg/companion"
+)
+
+// CompanionGraph builds the companion planting graph from the relationships recorded on every
+// plant in the catalog
+func CompanionGraph(ctx context.Context, catalog PlantServiceInterface) (*companion.Graph, error) {
+	plants, err := catalog.ListPlants(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list plants for companion planting: %w", err)
+	}
+	nodes := make([]companion.Plant, len(plants))
+	for i, p := range plants {
+		nodes[i] = companion.Plant{ID: p.ID, Name: p.Name, Links: p.Companions}
+	}
+	return companion.NewGraph(nodes), nil
+}
@@ -0,0 +1,18 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/HealthyTechGuy/plant-report-app/pkg/climate"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockClimateProvider struct {
+	mock.Mock
+}
+
+// Normals is a mock implementation for looking up climate normals
+func (m *MockClimateProvider) Normals(ctx context.Context, latitude, longitude float64) (climate.Normals, error) {
+	args := m.Called(latitude, longitude)
+	return args.Get(0).(climate.Normals), args.Error(1)
+}
@@ -2,6 +2,8 @@
 package mocks
 
 import (
+	"context"
+
 	"github.com/HealthyTechGuy/plant-report-app/models"
 	"github.com/stretchr/testify/mock"
 )
@@ -10,7 +12,13 @@ type MockPlantService struct {
 	mock.Mock
 }
 
-func (m *MockPlantService) GetPlantInfo(plantID string) (models.PlantInfo, error) {
-	args := m.Called(plantID)
+func (m *MockPlantService) GetPlantInfo(ctx context.Context, plantID string) (models.PlantInfo, error) {
+	args := m.Called(ctx, plantID)
 	return args.Get(0).(models.PlantInfo), args.Error(1)
 }
+
+func (m *MockPlantService) ListPlants(ctx context.Context) ([]models.PlantInfo, error) {
+	args := m.Called(ctx)
+	plants, _ := args.Get(0).([]models.PlantInfo)
+	return plants, args.Error(1)
+}
@@ -1,12 +1,18 @@
 package plantservice
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
 
 	"github.com/HealthyTechGuy/plant-report-app/models"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/companion"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/frost"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/units"
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 )
@@ -18,35 +24,83 @@ var (
 
 // PlantServiceInterface defines the methods for interacting with plant data
 type PlantServiceInterface interface {
-	GetPlantInfo(plantID string) (models.PlantInfo, error)
+	GetPlantInfo(ctx context.Context, plantID string) (models.PlantInfo, error)
+	ListPlants(ctx context.Context) ([]models.PlantInfo, error)
 }
 
 // PlantService is a concrete implementation of PlantServiceInterface
 type PlantService struct {
 	dynamoDBClient dynamodbiface.DynamoDBAPI
 	tableName      string
+	consistentRead bool
+	retry          RetryPolicy
+	breaker        *circuitBreaker
+	sleep          func(ctx context.Context, d time.Duration) error
+	random         func() float64
 }
 
-// NewPlantService creates a new PlantService
-func NewPlantService(tableName string) *PlantService {
-	sess := session.Must(session.NewSession())
-	return &PlantService{
-		dynamoDBClient: dynamodb.New(sess),
+// Option configures optional PlantService behaviour
+type Option func(*PlantService)
+
+// WithConsistentRead makes reads strongly consistent instead of eventually consistent
+func WithConsistentRead(consistent bool) Option {
+	return func(s *PlantService) {
+		s.consistentRead = consistent
+	}
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(s *PlantService) {
+		s.retry = policy
+	}
+}
+
+// WithCircuitBreaker replaces DefaultCircuitBreakerOptions
+func WithCircuitBreaker(opts CircuitBreakerOptions) Option {
+	return func(s *PlantService) {
+		s.breaker = &circuitBreaker{opts: opts, now: time.Now}
+	}
+}
+
+// NewPlantService creates a new PlantService using a shared DynamoDB client.
+// Retries are handled by PlantService, so the client should be built with SDK retries disabled
+// to avoid compounding them, e.g. factory.DynamoDB(aws.NewConfig().WithMaxRetries(0)).
+func NewPlantService(client dynamodbiface.DynamoDBAPI, tableName string, opts ...Option) *PlantService {
+	s := &PlantService{
+		dynamoDBClient: client,
 		tableName:      tableName,
+		retry:          DefaultRetryPolicy,
+		breaker:        &circuitBreaker{opts: DefaultCircuitBreakerOptions, now: time.Now},
+		sleep:          sleepContext,
+		random:         rand.Float64,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 // GetPlantInfo retrieves plant information from DynamoDB
-func (s *PlantService) GetPlantInfo(plantID string) (pi models.PlantInfo, err error) {
-	result, err := s.dynamoDBClient.GetItem(&dynamodb.GetItemInput{
-		TableName: aws.String(s.tableName),
-		Key: map[string]*dynamodb.AttributeValue{
-			"PlantID": {
-				S: aws.String(plantID),
+func (s *PlantService) GetPlantInfo(ctx context.Context, plantID string) (pi models.PlantInfo, err error) {
+	var result *dynamodb.GetItemOutput
+	err = s.call(ctx, func() error {
+		var err error
+		result, err = s.dynamoDBClient.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+			TableName: aws.String(s.tableName),
+			Key: map[string]*dynamodb.AttributeValue{
+				"PlantID": {
+					S: aws.String(plantID),
+				},
 			},
-		},
+			ConsistentRead: aws.Bool(s.consistentRead),
+		})
+		return err
 	})
 
+	if errors.Is(err, ErrServiceUnavailable) || (err != nil && err == ctx.Err()) {
+		return pi, err
+	}
 	if err != nil {
 		return pi, fmt.Errorf("failed to get item from DynamoDB: %w", err)
 	}
@@ -55,13 +109,111 @@ func (s *PlantService) GetPlantInfo(plantID string) (pi models.PlantInfo, err er
 		return pi, ErrPlantNotFound
 	}
 
-	plantInfo := models.PlantInfo{
-		ID:              *result.Item["PlantID"].S,
-		Name:            *result.Item["name"].S,
-		GrowingPeriod:   *result.Item["growing_period"].S,
-		OptimalPlanting: *result.Item["optimal_planting"].S,
-		HardinessZone:   *result.Item["hardiness_zone"].S,
+	return plantFromItem(result.Item), nil
+}
+
+// ListPlants returns every plant in the catalog
+func (s *PlantService) ListPlants(ctx context.Context) ([]models.PlantInfo, error) {
+	var plants []models.PlantInfo
+	err := s.call(ctx, func() error {
+		// A retry restarts the scan from the beginning
+		plants = nil
+		return s.dynamoDBClient.ScanPagesWithContext(ctx, &dynamodb.ScanInput{
+			TableName:      aws.String(s.tableName),
+			ConsistentRead: aws.Bool(s.consistentRead),
+		}, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+			for _, item := range page.Items {
+				plants = append(plants, plantFromItem(item))
+			}
+			return true
+		})
+	})
+	if errors.Is(err, ErrServiceUnavailable) || (err != nil && err == ctx.Err()) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan plants from DynamoDB: %w", err)
 	}
+	return plants, nil
+}
 
-	return plantInfo, nil
+// plantFromItem converts a DynamoDB item into PlantInfo
+func plantFromItem(item map[string]*dynamodb.AttributeValue) models.PlantInfo {
+	return models.PlantInfo{
+		ID:              stringAttr(item, "PlantID"),
+		Name:            stringAttr(item, "name"),
+		GrowingPeriod:   stringAttr(item, "growing_period"),
+		OptimalPlanting: stringAttr(item, "optimal_planting"),
+		HardinessZone:   stringAttr(item, "hardiness_zone"),
+		Spacing:         units.Length(numberAttr(item, "spacing_cm")) * units.Centimetre,
+		PlantingDepth:   units.Length(numberAttr(item, "planting_depth_cm")) * units.Centimetre,
+		MinTemperature:  units.Celsius(numberAttr(item, "min_temp_c")),
+		MaxTemperature:  units.Celsius(numberAttr(item, "max_temp_c")),
+		AnnualRainfall:  units.Length(numberAttr(item, "annual_rainfall_mm")) * units.Millimetre,
+		DaysToMaturity:  int(numberAttr(item, "days_to_maturity")),
+		FrostTolerance:  frost.ParseTolerance(stringAttr(item, "frost_tolerance")),
+		ChillHours:      int(numberAttr(item, "chill_hours")),
+		Category:        stringAttr(item, "category"),
+		Uses:            stringSetAttr(item, "uses"),
+		Companions:      companionsAttr(item, "companions"),
+	}
+}
+
+// stringAttr reads an optional string attribute, returning "" when it is missing
+func stringAttr(item map[string]*dynamodb.AttributeValue, name string) string {
+	attr, ok := item[name]
+	if !ok || attr == nil || attr.S == nil {
+		return ""
+	}
+	return *attr.S
+}
+
+// stringSetAttr reads an optional string set or list of strings, returning nil when it is missing
+func stringSetAttr(item map[string]*dynamodb.AttributeValue, name string) []string {
+	attr, ok := item[name]
+	if !ok || attr == nil {
+		return nil
+	}
+	values := aws.StringValueSlice(attr.SS)
+	for _, v := range attr.L {
+		if v != nil && v.S != nil {
+			values = append(values, *v.S)
+		}
+	}
+	return values
+}
+
+// companionsAttr reads an optional list of {plant_id, relation, reason} maps, skipping entries
+// without a plant ID or with a relation other than good or bad
+func companionsAttr(item map[string]*dynamodb.AttributeValue, name string) []companion.Link {
+	attr, ok := item[name]
+	if !ok || attr == nil {
+		return nil
+	}
+	var links []companion.Link
+	for _, v := range attr.L {
+		if v == nil {
+			continue
+		}
+		relation, ok := companion.ParseRelation(stringAttr(v.M, "relation"))
+		plantID := stringAttr(v.M, "plant_id")
+		if !ok || plantID == "" {
+			continue
+		}
+		links = append(links, companion.Link{PlantID: plantID, Relation: relation, Reason: stringAttr(v.M, "reason")})
+	}
+	return links
+}
+
+// numberAttr reads an optional numeric attribute, returning 0 when it is missing or malformed
+func numberAttr(item map[string]*dynamodb.AttributeValue, name string) float64 {
+	attr, ok := item[name]
+	if !ok || attr == nil || attr.N == nil {
+		return 0
+	}
+	v, err := strconv.ParseFloat(*attr.N, 64)
+	if err != nil {
+		return 0
+	}
+	return v
 }
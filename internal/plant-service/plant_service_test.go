@@ -1,15 +1,23 @@
 package plantservice
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/HealthyTechGuy/plant-report-app/internal/plant-service/mocks"
 	"github.com/HealthyTechGuy/plant-report-app/models"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/companion"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/frost"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/suitability"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -29,9 +37,10 @@ func TestGetPlantInfo_Success(t *testing.T) {
 		GrowingPeriod:   "May to August",
 		OptimalPlanting: "Spring",
 		HardinessZone:   "3-7",
+		FrostTolerance:  frost.Tender,
 	}
 
-	mockDynamoDB.EXPECT().GetItem(gomock.Any()).Return(&dynamodb.GetItemOutput{
+	mockDynamoDB.EXPECT().GetItemWithContext(gomock.Any(), gomock.Any()).Return(&dynamodb.GetItemOutput{
 		Item: map[string]*dynamodb.AttributeValue{
 			"PlantID":          {S: aws.String(expectedPlantInfo.ID)},
 			"name":             {S: aws.String(expectedPlantInfo.Name)},
@@ -41,11 +50,87 @@ func TestGetPlantInfo_Success(t *testing.T) {
 		},
 	}, nil)
 
-	plantInfo, err := plantService.GetPlantInfo("1")
+	plantInfo, err := plantService.GetPlantInfo(context.TODO(), "1")
 	require.NoError(t, err)
 	assert.Equal(t, expectedPlantInfo, plantInfo)
 }
 
+func TestGetPlantInfo_Measurements(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDynamoDB := mocks.NewMockDynamoDBAPI(ctrl)
+	plantService := &PlantService{
+		dynamoDBClient: mockDynamoDB,
+		tableName:      "test-table",
+	}
+
+	mockDynamoDB.EXPECT().GetItemWithContext(gomock.Any(), gomock.Any()).Return(&dynamodb.GetItemOutput{
+		Item: map[string]*dynamodb.AttributeValue{
+			"PlantID":            {S: aws.String("kale")},
+			"name":               {S: aws.String("Kale")},
+			"growing_period":     {S: aws.String("55-75 days")},
+			"optimal_planting":   {S: aws.String("Spring")},
+			"hardiness_zone":     {S: aws.String("7-9")},
+			"spacing_cm":         {N: aws.String("45")},
+			"planting_depth_cm":  {N: aws.String("1.5")},
+			"min_temp_c":         {N: aws.String("-10")},
+			"max_temp_c":         {N: aws.String("27")},
+			"annual_rainfall_mm": {N: aws.String("not-a-number")},
+			"days_to_maturity":   {N: aws.String("65")},
+			"frost_tolerance":    {S: aws.String("hardy")},
+		},
+	}, nil)
+
+	plantInfo, err := plantService.GetPlantInfo(context.TODO(), "kale")
+	require.NoError(t, err)
+	assert.InDelta(t, 45, plantInfo.Spacing.Centimetres(), 1e-9)
+	assert.InDelta(t, 1.5, plantInfo.PlantingDepth.Centimetres(), 1e-9)
+	assert.InDelta(t, -10, plantInfo.MinTemperature.Celsius(), 1e-9)
+	assert.InDelta(t, 27, plantInfo.MaxTemperature.Celsius(), 1e-9)
+	assert.Zero(t, plantInfo.AnnualRainfall)
+	assert.Equal(t, 65, plantInfo.DaysToMaturity)
+	assert.Equal(t, frost.Hardy, plantInfo.FrostTolerance)
+}
+
+func TestGetPlantInfo_Companions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDynamoDB := mocks.NewMockDynamoDBAPI(ctrl)
+	plantService := &PlantService{
+		dynamoDBClient: mockDynamoDB,
+		tableName:      "test-table",
+	}
+
+	link := func(plantID, relation, reason string) *dynamodb.AttributeValue {
+		return &dynamodb.AttributeValue{M: map[string]*dynamodb.AttributeValue{
+			"plant_id": {S: aws.String(plantID)},
+			"relation": {S: aws.String(relation)},
+			"reason":   {S: aws.String(reason)},
+		}}
+	}
+	mockDynamoDB.EXPECT().GetItemWithContext(gomock.Any(), gomock.Any()).Return(&dynamodb.GetItemOutput{
+		Item: map[string]*dynamodb.AttributeValue{
+			"PlantID": {S: aws.String("tomato")},
+			"name":    {S: aws.String("Tomato")},
+			"companions": {L: []*dynamodb.AttributeValue{
+				link("basil", "Good", "Repels whitefly"),
+				link("fennel", "bad", "Stunts growth"),
+				link("", "good", "No plant"),
+				link("marigold", "maybe", "Unknown relation"),
+			}},
+		},
+	}, nil)
+
+	plantInfo, err := plantService.GetPlantInfo(context.TODO(), "tomato")
+	require.NoError(t, err)
+	assert.Equal(t, []companion.Link{
+		{PlantID: "basil", Relation: companion.Good, Reason: "Repels whitefly"},
+		{PlantID: "fennel", Relation: companion.Bad, Reason: "Stunts growth"},
+	}, plantInfo.Companions)
+}
+
 func TestGetPlantInfo_NotFound(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -56,11 +141,11 @@ func TestGetPlantInfo_NotFound(t *testing.T) {
 		tableName:      "test-table",
 	}
 
-	mockDynamoDB.EXPECT().GetItem(gomock.Any()).Return(&dynamodb.GetItemOutput{
+	mockDynamoDB.EXPECT().GetItemWithContext(gomock.Any(), gomock.Any()).Return(&dynamodb.GetItemOutput{
 		Item: nil,
 	}, nil)
 
-	_, err := plantService.GetPlantInfo("unknown-id")
+	_, err := plantService.GetPlantInfo(context.TODO(), "unknown-id")
 	assert.Equal(t, ErrPlantNotFound, err)
 }
 
@@ -74,45 +159,287 @@ func TestGetPlantInfo_DynamoDBError(t *testing.T) {
 		tableName:      "test-table",
 	}
 
-	mockDynamoDB.EXPECT().GetItem(gomock.Any()).Return(nil, errors.New("dynamo error"))
+	mockDynamoDB.EXPECT().GetItemWithContext(gomock.Any(), gomock.Any()).Return(nil, errors.New("dynamo error"))
 
-	_, err := plantService.GetPlantInfo("1")
+	_, err := plantService.GetPlantInfo(context.TODO(), "1")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to get item from DynamoDB")
 }
 
-func TestPDFGenerationAndUpload(t *testing.T) {
+func TestListPlants(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockPDFGenerator := new(mocks.MockPDFGenerator)
+	mockDynamoDB := mocks.NewMockDynamoDBAPI(ctrl)
+	plantService := &PlantService{
+		dynamoDBClient: mockDynamoDB,
+		tableName:      "test-table",
+	}
 
-	expectedPlantInfo := models.PlantInfo{
-		ID:              "1",
-		Name:            "Blueberry Bush",
-		GrowingPeriod:   "May to August",
-		OptimalPlanting: "Spring",
-		HardinessZone:   "3-7",
+	pages := []*dynamodb.ScanOutput{
+		{Items: []map[string]*dynamodb.AttributeValue{
+			{"PlantID": {S: aws.String("kale")}, "name": {S: aws.String("Kale")}, "category": {S: aws.String("Vegetable")}},
+		}},
+		{Items: []map[string]*dynamodb.AttributeValue{
+			{"PlantID": {S: aws.String("apple")}, "name": {S: aws.String("Apple Tree")}, "uses": {SS: aws.StringSlice([]string{"Fruit", "Cider"})}},
+		}},
+	}
+	mockDynamoDB.EXPECT().ScanPagesWithContext(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ aws.Context, input *dynamodb.ScanInput, fn func(*dynamodb.ScanOutput, bool) bool, _ ...request.Option) error {
+			assert.Equal(t, "test-table", *input.TableName)
+			for i, page := range pages {
+				if !fn(page, i == len(pages)-1) {
+					break
+				}
+			}
+			return nil
+		})
+
+	plants, err := plantService.ListPlants(context.TODO())
+	require.NoError(t, err)
+	require.Len(t, plants, 2)
+	assert.Equal(t, "Vegetable", plants[0].Category)
+	assert.Equal(t, []string{"Fruit", "Cider"}, plants[1].Uses)
+}
+
+func TestListPlants_DynamoDBError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDynamoDB := mocks.NewMockDynamoDBAPI(ctrl)
+	plantService := &PlantService{
+		dynamoDBClient: mockDynamoDB,
+		tableName:      "test-table",
 	}
 
-	userLocation := models.UserLocation{
-		// Fill in any necessary fields for UserLocation
+	mockDynamoDB.EXPECT().ScanPagesWithContext(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("dynamo error"))
+
+	_, err := plantService.ListPlants(context.TODO())
+	assert.ErrorContains(t, err, "failed to scan plants from DynamoDB")
+}
+
+func TestRecommendAlternatives(t *testing.T) {
+	mockPlantService := new(mocks.MockPlantService)
+
+	blueberry := models.PlantInfo{ID: "blueberry", Name: "Blueberry Bush", Category: "Shrub", Uses: []string{"Fruit"}}
+	catalog := []models.PlantInfo{
+		blueberry,
+		{ID: "raspberry", Name: "Raspberry", Category: "Cane", Uses: []string{"fruit"}},
+		{ID: "currant", Name: "Currant", Category: "Shrub"},
+		{ID: "lavender", Name: "Lavender", Category: "Shrub"},
+		{ID: "strawberry", Name: "Strawberry", Category: "Perennial", Uses: []string{"Fruit"}},
+		{ID: "kale", Name: "Kale", Category: "Vegetable", Uses: []string{"Leaves"}},
+	}
+	mockPlantService.On("ListPlants", mock.Anything).Return(catalog, nil)
+
+	scores := map[string]int{"raspberry": 80, "currant": 90, "lavender": 30, "strawberry": 80, "kale": 100}
+	score := func(p models.PlantInfo) suitability.Assessment {
+		verdict := suitability.Yes
+		if scores[p.ID] < 70 {
+			verdict = suitability.No
+		}
+		return suitability.Assessment{Score: scores[p.ID], Verdict: verdict}
+	}
+
+	alternatives, err := RecommendAlternatives(context.TODO(), mockPlantService, blueberry, score, 2)
+	require.NoError(t, err)
+
+	// Kale grows well but isn't similar, lavender is similar but a poor fit
+	require.Len(t, alternatives, 2)
+	assert.Equal(t, "currant", alternatives[0].PlantID)
+	assert.Equal(t, "Also a shrub", alternatives[0].Reason)
+	assert.Equal(t, "raspberry", alternatives[1].PlantID)
+	assert.Equal(t, "Also grown for fruit", alternatives[1].Reason)
+}
+
+func TestRecommendAlternatives_CatalogError(t *testing.T) {
+	mockPlantService := new(mocks.MockPlantService)
+	mockPlantService.On("ListPlants", mock.Anything).Return(nil, errors.New("scan failed"))
+
+	_, err := RecommendAlternatives(context.TODO(), mockPlantService, models.PlantInfo{ID: "kale"}, nil, 3)
+	assert.Error(t, err)
+}
+
+func TestCompanionGraph(t *testing.T) {
+	mockPlantService := new(mocks.MockPlantService)
+	mockPlantService.On("ListPlants", mock.Anything).Return([]models.PlantInfo{
+		{ID: "tomato", Name: "Tomato", Companions: []companion.Link{{PlantID: "basil", Relation: companion.Good, Reason: "Repels whitefly"}}},
+		{ID: "basil", Name: "Basil"},
+	}, nil)
+
+	graph, err := CompanionGraph(context.TODO(), mockPlantService)
+	require.NoError(t, err)
+	assert.Equal(t, []companion.Neighbour{
+		{PlantID: "tomato", Name: "Tomato", Relation: companion.Good, Reason: "Repels whitefly"},
+	}, graph.Neighbours("basil"))
+
+	failing := new(mocks.MockPlantService)
+	failing.On("ListPlants", mock.Anything).Return(nil, errors.New("scan failed"))
+	_, err = CompanionGraph(context.TODO(), failing)
+	assert.Error(t, err)
+}
+
+// newResilientTestService builds a PlantService around the mock with no real sleeping
+func newResilientTestService(client *mocks.MockDynamoDBAPI, delays *[]time.Duration, opts ...Option) *PlantService {
+	s := &PlantService{
+		dynamoDBClient: client,
+		tableName:      "test-table",
+		retry:          DefaultRetryPolicy,
+		sleep: func(ctx context.Context, d time.Duration) error {
+			*delays = append(*delays, d)
+			return ctx.Err()
+		},
+		random: func() float64 { return 1 },
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
 
-	// Set up expectations for PDF generation
-	mockPDFGenerator.On("GeneratePDF", userLocation, expectedPlantInfo).Return([]byte("mock pdf data"), nil)
-	// Set up expectations for S3 upload
-	mockPDFGenerator.On("UploadToS3", []byte("mock pdf data"), "plant-report-bucket", "file.pdf").Return("https://plant-report-bucket.s3.amazonaws.com/file.pdf", nil)
+func throttled() error {
+	return awserr.New(dynamodb.ErrCodeProvisionedThroughputExceededException, "slow down", nil)
+}
+
+func TestGetPlantInfo_RetriesThrottling(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
 
-	// Simulate generating the PDF
-	pdfData, err := mockPDFGenerator.GeneratePDF(userLocation, expectedPlantInfo)
+	mockDynamoDB := mocks.NewMockDynamoDBAPI(ctrl)
+	var delays []time.Duration
+	plantService := newResilientTestService(mockDynamoDB, &delays)
+
+	gomock.InOrder(
+		mockDynamoDB.EXPECT().GetItemWithContext(gomock.Any(), gomock.Any()).Return(nil, throttled()),
+		mockDynamoDB.EXPECT().GetItemWithContext(gomock.Any(), gomock.Any()).Return(nil, awserr.New(dynamodb.ErrCodeInternalServerError, "oops", nil)),
+		mockDynamoDB.EXPECT().GetItemWithContext(gomock.Any(), gomock.Any()).Return(&dynamodb.GetItemOutput{
+			Item: map[string]*dynamodb.AttributeValue{"PlantID": {S: aws.String("kale")}},
+		}, nil),
+	)
+
+	plantInfo, err := plantService.GetPlantInfo(context.TODO(), "kale")
 	require.NoError(t, err)
+	assert.Equal(t, "kale", plantInfo.ID)
+	assert.Equal(t, []time.Duration{50 * time.Millisecond, 100 * time.Millisecond}, delays)
+}
+
+func TestGetPlantInfo_RetriesExhausted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDynamoDB := mocks.NewMockDynamoDBAPI(ctrl)
+	var delays []time.Duration
+	plantService := newResilientTestService(mockDynamoDB, &delays)
+
+	mockDynamoDB.EXPECT().GetItemWithContext(gomock.Any(), gomock.Any()).Return(nil, throttled()).Times(DefaultRetryPolicy.MaxAttempts)
+
+	_, err := plantService.GetPlantInfo(context.TODO(), "kale")
+	assert.ErrorContains(t, err, "failed to get item from DynamoDB")
+	assert.Len(t, delays, DefaultRetryPolicy.MaxAttempts-1)
+}
+
+func TestGetPlantInfo_CancelStopsRetries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDynamoDB := mocks.NewMockDynamoDBAPI(ctrl)
+	plantService := NewPlantService(mockDynamoDB, "test-table",
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 4, BaseDelay: time.Minute, MaxDelay: time.Minute}))
 
-	// Simulate uploading the PDF to S3
-	s3URL, err := mockPDFGenerator.UploadToS3(pdfData, "plant-report-bucket", "file.pdf")
+	// The request is cancelled while the first retry is backing off
+	ctx, cancel := context.WithCancel(context.Background())
+	mockDynamoDB.EXPECT().GetItemWithContext(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(aws.Context, *dynamodb.GetItemInput, ...request.Option) (*dynamodb.GetItemOutput, error) {
+			time.AfterFunc(10*time.Millisecond, cancel)
+			return nil, throttled()
+		}).Times(1)
+
+	start := time.Now()
+	_, err := plantService.GetPlantInfo(ctx, "kale")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), 10*time.Second)
+}
+
+func TestGetPlantInfo_DoesNotRetryPermanentErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDynamoDB := mocks.NewMockDynamoDBAPI(ctrl)
+	var delays []time.Duration
+	plantService := newResilientTestService(mockDynamoDB, &delays)
+
+	mockDynamoDB.EXPECT().GetItemWithContext(gomock.Any(), gomock.Any()).Return(nil, awserr.New(dynamodb.ErrCodeResourceNotFoundException, "no table", nil)).Times(1)
+
+	_, err := plantService.GetPlantInfo(context.TODO(), "kale")
+	assert.Error(t, err)
+	assert.Empty(t, delays)
+}
+
+func TestGetPlantInfo_ConsistentRead(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDynamoDB := mocks.NewMockDynamoDBAPI(ctrl)
+	var delays []time.Duration
+	plantService := newResilientTestService(mockDynamoDB, &delays, WithConsistentRead(true))
+
+	mockDynamoDB.EXPECT().GetItemWithContext(gomock.Any(), gomock.Any()).DoAndReturn(func(_ aws.Context, input *dynamodb.GetItemInput, _ ...request.Option) (*dynamodb.GetItemOutput, error) {
+		assert.True(t, aws.BoolValue(input.ConsistentRead))
+		return &dynamodb.GetItemOutput{}, nil
+	})
+
+	_, err := plantService.GetPlantInfo(context.TODO(), "kale")
+	assert.Equal(t, ErrPlantNotFound, err)
+}
+
+func TestGetPlantInfo_CircuitBreaker(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDynamoDB := mocks.NewMockDynamoDBAPI(ctrl)
+	var delays []time.Duration
+	plantService := newResilientTestService(mockDynamoDB, &delays,
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 1}),
+		WithCircuitBreaker(CircuitBreakerOptions{FailureThreshold: 2, Cooldown: time.Minute}),
+	)
+	now := time.Now()
+	plantService.breaker.now = func() time.Time { return now }
+
+	// A missing plant is a healthy response and doesn't count as a failure
+	mockDynamoDB.EXPECT().GetItemWithContext(gomock.Any(), gomock.Any()).Return(&dynamodb.GetItemOutput{}, nil)
+	_, err := plantService.GetPlantInfo(context.TODO(), "unknown")
+	assert.Equal(t, ErrPlantNotFound, err)
+
+	// Two throttled calls open the breaker
+	mockDynamoDB.EXPECT().GetItemWithContext(gomock.Any(), gomock.Any()).Return(nil, throttled()).Times(2)
+	for i := 0; i < 2; i++ {
+		_, err = plantService.GetPlantInfo(context.TODO(), "kale")
+		assert.NotErrorIs(t, err, ErrServiceUnavailable)
+	}
+
+	// While open, calls fail fast without reaching DynamoDB
+	_, err = plantService.GetPlantInfo(context.TODO(), "kale")
+	assert.ErrorIs(t, err, ErrServiceUnavailable)
+
+	// After the cooldown a successful trial call closes it again
+	now = now.Add(time.Minute)
+	mockDynamoDB.EXPECT().GetItemWithContext(gomock.Any(), gomock.Any()).Return(&dynamodb.GetItemOutput{
+		Item: map[string]*dynamodb.AttributeValue{"PlantID": {S: aws.String("kale")}},
+	}, nil).Times(2)
+	_, err = plantService.GetPlantInfo(context.TODO(), "kale")
+	require.NoError(t, err)
+	_, err = plantService.GetPlantInfo(context.TODO(), "kale")
 	require.NoError(t, err)
-	assert.Equal(t, "https://plant-report-bucket.s3.amazonaws.com/file.pdf", s3URL)
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	ceiling := func() float64 { return 1 }
+	half := func() float64 { return 0.5 }
 
-	// Assert that the expectations were met
-	mockPDFGenerator.AssertExpectations(t)
+	assert.Equal(t, 100*time.Millisecond, policy.backoff(1, ceiling))
+	assert.Equal(t, 400*time.Millisecond, policy.backoff(3, ceiling))
+	assert.Equal(t, time.Second, policy.backoff(10, ceiling))
+	assert.Equal(t, time.Second, policy.backoff(100, ceiling))
+	assert.Equal(t, 200*time.Millisecond, policy.backoff(3, half))
 }
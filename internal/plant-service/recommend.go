@@ -0,0 +1,76 @@
+package plantservice
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/HealthyTechGuy/plant-report-app/models"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/suitability"
+)
+
+// ScoreFunc scores how well a plant suits the location being reported on
+type ScoreFunc func(plantInfo models.PlantInfo) suitability.Assessment
+
+// RecommendAlternatives returns up to n plants from the catalog that are similar to the given plant,
+// sharing its category or one of its uses, and that score as a good fit for the location.
+func RecommendAlternatives(ctx context.Context, catalog PlantServiceInterface, plantInfo models.PlantInfo, score ScoreFunc, n int) ([]models.Alternative, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	plants, err := catalog.ListPlants(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list plants for recommendations: %w", err)
+	}
+
+	var alternatives []models.Alternative
+	for _, p := range plants {
+		if p.ID == plantInfo.ID {
+			continue
+		}
+		reason, ok := similarity(plantInfo, p)
+		if !ok {
+			continue
+		}
+		assessment := score(p)
+		if assessment.Verdict != suitability.Yes {
+			continue
+		}
+		alternatives = append(alternatives, models.Alternative{
+			PlantID: p.ID,
+			Name:    p.Name,
+			Score:   assessment.Score,
+			Verdict: assessment.Verdict,
+			Reason:  reason,
+		})
+	}
+
+	// Best fit first, by name for a stable order between equal scores
+	sort.Slice(alternatives, func(i, j int) bool {
+		if alternatives[i].Score != alternatives[j].Score {
+			return alternatives[i].Score > alternatives[j].Score
+		}
+		return alternatives[i].Name < alternatives[j].Name
+	})
+	if len(alternatives) > n {
+		alternatives = alternatives[:n]
+	}
+	return alternatives, nil
+}
+
+// similarity reports whether two plants share a category or use, and describes the match
+func similarity(a, b models.PlantInfo) (string, bool) {
+	if a.Category != "" && strings.EqualFold(a.Category, b.Category) {
+		return fmt.Sprintf("Also a %s", strings.ToLower(b.Category)), true
+	}
+	for _, use := range a.Uses {
+		for _, other := range b.Uses {
+			if strings.EqualFold(use, other) {
+				return fmt.Sprintf("Also grown for %s", strings.ToLower(other)), true
+			}
+		}
+	}
+	return "", false
+}
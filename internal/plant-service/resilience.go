@@ -0,0 +1,172 @@
+package plantservice
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+var (
+	// ErrServiceUnavailable is returned while the circuit breaker is open because DynamoDB is unhealthy
+	ErrServiceUnavailable = errors.New("plant catalog temporarily unavailable")
+)
+
+// RetryPolicy controls how transient DynamoDB errors are retried
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first
+	MaxAttempts int
+	// BaseDelay is the backoff before the first retry, doubled for each retry after that
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff between attempts
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy retries up to three times, backing off from 50ms to at most 1s
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   50 * time.Millisecond,
+	MaxDelay:    time.Second,
+}
+
+// backoff returns a full-jitter delay before the given retry (1 for the first retry)
+func (p RetryPolicy) backoff(retry int, random func() float64) time.Duration {
+	ceiling := p.MaxDelay
+	// Limit the shift so large retry counts can't overflow
+	if shift := retry - 1; shift < 32 {
+		if d := p.BaseDelay << shift; d > 0 && d < p.MaxDelay {
+			ceiling = d
+		}
+	}
+	return time.Duration(random() * float64(ceiling))
+}
+
+// CircuitBreakerOptions controls when the circuit breaker opens and how long it stays open
+type CircuitBreakerOptions struct {
+	// FailureThreshold is the number of consecutive failed calls that opens the breaker
+	FailureThreshold int
+	// Cooldown is how long the breaker stays open before letting a trial call through
+	Cooldown time.Duration
+}
+
+// DefaultCircuitBreakerOptions opens after five consecutive failures for 30 seconds
+var DefaultCircuitBreakerOptions = CircuitBreakerOptions{
+	FailureThreshold: 5,
+	Cooldown:         30 * time.Second,
+}
+
+// circuitBreaker fails fast after repeated failures, letting a single trial call through once the cooldown passes
+type circuitBreaker struct {
+	opts CircuitBreakerOptions
+	now  func() time.Time
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	trial     bool
+}
+
+// allow reports whether a call may proceed
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.opts.FailureThreshold {
+		return true
+	}
+	if b.now().Before(b.openUntil) || b.trial {
+		return false
+	}
+	// Half-open: let one trial call through
+	b.trial = true
+	return true
+}
+
+// record updates the breaker with the outcome of a call
+func (b *circuitBreaker) record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+	if success {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.opts.FailureThreshold {
+		b.openUntil = b.now().Add(b.opts.Cooldown)
+	}
+}
+
+// retryableCodes are DynamoDB error codes worth retrying
+var retryableCodes = map[string]bool{
+	dynamodb.ErrCodeProvisionedThroughputExceededException: true,
+	dynamodb.ErrCodeRequestLimitExceeded:                   true,
+	dynamodb.ErrCodeInternalServerError:                    true,
+	"ThrottlingException":                                  true,
+	"ServiceUnavailable":                                   true,
+}
+
+// isRetryable reports whether an error is throttling or otherwise transient
+func isRetryable(err error) bool {
+	var aerr awserr.Error
+	if errors.As(err, &aerr) && retryableCodes[aerr.Code()] {
+		return true
+	}
+	return request.IsErrorThrottle(err) || request.IsErrorRetryable(err)
+}
+
+// sleepContext waits for d, returning early with the context's error if it is cancelled first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
+// call runs fn through the circuit breaker, retrying transient errors with jittered exponential
+// backoff. Cancelling ctx stops the retries and returns the context's error.
+func (s *PlantService) call(ctx context.Context, fn func() error) error {
+	if s.breaker != nil && !s.breaker.allow() {
+		return ErrServiceUnavailable
+	}
+
+	attempts := s.retry.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	sleep, random := s.sleep, s.random
+	if sleep == nil {
+		sleep = sleepContext
+	}
+	if random == nil {
+		random = rand.Float64
+	}
+
+	var err, cancelled error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			if cancelled = sleep(ctx, s.retry.backoff(attempt-1, random)); cancelled != nil {
+				break
+			}
+		}
+		if err = fn(); err == nil || !isRetryable(err) {
+			break
+		}
+	}
+
+	if s.breaker != nil {
+		// Only transient failures count against DynamoDB's health. A retry cut short by
+		// cancellation still records the transient failure that led to it.
+		s.breaker.record(err == nil || !isRetryable(err))
+	}
+	if cancelled != nil {
+		return cancelled
+	}
+	return err
+}
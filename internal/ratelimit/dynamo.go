@@ -0,0 +1,108 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// maxWriteAttempts is how often Allow retries when another instance updated the bucket first
+const maxWriteAttempts = 3
+
+// DynamoLimiter keeps buckets in a DynamoDB table keyed by BucketKey, so every Lambda instance
+// shares them. Buckets are read and written back with a condition on updated_at, so concurrent
+// requests cannot both spend the same token, and carry an expires_at attribute for DynamoDB TTL.
+type DynamoLimiter struct {
+	client    dynamodbiface.DynamoDBAPI
+	tableName string
+	limit     Limit
+	now       func() time.Time
+}
+
+// NewDynamoLimiter creates a DynamoLimiter
+func NewDynamoLimiter(client dynamodbiface.DynamoDBAPI, tableName string, limit Limit) *DynamoLimiter {
+	return &DynamoLimiter{client: client, tableName: tableName, limit: limit, now: time.Now}
+}
+
+// Allow takes a token from key's bucket. A bucket that keeps changing under it is treated as
+// over the limit, since that only happens when the key is sending many requests at once.
+func (l *DynamoLimiter) Allow(ctx context.Context, key string) (Decision, error) {
+	for attempt := 0; attempt < maxWriteAttempts; attempt++ {
+		result, err := l.client.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+			TableName:      aws.String(l.tableName),
+			Key:            l.key(key),
+			ConsistentRead: aws.Bool(true),
+		})
+		if err != nil {
+			return Decision{}, fmt.Errorf("failed to get rate limit bucket from DynamoDB: %w", err)
+		}
+
+		now := l.now()
+		tokens, last := float64(l.limit.Burst), now
+		previous := numberAttr(result.Item, "updated_at")
+		if previous != nil {
+			tokens, _ = strconv.ParseFloat(aws.StringValue(numberAttr(result.Item, "tokens")), 64)
+			updated, _ := strconv.ParseInt(*previous, 10, 64)
+			last = time.UnixMilli(updated)
+		}
+
+		tokens, decision := l.limit.take(tokens, last, now)
+		if !decision.Allowed {
+			return decision, nil
+		}
+
+		err = l.put(ctx, key, tokens, now, previous)
+		var aerr awserr.Error
+		if errors.As(err, &aerr) && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			continue
+		}
+		if err != nil {
+			return Decision{}, fmt.Errorf("failed to update rate limit bucket in DynamoDB: %w", err)
+		}
+		return decision, nil
+	}
+	return Decision{RetryAfter: time.Duration(float64(time.Second) / l.limit.PerSecond)}, nil
+}
+
+// put writes the bucket back if nobody else has since previous, or if it did not exist when previous is nil
+func (l *DynamoLimiter) put(ctx context.Context, key string, tokens float64, now time.Time, previous *string) error {
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String(l.tableName),
+		Item: map[string]*dynamodb.AttributeValue{
+			"BucketKey":  {S: aws.String(key)},
+			"tokens":     {N: aws.String(strconv.FormatFloat(tokens, 'f', -1, 64))},
+			"updated_at": {N: aws.String(strconv.FormatInt(now.UnixMilli(), 10))},
+			"expires_at": {N: aws.String(strconv.FormatInt(now.Add(l.limit.fillTime()+time.Hour).Unix(), 10))},
+		},
+		ConditionExpression: aws.String("attribute_not_exists(BucketKey)"),
+	}
+	if previous != nil {
+		input.ConditionExpression = aws.String("updated_at = :previous")
+		input.ExpressionAttributeValues = map[string]*dynamodb.AttributeValue{
+			":previous": {N: previous},
+		}
+	}
+	_, err := l.client.PutItemWithContext(ctx, input)
+	return err
+}
+
+func (l *DynamoLimiter) key(key string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"BucketKey": {S: aws.String(key)},
+	}
+}
+
+// numberAttr returns the raw number of an attribute, nil when it is missing
+func numberAttr(item map[string]*dynamodb.AttributeValue, name string) *string {
+	if v, ok := item[name]; ok && v != nil {
+		return v.N
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// pruneThreshold is how many buckets MemoryLimiter holds before dropping full ones
+const pruneThreshold = 10000
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// MemoryLimiter keeps buckets in memory. Each Lambda instance has its own buckets, so it suits
+// the local server and single instances; use DynamoLimiter to share limits between instances.
+type MemoryLimiter struct {
+	limit   Limit
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+// NewMemoryLimiter creates a MemoryLimiter
+func NewMemoryLimiter(limit Limit) *MemoryLimiter {
+	return &MemoryLimiter{limit: limit, buckets: make(map[string]*bucket), now: time.Now}
+}
+
+// Allow takes a token from key's bucket
+func (l *MemoryLimiter) Allow(ctx context.Context, key string) (Decision, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+
+	b, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= pruneThreshold {
+			l.prune(now)
+		}
+		b = &bucket{tokens: float64(l.limit.Burst), last: now}
+		l.buckets[key] = b
+	}
+
+	tokens, decision := l.limit.take(b.tokens, b.last, now)
+	b.tokens, b.last = tokens, now
+	return decision, nil
+}
+
+// prune drops buckets that have refilled completely, they are the same as new ones
+func (l *MemoryLimiter) prune(now time.Time) {
+	full := l.limit.fillTime()
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= full {
+			delete(l.buckets, key)
+		}
+	}
+}
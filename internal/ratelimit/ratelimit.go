@@ -0,0 +1,115 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/HealthyTechGuy/plant-report-app/internal/auth"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/apigw"
+	"github.com/aws/aws-lambda-go/events"
+	"go.uber.org/zap"
+)
+
+// Limit is a token bucket: it holds up to Burst requests and refills at PerSecond
+type Limit struct {
+	PerSecond float64
+	Burst     int
+}
+
+// PerMinute returns a Limit allowing n requests a minute with bursts of up to burst
+func PerMinute(n float64, burst int) Limit {
+	return Limit{PerSecond: n / 60, Burst: burst}
+}
+
+// Decision is the outcome of taking a token from a bucket
+type Decision struct {
+	Allowed bool
+	// Remaining is how many whole tokens are left in the bucket
+	Remaining int
+	// RetryAfter is how long until the next token when the request was refused
+	RetryAfter time.Duration
+}
+
+// Limiter takes tokens from per-key buckets
+type Limiter interface {
+	Allow(ctx context.Context, key string) (Decision, error)
+}
+
+// take refills a bucket last updated at last with tokens left up to now, then takes one token
+func (l Limit) take(tokens float64, last, now time.Time) (float64, Decision) {
+	if elapsed := now.Sub(last); elapsed > 0 {
+		tokens = math.Min(float64(l.Burst), tokens+elapsed.Seconds()*l.PerSecond)
+	}
+	if tokens < 1 {
+		wait := time.Duration((1 - tokens) / l.PerSecond * float64(time.Second))
+		return tokens, Decision{RetryAfter: wait}
+	}
+	tokens--
+	return tokens, Decision{Allowed: true, Remaining: int(tokens)}
+}
+
+// fillTime is how long an empty bucket takes to fill up again
+func (l Limit) fillTime() time.Duration {
+	return time.Duration(float64(l.Burst) / l.PerSecond * float64(time.Second))
+}
+
+// KeyFunc identifies who a request is limited as, or returns "" to leave it unlimited
+type KeyFunc func(ctx context.Context, request events.APIGatewayProxyRequest) string
+
+// IPKey limits requests by the caller's IP address. It needs nothing from authentication, so it
+// can run first and turn floods away before they reach the credential store.
+func IPKey(_ context.Context, request events.APIGatewayProxyRequest) string {
+	if ip := request.RequestContext.Identity.SourceIP; ip != "" {
+		return "ip:" + ip
+	}
+	return ""
+}
+
+// ClientKey limits requests by the client authentication verified. It must run after the auth
+// middleware: credentials that have not been checked would let a caller get a fresh bucket for
+// every request by making up a new key each time.
+func ClientKey(ctx context.Context, _ events.APIGatewayProxyRequest) string {
+	if client, ok := auth.ClientFrom(ctx); ok {
+		return "client:" + client.ID
+	}
+	return ""
+}
+
+// Middleware refuses requests over the limit for their key with 429 and a Retry-After header. If
+// the limiter fails the request is let through, rate limiting is not worth an outage.
+func Middleware(limiter Limiter, key KeyFunc, logger *zap.Logger) apigw.Middleware {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return func(next apigw.HandlerFunc) apigw.HandlerFunc {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			key := key(ctx, request)
+			if key == "" {
+				return next(ctx, request)
+			}
+
+			decision, err := limiter.Allow(ctx, key)
+			if err != nil {
+				logger.Warn("error checking rate limit, allowing request", zap.Error(err))
+				return next(ctx, request)
+			}
+			if !decision.Allowed {
+				return apigw.ErrorResponse(429, "Too many requests, please slow down", map[string]string{
+					"Retry-After": strconv.Itoa(retryAfterSeconds(decision.RetryAfter)),
+				}), nil
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// retryAfterSeconds rounds a wait up to whole seconds, at least one
+func retryAfterSeconds(wait time.Duration) int {
+	seconds := int(math.Ceil(wait.Seconds()))
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
@@ -0,0 +1,194 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/HealthyTechGuy/plant-report-app/internal/auth"
+	"github.com/HealthyTechGuy/plant-report-app/internal/plant-service/mocks"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var start = time.Date(2026, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+// clock is a settable fake time source
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time { return c.t }
+
+func TestMemoryLimiter_Allow(t *testing.T) {
+	c := &clock{t: start}
+	limiter := NewMemoryLimiter(PerMinute(60, 3))
+	limiter.now = c.now
+
+	for i := 2; i >= 0; i-- {
+		decision, err := limiter.Allow(context.TODO(), "ip:1.2.3.4")
+		require.NoError(t, err)
+		assert.True(t, decision.Allowed)
+		assert.Equal(t, i, decision.Remaining)
+	}
+
+	decision, err := limiter.Allow(context.TODO(), "ip:1.2.3.4")
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, time.Second, decision.RetryAfter)
+
+	// Other keys have their own bucket
+	decision, _ = limiter.Allow(context.TODO(), "ip:5.6.7.8")
+	assert.True(t, decision.Allowed)
+
+	// One token a second comes back
+	c.t = c.t.Add(1500 * time.Millisecond)
+	decision, _ = limiter.Allow(context.TODO(), "ip:1.2.3.4")
+	assert.True(t, decision.Allowed)
+	decision, _ = limiter.Allow(context.TODO(), "ip:1.2.3.4")
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, 500*time.Millisecond, decision.RetryAfter)
+}
+
+func TestMemoryLimiter_PrunesFullBuckets(t *testing.T) {
+	c := &clock{t: start}
+	limiter := NewMemoryLimiter(PerMinute(60, 1))
+	limiter.now = c.now
+
+	for i := 0; i < pruneThreshold; i++ {
+		_, _ = limiter.Allow(context.TODO(), "ip:"+strconv.Itoa(i))
+	}
+	c.t = c.t.Add(time.Minute)
+	_, _ = limiter.Allow(context.TODO(), "ip:new")
+	assert.Len(t, limiter.buckets, 1)
+}
+
+func TestIPKey(t *testing.T) {
+	request := events.APIGatewayProxyRequest{}
+	request.RequestContext.Identity.SourceIP = "203.0.113.7"
+	assert.Equal(t, "ip:203.0.113.7", IPKey(context.TODO(), request))
+
+	// Credentials are not trusted before authentication checks them
+	request.Headers = map[string]string{"X-Api-Key": "secret"}
+	assert.Equal(t, "ip:203.0.113.7", IPKey(context.TODO(), request))
+
+	assert.Empty(t, IPKey(context.TODO(), events.APIGatewayProxyRequest{}))
+}
+
+func TestClientKey(t *testing.T) {
+	request := events.APIGatewayProxyRequest{Headers: map[string]string{"X-Api-Key": "secret"}}
+	assert.Empty(t, ClientKey(context.TODO(), request))
+
+	ctx := auth.WithClient(context.TODO(), auth.Client{ID: "acme"})
+	assert.Equal(t, "client:acme", ClientKey(ctx, request))
+}
+
+// limiterFunc adapts a function to Limiter
+type limiterFunc func(ctx context.Context, key string) (Decision, error)
+
+func (f limiterFunc) Allow(ctx context.Context, key string) (Decision, error) { return f(ctx, key) }
+
+func TestMiddleware(t *testing.T) {
+	ok := func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		return events.APIGatewayProxyResponse{StatusCode: 200}, nil
+	}
+	request := events.APIGatewayProxyRequest{}
+	request.RequestContext.Identity.SourceIP = "203.0.113.7"
+
+	limiter := NewMemoryLimiter(PerMinute(6, 1))
+	limiter.now = (&clock{t: start}).now
+	handler := Middleware(limiter, IPKey, nil)(ok)
+
+	response, err := handler(context.TODO(), request)
+	require.NoError(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+
+	response, err = handler(context.TODO(), request)
+	require.NoError(t, err)
+	assert.Equal(t, 429, response.StatusCode)
+	assert.Equal(t, "10", response.Headers["Retry-After"])
+
+	// A broken limiter lets requests through
+	broken := Middleware(limiterFunc(func(context.Context, string) (Decision, error) {
+		return Decision{}, errors.New("boom")
+	}), IPKey, nil)(ok)
+	response, err = broken(context.TODO(), request)
+	require.NoError(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+}
+
+func TestMiddleware_MadeUpKeysShareTheIPBucket(t *testing.T) {
+	ok := func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		return events.APIGatewayProxyResponse{StatusCode: 200}, nil
+	}
+	limiter := NewMemoryLimiter(PerMinute(6, 2))
+	limiter.now = (&clock{t: start}).now
+	handler := Middleware(limiter, IPKey, nil)(ok)
+
+	var statuses []int
+	for i := 0; i < 3; i++ {
+		request := events.APIGatewayProxyRequest{Headers: map[string]string{"X-Api-Key": "guess-" + strconv.Itoa(i)}}
+		request.RequestContext.Identity.SourceIP = "203.0.113.7"
+		response, err := handler(context.TODO(), request)
+		require.NoError(t, err)
+		statuses = append(statuses, response.StatusCode)
+	}
+	assert.Equal(t, []int{200, 200, 429}, statuses)
+}
+
+func TestDynamoLimiter_NewBucket(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDynamoDB := mocks.NewMockDynamoDBAPI(ctrl)
+	limiter := NewDynamoLimiter(mockDynamoDB, "rate-limits", PerMinute(60, 5))
+	limiter.now = func() time.Time { return start }
+
+	mockDynamoDB.EXPECT().GetItemWithContext(gomock.Any(), &dynamodb.GetItemInput{
+		TableName:      aws.String("rate-limits"),
+		Key:            map[string]*dynamodb.AttributeValue{"BucketKey": {S: aws.String("ip:1.2.3.4")}},
+		ConsistentRead: aws.Bool(true),
+	}).Return(&dynamodb.GetItemOutput{}, nil)
+	mockDynamoDB.EXPECT().PutItemWithContext(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ aws.Context, input *dynamodb.PutItemInput, _ ...interface{}) (*dynamodb.PutItemOutput, error) {
+			assert.Equal(t, "attribute_not_exists(BucketKey)", aws.StringValue(input.ConditionExpression))
+			assert.Equal(t, "4", aws.StringValue(input.Item["tokens"].N))
+			return &dynamodb.PutItemOutput{}, nil
+		})
+
+	decision, err := limiter.Allow(context.TODO(), "ip:1.2.3.4")
+	require.NoError(t, err)
+	assert.Equal(t, Decision{Allowed: true, Remaining: 4}, decision)
+}
+
+func TestDynamoLimiter_RetriesConcurrentUpdate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDynamoDB := mocks.NewMockDynamoDBAPI(ctrl)
+	limiter := NewDynamoLimiter(mockDynamoDB, "rate-limits", PerMinute(60, 5))
+	limiter.now = func() time.Time { return start }
+
+	updated := aws.String("1780315198000") // two seconds before start
+	gomock.InOrder(
+		mockDynamoDB.EXPECT().GetItemWithContext(gomock.Any(), gomock.Any()).Return(&dynamodb.GetItemOutput{
+			Item: map[string]*dynamodb.AttributeValue{"tokens": {N: aws.String("1")}, "updated_at": {N: updated}},
+		}, nil),
+		mockDynamoDB.EXPECT().PutItemWithContext(gomock.Any(), gomock.Any()).Return(nil,
+			awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "changed", nil)),
+		mockDynamoDB.EXPECT().GetItemWithContext(gomock.Any(), gomock.Any()).Return(&dynamodb.GetItemOutput{
+			Item: map[string]*dynamodb.AttributeValue{"tokens": {N: aws.String("0.5")}, "updated_at": {N: aws.String("1780315200000")}},
+		}, nil),
+	)
+
+	// The second read finds the bucket drained by another instance
+	decision, err := limiter.Allow(context.TODO(), "key:abc")
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, 500*time.Millisecond, decision.RetryAfter)
+}
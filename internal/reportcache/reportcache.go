@@ -0,0 +1,161 @@
+package reportcache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/HealthyTechGuy/plant-report-app/models"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/geo"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/storage"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/suitability"
+)
+
+// DefaultCellSize is the default grid cell size in degrees, roughly 11 km of latitude
+const DefaultCellSize = 0.1
+
+// keyPrefix is the storage prefix under which all cached reports live
+const keyPrefix = "reports/"
+
+// Key identifies a cached report. Any change to the plant's data (via its fingerprint)
+// or to the template version produces a new key, so stale entries are never served. Entries
+// are never invalidated in place: the key also carries the date, so every report is rebuilt
+// at least daily, and superseded entries are left for the retention cleanup to remove.
+type Key struct {
+	PlantID         string
+	Fingerprint     string
+	Cell            geo.Cell
+	Locale          string
+	Format          string
+	TemplateVersion string
+	Date            string
+}
+
+// ObjectKey returns the storage key of the cached report
+func (k Key) ObjectKey() string {
+	return fmt.Sprintf("%s%s/%s/%s/%s/%s/%s.%s",
+		keyPrefix, k.PlantID, k.TemplateVersion, k.Fingerprint, k.Cell, k.Date, k.Locale, k.Format)
+}
+
+// summaryKey returns the storage key of the JSON summary written alongside the report
+func (k Key) summaryKey() string {
+	return k.ObjectKey() + ".json"
+}
+
+// Entry is a cached report and the data returned alongside it in the API response
+type Entry struct {
+	URL          string                  `json:"-"`
+	Suitability  *suitability.Assessment `json:"suitability,omitempty"`
+	Alternatives []models.Alternative    `json:"alternatives,omitempty"`
+	// Size and Checksum, the hex SHA-256, describe the cached report. Put fills them in.
+	Size     int64  `json:"size,omitempty"`
+	Checksum string `json:"checksum,omitempty"`
+}
+
+// Cache stores generated reports so repeat requests for the same plant near the same place are served without re-rendering
+type Cache struct {
+	store    storage.Store
+	cellSize float64
+}
+
+// New creates a Cache on top of a store. A cellSize of zero uses DefaultCellSize.
+func New(store storage.Store, cellSize float64) *Cache {
+	if cellSize <= 0 {
+		cellSize = DefaultCellSize
+	}
+	return &Cache{
+		store:    store,
+		cellSize: cellSize,
+	}
+}
+
+// CellSize returns the grid cell size in degrees that cached report locations are rounded to
+func (c *Cache) CellSize() float64 {
+	return c.cellSize
+}
+
+// Cell returns the grid cell a coordinate falls into
+func (c *Cache) Cell(latitude, longitude float64) geo.Cell {
+	return geo.CellOf(latitude, longitude, c.cellSize)
+}
+
+// Lookup returns the cached entry for key, reporting false on a miss
+func (c *Cache) Lookup(ctx context.Context, key Key) (Entry, bool, error) {
+	data, err := c.store.Get(ctx, key.summaryKey())
+	if errors.Is(err, storage.ErrNotFound) {
+		return Entry{}, false, nil
+	}
+	if err != nil {
+		return Entry{}, false, fmt.Errorf("failed to look up cached report: %w", err)
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		// A corrupt summary is treated as a miss and overwritten on the next Put
+		return Entry{}, false, nil
+	}
+	entry.URL = c.store.URL(key.ObjectKey())
+	return entry, true, nil
+}
+
+// Put stores a rendered report, read from report as it is uploaded, and its summary, returning
+// the report URL. The summary is written last so a hit always has a complete report behind it.
+func (c *Cache) Put(ctx context.Context, key Key, report io.Reader, contentType string, entry Entry) (string, error) {
+	digest := sha256.New()
+	counter := &countingWriter{}
+	if err := c.store.Put(ctx, key.ObjectKey(), io.TeeReader(report, io.MultiWriter(digest, counter)), contentType); err != nil {
+		return "", err
+	}
+	entry.Size, entry.Checksum = counter.n, hex.EncodeToString(digest.Sum(nil))
+	summary, err := json.Marshal(entry)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cache summary: %w", err)
+	}
+	if err := c.store.Put(ctx, key.summaryKey(), bytes.NewReader(summary), "application/json"); err != nil {
+		return "", err
+	}
+	return c.store.URL(key.ObjectKey()), nil
+}
+
+// countingWriter counts the bytes written to it
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+// Report returns the stored PDF for a cached report
+func (c *Cache) Report(ctx context.Context, key Key) ([]byte, error) {
+	return c.store.Get(ctx, key.ObjectKey())
+}
+
+// Fingerprint returns a short hash of a plant's data, used to key reports on the data they were rendered from.
+// The IDs of the plants grown alongside it are included, in any order and once each, as they change
+// the companion planting section.
+func Fingerprint(plantInfo models.PlantInfo, neighbours ...string) string {
+	data, _ := json.Marshal(plantInfo)
+	if len(neighbours) > 0 {
+		sorted := append([]string(nil), neighbours...)
+		sort.Strings(sorted)
+		// Repeating a neighbour does not change the report, so it must not change the key either
+		unique := sorted[:1]
+		for _, id := range sorted[1:] {
+			if id != unique[len(unique)-1] {
+				unique = append(unique, id)
+			}
+		}
+		data = append(data, strings.Join(unique, ",")...)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
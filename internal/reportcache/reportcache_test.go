@@ -0,0 +1,101 @@
+package reportcache
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/HealthyTechGuy/plant-report-app/models"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/storage"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/suitability"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testKey(cache *Cache, plantInfo models.PlantInfo) Key {
+	return Key{
+		PlantID:         plantInfo.ID,
+		Fingerprint:     Fingerprint(plantInfo),
+		Cell:            cache.Cell(40.7128, -74.0060),
+		Locale:          "imperial",
+		Format:          "pdf",
+		TemplateVersion: "1",
+		Date:            "2026-10-19",
+	}
+}
+
+func TestCache_MissThenHit(t *testing.T) {
+	store := storage.NewMemoryStore()
+	cache := New(store, 0)
+	key := testKey(cache, models.PlantInfo{ID: "kale", Name: "Kale"})
+
+	_, hit, err := cache.Lookup(context.TODO(), key)
+	require.NoError(t, err)
+	assert.False(t, hit)
+
+	assessment := &suitability.Assessment{Score: 90, Verdict: suitability.Yes}
+	url, err := cache.Put(context.TODO(), key, strings.NewReader("PDF content"), "application/pdf", Entry{Suitability: assessment})
+	require.NoError(t, err)
+	assert.Equal(t, "memory://"+key.ObjectKey(), url)
+
+	entry, hit, err := cache.Lookup(context.TODO(), key)
+	require.NoError(t, err)
+	assert.True(t, hit)
+	assert.Equal(t, url, entry.URL)
+	assert.Equal(t, assessment, entry.Suitability)
+	assert.Equal(t, int64(len("PDF content")), entry.Size)
+	assert.Equal(t, "7e7f04c8b5646f7ad29b1cb0c8085d4ff9c6b08f2a632f496641b31f524c7b98", entry.Checksum)
+
+	data, err := store.Get(context.TODO(), key.ObjectKey())
+	require.NoError(t, err)
+	assert.Equal(t, "PDF content", string(data))
+}
+
+func TestCache_KeyChangesInvalidate(t *testing.T) {
+	cache := New(storage.NewMemoryStore(), 0)
+	kale := models.PlantInfo{ID: "kale", Name: "Kale", HardinessZone: "7-9"}
+	key := testKey(cache, kale)
+	_, err := cache.Put(context.TODO(), key, strings.NewReader("PDF content"), "application/pdf", Entry{})
+	require.NoError(t, err)
+
+	// Changed plant data gives a new fingerprint
+	updated := kale
+	updated.HardinessZone = "6-9"
+	_, hit, err := cache.Lookup(context.TODO(), testKey(cache, updated))
+	require.NoError(t, err)
+	assert.False(t, hit)
+
+	// So does growing it alongside other plants, whatever their order
+	withNeighbours := key
+	withNeighbours.Fingerprint = Fingerprint(kale, "onion", "carrot")
+	_, hit, err = cache.Lookup(context.TODO(), withNeighbours)
+	require.NoError(t, err)
+	assert.False(t, hit)
+	assert.Equal(t, withNeighbours.Fingerprint, Fingerprint(kale, "carrot", "onion"))
+	assert.Equal(t, withNeighbours.Fingerprint, Fingerprint(kale, "onion", "carrot", "onion"))
+
+	// So does a new template version
+	newTemplate := key
+	newTemplate.TemplateVersion = "2"
+	_, hit, err = cache.Lookup(context.TODO(), newTemplate)
+	require.NoError(t, err)
+	assert.False(t, hit)
+
+	// A nearby location in the same cell still hits
+	nearby := key
+	nearby.Cell = cache.Cell(40.7150, -74.0100)
+	_, hit, err = cache.Lookup(context.TODO(), nearby)
+	require.NoError(t, err)
+	assert.True(t, hit)
+}
+
+func TestCache_CorruptSummaryIsAMiss(t *testing.T) {
+	store := storage.NewMemoryStore()
+	cache := New(store, 0)
+	key := testKey(cache, models.PlantInfo{ID: "kale"})
+	require.NoError(t, store.Put(context.TODO(), key.summaryKey(), strings.NewReader("not json"), "application/json"))
+
+	_, hit, err := cache.Lookup(context.TODO(), key)
+	require.NoError(t, err)
+	assert.False(t, hit)
+}
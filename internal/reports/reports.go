@@ -0,0 +1,238 @@
+// Package reports records the metadata of every generated report so it can be looked up by
+// report ID after the response that created it is gone
+package reports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// LocationDecimals is how many decimal places recorded coordinates keep, about 1 km
+const LocationDecimals = 2
+
+// ErrNotFound is returned by Get for unknown report IDs
+var ErrNotFound = errors.New("report not found")
+
+// Record describes one generated report
+type Record struct {
+	ReportID string
+	// OwnerID is who requested the report, the only principal allowed to look it up. It is empty
+	// for reports requested without authentication.
+	OwnerID string
+	// PlantIDs lists the report's plant first, followed by the alternatives it suggests
+	PlantIDs []string
+	// Latitude and Longitude are rounded to LocationDecimals places
+	Latitude        float64
+	Longitude       float64
+	Format          string
+	Language        string
+	TemplateVersion string
+	// Size is the report's length in bytes and Checksum its hex SHA-256
+	Size       int64
+	Checksum   string
+	StorageKey string
+	CreatedAt  time.Time
+	// ExpiresAt is when the report's retention ends, zero keeps it forever. The DynamoDB table
+	// removes expired records through its TTL, which can lag by a day or two, so readers must
+	// check Expired themselves.
+	ExpiresAt time.Time
+}
+
+// Expired reports whether the record's retention has ended at now
+func (r Record) Expired(now time.Time) bool {
+	return !r.ExpiresAt.IsZero() && !now.Before(r.ExpiresAt)
+}
+
+// Store keeps report metadata by report ID
+type Store interface {
+	Put(ctx context.Context, record Record) error
+	Get(ctx context.Context, reportID string) (Record, error)
+	// StorageKeys returns the storage keys of every record not expired at now
+	StorageKeys(ctx context.Context, now time.Time) (map[string]bool, error)
+}
+
+// RoundLocation rounds a coordinate pair to LocationDecimals places, so records do not pin down
+// where a user is any closer than the report needs
+func RoundLocation(latitude, longitude float64) (float64, float64) {
+	scale := math.Pow10(LocationDecimals)
+	return math.Round(latitude*scale) / scale, math.Round(longitude*scale) / scale
+}
+
+// MemoryStore is an in-memory Store for tests and the local server
+type MemoryStore struct {
+	mu      sync.RWMutex
+	records map[string]Record
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]Record)}
+}
+
+// Put records a report, replacing any record with the same ID
+func (s *MemoryStore) Put(ctx context.Context, record Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[record.ReportID] = record
+	return nil
+}
+
+// Get returns the record of a report
+func (s *MemoryStore) Get(ctx context.Context, reportID string) (Record, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	record, ok := s.records[reportID]
+	if !ok {
+		return Record{}, ErrNotFound
+	}
+	return record, nil
+}
+
+// StorageKeys returns the storage keys of every record not expired at now
+func (s *MemoryStore) StorageKeys(ctx context.Context, now time.Time) (map[string]bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	keys := make(map[string]bool)
+	for _, record := range s.records {
+		if !record.Expired(now) {
+			keys[record.StorageKey] = true
+		}
+	}
+	return keys, nil
+}
+
+// DynamoStore keeps report metadata in a DynamoDB table partitioned by ReportID, with the
+// expires_at attribute, in Unix seconds, as the table's TTL
+type DynamoStore struct {
+	client    dynamodbiface.DynamoDBAPI
+	tableName string
+}
+
+// NewDynamoStore creates a DynamoStore
+func NewDynamoStore(client dynamodbiface.DynamoDBAPI, tableName string) *DynamoStore {
+	return &DynamoStore{client: client, tableName: tableName}
+}
+
+// Put records a report
+func (s *DynamoStore) Put(ctx context.Context, record Record) error {
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String(s.tableName),
+		Item: map[string]*dynamodb.AttributeValue{
+			"ReportID":         {S: aws.String(record.ReportID)},
+			"plant_ids":        {L: stringList(record.PlantIDs)},
+			"latitude":         {N: aws.String(strconv.FormatFloat(record.Latitude, 'f', -1, 64))},
+			"longitude":        {N: aws.String(strconv.FormatFloat(record.Longitude, 'f', -1, 64))},
+			"format":           {S: aws.String(record.Format)},
+			"language":         {S: aws.String(record.Language)},
+			"template_version": {S: aws.String(record.TemplateVersion)},
+			"size":             {N: aws.String(strconv.FormatInt(record.Size, 10))},
+			"checksum":         {S: aws.String(record.Checksum)},
+			"storage_key":      {S: aws.String(record.StorageKey)},
+			"created_at":       {S: aws.String(record.CreatedAt.UTC().Format(time.RFC3339Nano))},
+		},
+	}
+	if record.OwnerID != "" {
+		input.Item["owner_id"] = &dynamodb.AttributeValue{S: aws.String(record.OwnerID)}
+	}
+	if !record.ExpiresAt.IsZero() {
+		input.Item["expires_at"] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(record.ExpiresAt.Unix(), 10))}
+	}
+	_, err := s.client.PutItemWithContext(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to put report record in DynamoDB: %w", err)
+	}
+	return nil
+}
+
+// Get returns the record of a report
+func (s *DynamoStore) Get(ctx context.Context, reportID string) (Record, error) {
+	result, err := s.client.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.tableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"ReportID": {S: aws.String(reportID)},
+		},
+	})
+	if err != nil {
+		return Record{}, fmt.Errorf("failed to get report record from DynamoDB: %w", err)
+	}
+	if len(result.Item) == 0 {
+		return Record{}, ErrNotFound
+	}
+
+	item := result.Item
+	record := Record{
+		ReportID:        reportID,
+		OwnerID:         stringAttr(item, "owner_id"),
+		Latitude:        numberAttr(item, "latitude"),
+		Longitude:       numberAttr(item, "longitude"),
+		Format:          stringAttr(item, "format"),
+		Language:        stringAttr(item, "language"),
+		TemplateVersion: stringAttr(item, "template_version"),
+		Size:            int64(numberAttr(item, "size")),
+		Checksum:        stringAttr(item, "checksum"),
+		StorageKey:      stringAttr(item, "storage_key"),
+	}
+	if v, ok := item["plant_ids"]; ok && v != nil {
+		for _, id := range v.L {
+			record.PlantIDs = append(record.PlantIDs, aws.StringValue(id.S))
+		}
+	}
+	record.CreatedAt, _ = time.Parse(time.RFC3339Nano, stringAttr(item, "created_at"))
+	if expires := int64(numberAttr(item, "expires_at")); expires > 0 {
+		record.ExpiresAt = time.Unix(expires, 0).UTC()
+	}
+	return record, nil
+}
+
+// StorageKeys scans the table for the storage keys of every record not expired at now
+func (s *DynamoStore) StorageKeys(ctx context.Context, now time.Time) (map[string]bool, error) {
+	keys := make(map[string]bool)
+	err := s.client.ScanPagesWithContext(ctx, &dynamodb.ScanInput{
+		TableName:            aws.String(s.tableName),
+		ProjectionExpression: aws.String("storage_key, expires_at"),
+	}, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		for _, item := range page.Items {
+			expires := int64(numberAttr(item, "expires_at"))
+			if expires == 0 || now.Unix() < expires {
+				keys[stringAttr(item, "storage_key")] = true
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan report records in DynamoDB: %w", err)
+	}
+	return keys, nil
+}
+
+func stringList(values []string) []*dynamodb.AttributeValue {
+	list := make([]*dynamodb.AttributeValue, len(values))
+	for i, v := range values {
+		list[i] = &dynamodb.AttributeValue{S: aws.String(v)}
+	}
+	return list
+}
+
+func stringAttr(item map[string]*dynamodb.AttributeValue, name string) string {
+	if v, ok := item[name]; ok && v != nil {
+		return aws.StringValue(v.S)
+	}
+	return ""
+}
+
+func numberAttr(item map[string]*dynamodb.AttributeValue, name string) float64 {
+	if v, ok := item[name]; ok && v != nil && v.N != nil {
+		f, _ := strconv.ParseFloat(*v.N, 64)
+		return f
+	}
+	return 0
+}
@@ -0,0 +1,153 @@
+package reports
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/HealthyTechGuy/plant-report-app/internal/plant-service/mocks"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var created = time.Date(2026, time.March, 3, 9, 30, 0, 0, time.UTC)
+
+var record = Record{
+	ReportID:        "r1",
+	OwnerID:         "client:acme",
+	PlantIDs:        []string{"tomato", "kale"},
+	Latitude:        51.51,
+	Longitude:       -0.13,
+	Format:          "pdf",
+	Language:        "en",
+	TemplateVersion: "1",
+	Size:            48213,
+	Checksum:        "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+	StorageKey:      "reports/r1.pdf",
+	CreatedAt:       created,
+	ExpiresAt:       created.AddDate(0, 0, 30),
+}
+
+func TestRoundLocation(t *testing.T) {
+	lat, lon := RoundLocation(51.50735, -0.12776)
+	assert.Equal(t, 51.51, lat)
+	assert.Equal(t, -0.13, lon)
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	require.NoError(t, store.Put(context.TODO(), record))
+
+	got, err := store.Get(context.TODO(), "r1")
+	require.NoError(t, err)
+	assert.Equal(t, record, got)
+
+	_, err = store.Get(context.TODO(), "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestMemoryStore_StorageKeys(t *testing.T) {
+	store := NewMemoryStore()
+	require.NoError(t, store.Put(context.TODO(), record))
+	require.NoError(t, store.Put(context.TODO(), Record{ReportID: "r2", StorageKey: "reports/r2.pdf"}))
+
+	keys, err := store.StorageKeys(context.TODO(), created.AddDate(0, 0, 29))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"reports/r1.pdf": true, "reports/r2.pdf": true}, keys)
+
+	// Records without an expiry are kept forever
+	keys, err = store.StorageKeys(context.TODO(), created.AddDate(0, 0, 30))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"reports/r2.pdf": true}, keys)
+}
+
+func TestDynamoStore_Put(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDynamoDB := mocks.NewMockDynamoDBAPI(ctrl)
+	store := NewDynamoStore(mockDynamoDB, "reports")
+
+	mockDynamoDB.EXPECT().PutItemWithContext(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ aws.Context, input *dynamodb.PutItemInput, _ ...interface{}) (*dynamodb.PutItemOutput, error) {
+			assert.Equal(t, "reports", aws.StringValue(input.TableName))
+			assert.Equal(t, "r1", aws.StringValue(input.Item["ReportID"].S))
+			assert.Equal(t, "client:acme", aws.StringValue(input.Item["owner_id"].S))
+			require.Len(t, input.Item["plant_ids"].L, 2)
+			assert.Equal(t, "kale", aws.StringValue(input.Item["plant_ids"].L[1].S))
+			assert.Equal(t, "51.51", aws.StringValue(input.Item["latitude"].N))
+			assert.Equal(t, "48213", aws.StringValue(input.Item["size"].N))
+			assert.Equal(t, "2026-03-03T09:30:00Z", aws.StringValue(input.Item["created_at"].S))
+			assert.Equal(t, "1775122200", aws.StringValue(input.Item["expires_at"].N))
+			return &dynamodb.PutItemOutput{}, nil
+		})
+
+	assert.NoError(t, store.Put(context.TODO(), record))
+}
+
+func TestDynamoStore_Get(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDynamoDB := mocks.NewMockDynamoDBAPI(ctrl)
+	store := NewDynamoStore(mockDynamoDB, "reports")
+
+	mockDynamoDB.EXPECT().GetItemWithContext(gomock.Any(), &dynamodb.GetItemInput{
+		TableName: aws.String("reports"),
+		Key:       map[string]*dynamodb.AttributeValue{"ReportID": {S: aws.String("r1")}},
+	}).Return(&dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{
+		"ReportID": {S: aws.String("r1")},
+		"owner_id": {S: aws.String("client:acme")},
+		"plant_ids": {L: []*dynamodb.AttributeValue{
+			{S: aws.String("tomato")},
+			{S: aws.String("kale")},
+		}},
+		"latitude":         {N: aws.String("51.51")},
+		"longitude":        {N: aws.String("-0.13")},
+		"format":           {S: aws.String("pdf")},
+		"language":         {S: aws.String("en")},
+		"template_version": {S: aws.String("1")},
+		"size":             {N: aws.String("48213")},
+		"checksum":         {S: aws.String(record.Checksum)},
+		"storage_key":      {S: aws.String("reports/r1.pdf")},
+		"created_at":       {S: aws.String("2026-03-03T09:30:00Z")},
+		"expires_at":       {N: aws.String("1775122200")},
+	}}, nil)
+	mockDynamoDB.EXPECT().GetItemWithContext(gomock.Any(), gomock.Any()).Return(&dynamodb.GetItemOutput{}, nil)
+
+	got, err := store.Get(context.TODO(), "r1")
+	require.NoError(t, err)
+	assert.Equal(t, record, got)
+
+	_, err = store.Get(context.TODO(), "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestDynamoStore_StorageKeys(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDynamoDB := mocks.NewMockDynamoDBAPI(ctrl)
+	store := NewDynamoStore(mockDynamoDB, "reports")
+
+	mockDynamoDB.EXPECT().ScanPagesWithContext(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ aws.Context, input *dynamodb.ScanInput, fn func(*dynamodb.ScanOutput, bool) bool, _ ...interface{}) error {
+			assert.Equal(t, "reports", aws.StringValue(input.TableName))
+			// Expired records linger until the table's TTL removes them
+			fn(&dynamodb.ScanOutput{Items: []map[string]*dynamodb.AttributeValue{
+				{"storage_key": {S: aws.String("reports/r1.pdf")}, "expires_at": {N: aws.String("1775122200")}},
+				{"storage_key": {S: aws.String("reports/r2.pdf")}, "expires_at": {N: aws.String("1772530200")}},
+			}}, false)
+			fn(&dynamodb.ScanOutput{Items: []map[string]*dynamodb.AttributeValue{
+				{"storage_key": {S: aws.String("reports/r3.pdf")}},
+			}}, true)
+			return nil
+		})
+
+	keys, err := store.StorageKeys(context.TODO(), created.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"reports/r1.pdf": true, "reports/r3.pdf": true}, keys)
+}
@@ -0,0 +1,193 @@
+// Package retention decides how long reports are kept for each client tier and removes stored
+// reports whose metadata records have expired
+package retention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HealthyTechGuy/plant-report-app/internal/reports"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/storage"
+	"go.uber.org/zap"
+)
+
+// DefaultTier names the policy entry for tiers without one of their own
+const DefaultTier = "*"
+
+// MaxDays is the longest retention a tier may have, it bounds the bucket's backstop expiry
+const MaxDays = 3650
+
+// DefaultGracePeriod is how old an object must be before Cleaner may delete it
+const DefaultGracePeriod = 24 * time.Hour
+
+// DefaultPrefixes are the storage prefixes holding reports that have metadata records
+var DefaultPrefixes = []string{"reports/", "users/"}
+
+// summarySuffix marks the JSON summary the report cache stores next to each cached report
+const summarySuffix = ".json"
+
+// Policy maps client tiers to how many days their reports are kept
+type Policy map[string]int
+
+// ParsePolicy parses tier=days entries such as free=7 and partner=90. The * entry applies to
+// tiers without an entry of their own, and to requests without a client.
+func ParsePolicy(entries []string) (Policy, error) {
+	policy := make(Policy, len(entries))
+	for _, entry := range entries {
+		tier, rawDays, ok := strings.Cut(entry, "=")
+		tier = strings.TrimSpace(tier)
+		days, err := strconv.Atoi(strings.TrimSpace(rawDays))
+		if !ok || tier == "" || err != nil || days < 1 || days > MaxDays {
+			return nil, fmt.Errorf("invalid retention %q: must be tier=days with 1 to %d days", entry, MaxDays)
+		}
+		if _, ok := policy[tier]; ok {
+			return nil, fmt.Errorf("invalid retention %q: tier %s is listed twice", entry, tier)
+		}
+		policy[tier] = days
+	}
+	return policy, nil
+}
+
+// Days returns how many days a tier's reports are kept, 0 means forever
+func (p Policy) Days(tier string) int {
+	if days, ok := p[tier]; ok {
+		return days
+	}
+	return p[DefaultTier]
+}
+
+// ExpiresAt returns when a report created at created for tier expires, zero when it never does
+func (p Policy) ExpiresAt(tier string, created time.Time) time.Time {
+	days := p.Days(tier)
+	if days == 0 {
+		return time.Time{}
+	}
+	return created.AddDate(0, 0, days)
+}
+
+// Cleaner deletes stored reports that no unexpired metadata record refers to. Every report is
+// recorded once it is stored, so an object without a record is either expired or was never
+// recorded, and objects younger than the grace period are left alone in case their record is
+// still being written.
+type Cleaner struct {
+	store       storage.Store
+	records     reports.Store
+	prefixes    []string
+	gracePeriod time.Duration
+	dryRun      bool
+	logger      *zap.Logger
+	clock       func() time.Time
+}
+
+// Option configures a Cleaner
+type Option func(*Cleaner)
+
+// WithPrefixes replaces DefaultPrefixes as the storage prefixes that are cleaned
+func WithPrefixes(prefixes ...string) Option {
+	return func(c *Cleaner) { c.prefixes = prefixes }
+}
+
+// WithGracePeriod replaces DefaultGracePeriod
+func WithGracePeriod(d time.Duration) Option {
+	return func(c *Cleaner) { c.gracePeriod = d }
+}
+
+// DryRun makes the Cleaner report what it would delete without deleting anything
+func DryRun() Option {
+	return func(c *Cleaner) { c.dryRun = true }
+}
+
+// WithClock replaces time.Now
+func WithClock(clock func() time.Time) Option {
+	return func(c *Cleaner) { c.clock = clock }
+}
+
+// NewCleaner creates a Cleaner for the reports in store recorded in records
+func NewCleaner(store storage.Store, records reports.Store, logger *zap.Logger, opts ...Option) *Cleaner {
+	c := &Cleaner{
+		store:       store,
+		records:     records,
+		prefixes:    DefaultPrefixes,
+		gracePeriod: DefaultGracePeriod,
+		logger:      logger,
+		clock:       time.Now,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.logger == nil {
+		c.logger = zap.NewNop()
+	}
+	return c
+}
+
+// Result summarises a cleanup run
+type Result struct {
+	Scanned int `json:"scanned"`
+	// Deleted lists the keys of the orphaned objects, removed unless the run was a dry run
+	Deleted []string `json:"deleted"`
+	// Failed counts orphaned objects that could not be deleted
+	Failed int `json:"failed"`
+}
+
+// Run deletes every orphaned object under the cleaner's prefixes. Failures to delete single
+// objects are logged and counted, the run carries on with the rest.
+func (c *Cleaner) Run(ctx context.Context) (Result, error) {
+	now := c.clock()
+	live, err := c.records.StorageKeys(ctx, now)
+	if err != nil {
+		return Result{}, err
+	}
+
+	var orphans []string
+	result := Result{Deleted: []string{}}
+	for _, prefix := range c.prefixes {
+		objects, err := c.store.List(ctx, prefix)
+		if err != nil {
+			return result, err
+		}
+		for _, obj := range objects {
+			result.Scanned++
+			// A cached report's summary lives and dies with the report
+			if live[obj.Key] || live[strings.TrimSuffix(obj.Key, summarySuffix)] {
+				continue
+			}
+			if now.Sub(obj.LastModified) >= c.gracePeriod {
+				orphans = append(orphans, obj.Key)
+			}
+		}
+	}
+
+	// Going backwards deletes a cached report's summary before the report, so the cache never
+	// holds a summary whose report is gone
+	sort.Strings(orphans)
+	for i := len(orphans) - 1; i >= 0; i-- {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		if !c.dryRun {
+			if err := c.store.Delete(ctx, orphans[i]); err != nil {
+				c.logger.Warn("error deleting orphaned report", zap.String("key", orphans[i]), zap.Error(err))
+				result.Failed++
+				continue
+			}
+		}
+		result.Deleted = append(result.Deleted, orphans[i])
+	}
+	sort.Strings(result.Deleted)
+
+	c.logger.Info("cleaned up orphaned reports",
+		zap.Int("scanned", result.Scanned),
+		zap.Int("deleted", len(result.Deleted)),
+		zap.Int("failed", result.Failed),
+		zap.Bool("dry_run", c.dryRun))
+	if result.Failed > 0 {
+		return result, errors.New("failed to delete some orphaned reports")
+	}
+	return result, nil
+}
@@ -0,0 +1,131 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HealthyTechGuy/plant-report-app/internal/reports"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2026, time.March, 3, 9, 30, 0, 0, time.UTC)
+
+func TestParsePolicy(t *testing.T) {
+	policy, err := ParsePolicy([]string{"free=7", " partner = 90", "*=30"})
+	require.NoError(t, err)
+	assert.Equal(t, 7, policy.Days("free"))
+	assert.Equal(t, 90, policy.Days("partner"))
+	assert.Equal(t, 30, policy.Days("user"))
+	assert.Equal(t, 30, policy.Days(""))
+	assert.Equal(t, now.AddDate(0, 0, 7), policy.ExpiresAt("free", now))
+
+	// Without a * entry, tiers not listed keep their reports
+	policy, err = ParsePolicy([]string{"free=7"})
+	require.NoError(t, err)
+	assert.True(t, policy.ExpiresAt("partner", now).IsZero())
+
+	for _, entries := range [][]string{{"free"}, {"free=0"}, {"free=soon"}, {"=7"}, {"free=7", "free=8"}, {"free=3651"}} {
+		_, err := ParsePolicy(entries)
+		assert.Error(t, err, entries)
+	}
+}
+
+// failingDeletes refuses to delete the keys in refuse
+type failingDeletes struct {
+	*storage.MemoryStore
+	refuse map[string]bool
+}
+
+func (s failingDeletes) Delete(ctx context.Context, key string) error {
+	if s.refuse[key] {
+		return errors.New("access denied")
+	}
+	return s.MemoryStore.Delete(ctx, key)
+}
+
+// seed stores objects last modified at modified
+func seed(t *testing.T, store *storage.MemoryStore, modified time.Time, keys ...string) {
+	t.Helper()
+	for _, key := range keys {
+		require.NoError(t, store.Put(context.TODO(), key, strings.NewReader("%PDF"), "application/pdf"))
+		store.SetModified(key, modified)
+	}
+}
+
+func TestCleaner_Run(t *testing.T) {
+	store := storage.NewMemoryStore()
+	records := reports.NewMemoryStore()
+	old := now.Add(-48 * time.Hour)
+	seed(t, store, old,
+		"reports/live.pdf",
+		"reports/expired.pdf",
+		"reports/orphan.pdf",
+		"reports/kale/1/f/c/2026-03-01/metric.pdf",
+		"reports/kale/1/f/c/2026-03-01/metric.pdf.json",
+		"reports/kale/1/f/c/2026-02-01/metric.pdf",
+		"reports/kale/1/f/c/2026-02-01/metric.pdf.json",
+		"users/u1/expired.pdf",
+		"batches/incoming/june.csv",
+	)
+	// Written moments ago, its record may not be stored yet
+	seed(t, store, now.Add(-time.Minute), "reports/in-flight.pdf")
+
+	require.NoError(t, records.Put(context.TODO(), reports.Record{ReportID: "live", StorageKey: "reports/live.pdf", ExpiresAt: now.Add(time.Hour)}))
+	require.NoError(t, records.Put(context.TODO(), reports.Record{ReportID: "expired", StorageKey: "reports/expired.pdf", ExpiresAt: now}))
+	require.NoError(t, records.Put(context.TODO(), reports.Record{ReportID: "cached", StorageKey: "reports/kale/1/f/c/2026-03-01/metric.pdf"}))
+	require.NoError(t, records.Put(context.TODO(), reports.Record{ReportID: "user", StorageKey: "users/u1/expired.pdf", ExpiresAt: old}))
+
+	cleaner := NewCleaner(store, records, nil, WithClock(func() time.Time { return now }))
+	result, err := cleaner.Run(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, 9, result.Scanned)
+	assert.Equal(t, []string{
+		"reports/expired.pdf",
+		"reports/kale/1/f/c/2026-02-01/metric.pdf",
+		"reports/kale/1/f/c/2026-02-01/metric.pdf.json",
+		"reports/orphan.pdf",
+		"users/u1/expired.pdf",
+	}, result.Deleted)
+
+	objects, err := store.List(context.TODO(), "")
+	require.NoError(t, err)
+	var kept []string
+	for _, obj := range objects {
+		kept = append(kept, obj.Key)
+	}
+	assert.Equal(t, []string{
+		"batches/incoming/june.csv",
+		"reports/in-flight.pdf",
+		"reports/kale/1/f/c/2026-03-01/metric.pdf",
+		"reports/kale/1/f/c/2026-03-01/metric.pdf.json",
+		"reports/live.pdf",
+	}, kept)
+}
+
+func TestCleaner_DryRun(t *testing.T) {
+	store := storage.NewMemoryStore()
+	seed(t, store, now.Add(-48*time.Hour), "reports/orphan.pdf")
+
+	cleaner := NewCleaner(store, reports.NewMemoryStore(), nil, DryRun(), WithClock(func() time.Time { return now }))
+	result, err := cleaner.Run(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"reports/orphan.pdf"}, result.Deleted)
+	assert.Len(t, store.Keys(), 1)
+}
+
+func TestCleaner_DeleteFailures(t *testing.T) {
+	store := storage.NewMemoryStore()
+	seed(t, store, now.Add(-48*time.Hour), "reports/a.pdf", "reports/b.pdf")
+
+	cleaner := NewCleaner(failingDeletes{MemoryStore: store, refuse: map[string]bool{"reports/a.pdf": true}},
+		reports.NewMemoryStore(), nil, WithClock(func() time.Time { return now }))
+	result, err := cleaner.Run(context.TODO())
+	assert.Error(t, err)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, []string{"reports/b.pdf"}, result.Deleted)
+}
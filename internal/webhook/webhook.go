@@ -0,0 +1,279 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/HealthyTechGuy/plant-report-app/pkg/suitability"
+	"go.uber.org/zap"
+)
+
+// Events sent to callback URLs
+const (
+	EventReportCompleted = "report.completed"
+	EventReportFailed    = "report.failed"
+)
+
+// Headers carrying the signature of each delivery
+const (
+	SignatureHeader = "X-Plant-Report-Signature"
+	TimestampHeader = "X-Plant-Report-Timestamp"
+	DeliveryHeader  = "X-Plant-Report-Delivery"
+)
+
+// ErrInvalidURL is returned for callback URLs that are not absolute http(s) URLs
+var ErrInvalidURL = errors.New("invalid callback URL")
+
+// errPrivateAddress is returned when a callback URL resolves to an internal address
+var errPrivateAddress = errors.New("callback URL resolves to a private address")
+
+// Payload is the JSON body POSTed to a callback URL
+type Payload struct {
+	// DeliveryID is unique per event and repeated on retries, so receivers can drop duplicates
+	DeliveryID  string                  `json:"delivery_id"`
+	Event       string                  `json:"event"`
+	ReportID    string                  `json:"report_id,omitempty"`
+	PlantID     string                  `json:"plant_id"`
+	PDFUrl      string                  `json:"pdf_url,omitempty"`
+	Suitability *suitability.Assessment `json:"suitability,omitempty"`
+	// Error describes why the report failed
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RetryPolicy controls how failed deliveries are retried
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first
+	MaxAttempts int
+	// BaseDelay is the backoff before the first retry, doubled for each retry after that
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff between attempts
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy retries up to three times, backing off from 500ms to at most 4s
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    4 * time.Second,
+}
+
+// backoff returns a full-jitter delay before the given retry (1 for the first retry)
+func (p RetryPolicy) backoff(retry int, random func() float64) time.Duration {
+	ceiling := p.MaxDelay
+	if shift := retry - 1; shift < 32 {
+		if d := p.BaseDelay << shift; d > 0 && d < p.MaxDelay {
+			ceiling = d
+		}
+	}
+	return time.Duration(random() * float64(ceiling))
+}
+
+// attemptTimeout bounds each delivery attempt
+const attemptTimeout = 5 * time.Second
+
+// Sender POSTs signed payloads to callback URLs
+type Sender struct {
+	client       *http.Client
+	retry        RetryPolicy
+	allowPrivate bool
+	logger       *zap.Logger
+	now          func() time.Time
+	sleep        func(ctx context.Context, d time.Duration) error
+	random       func() float64
+}
+
+// Option configures optional Sender behaviour
+type Option func(*Sender)
+
+// WithRetryPolicy overrides DefaultRetryPolicy
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(s *Sender) {
+		s.retry = policy
+	}
+}
+
+// AllowPrivateNetworks lets callbacks reach loopback and private addresses, for tests and local development
+func AllowPrivateNetworks() Option {
+	return func(s *Sender) {
+		s.allowPrivate = true
+	}
+}
+
+// NewSender creates a Sender. Every attempt is logged to logger for audit; a nil logger logs nothing.
+// Unless AllowPrivateNetworks is given, callbacks to loopback, private and link-local addresses
+// are refused when connecting, so a callback URL cannot be used to probe our own network.
+func NewSender(logger *zap.Logger, opts ...Option) *Sender {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	s := &Sender{
+		retry:  DefaultRetryPolicy,
+		logger: logger,
+		now:    time.Now,
+		sleep:  sleepContext,
+		random: rand.Float64,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	dialer := &net.Dialer{Timeout: attemptTimeout}
+	if !s.allowPrivate {
+		dialer.Control = refusePrivate
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	s.client = &http.Client{
+		Transport: transport,
+		Timeout:   attemptTimeout,
+		// Redirects could lead anywhere, receivers must answer at the URL they gave us
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	return s
+}
+
+// ValidateURL checks that a callback URL is an absolute http(s) URL without credentials
+func ValidateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" || u.User != nil {
+		return fmt.Errorf("%w: must be an absolute http(s) URL", ErrInvalidURL)
+	}
+	return nil
+}
+
+// Sign returns the signature of a delivery: the hex HMAC-SHA256 of the timestamp, a dot and the
+// body, keyed with the client's secret, prefixed with "sha256=". Receivers recompute it to check
+// the payload came from us, and reject old timestamps to stop replays.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is valid for the body and timestamp
+func Verify(secret, timestamp string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// Deliver POSTs payload to callbackURL signed with secret. Network errors, 429 and 5xx responses
+// are retried with jittered exponential backoff; other responses are final. Any 2xx is success.
+func (s *Sender) Deliver(ctx context.Context, callbackURL, secret string, payload Payload) error {
+	if err := ValidateURL(callbackURL); err != nil {
+		return err
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= s.retry.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			if err := s.sleep(ctx, s.retry.backoff(attempt-1, s.random)); err != nil {
+				break
+			}
+		}
+
+		start := s.now()
+		status, err := s.post(ctx, callbackURL, secret, payload.DeliveryID, body)
+		retry := err != nil || status == http.StatusTooManyRequests || status >= 500
+		if err == nil && (status < 200 || status > 299) {
+			err = fmt.Errorf("callback answered %d", status)
+		}
+
+		fields := []zap.Field{
+			zap.String("delivery_id", payload.DeliveryID),
+			zap.String("event", payload.Event),
+			zap.String("report_id", payload.ReportID),
+			zap.String("callback", redactURL(callbackURL)),
+			zap.Int("attempt", attempt),
+			zap.Int("status_code", status),
+			zap.Duration("duration", s.now().Sub(start)),
+		}
+		if err == nil {
+			s.logger.Info("webhook delivered", fields...)
+			return nil
+		}
+		s.logger.Warn("webhook delivery failed", append(fields, zap.Bool("will_retry", retry && attempt < s.retry.MaxAttempts), zap.Error(err))...)
+
+		lastErr = err
+		if !retry || errors.Is(err, errPrivateAddress) {
+			break
+		}
+	}
+	return fmt.Errorf("failed to deliver webhook: %w", lastErr)
+}
+
+// post makes one delivery attempt and returns the response status
+func (s *Sender) post(ctx context.Context, callbackURL, secret, deliveryID string, body []byte) (int, error) {
+	timestamp := strconv.FormatInt(s.now().Unix(), 10)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, callbackURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "plant-report-webhooks/1")
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(secret, timestamp, body))
+	req.Header.Set(DeliveryHeader, deliveryID)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return resp.StatusCode, nil
+}
+
+// refusePrivate is a net.Dialer Control func refusing connections to internal addresses. It runs
+// after DNS resolution, so a public name pointing at a private address is refused too.
+func refusePrivate(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+		return fmt.Errorf("%w: %s", errPrivateAddress, host)
+	}
+	return nil
+}
+
+// redactURL drops the query string, which may carry the receiver's own tokens, from audit logs
+func redactURL(raw string) string {
+	if i := strings.IndexAny(raw, "?#"); i >= 0 {
+		return raw[:i]
+	}
+	return raw
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
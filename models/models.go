@@ -1,5 +1,16 @@
 package models
 
+import (
+	"time"
+
+	"github.com/HealthyTechGuy/plant-report-app/pkg/climate"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/companion"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/frost"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/pests"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/suitability"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/units"
+)
+
 // PlantInfo holds information about a plant
 type PlantInfo struct {
 	ID              string
@@ -7,6 +18,30 @@ type PlantInfo struct {
 	GrowingPeriod   string
 	OptimalPlanting string
 	HardinessZone   string
+	Spacing         units.Length
+	PlantingDepth   units.Length
+	MinTemperature  units.Temperature
+	MaxTemperature  units.Temperature
+	AnnualRainfall  units.Length
+	DaysToMaturity  int
+	FrostTolerance  frost.Tolerance
+	ChillHours      int
+	Category        string
+	Uses            []string
+	// Companions are the good and bad neighbours recorded on the plant. A relationship may be
+	// recorded on either plant of a pair, so use a companion.Graph to find all of them.
+	Companions []companion.Link
+}
+
+// Requirements returns the climate requirements used to score the plant against a location
+func (p PlantInfo) Requirements() suitability.Requirements {
+	return suitability.Requirements{
+		HardinessZone:  p.HardinessZone,
+		ChillHours:     p.ChillHours,
+		MaxTemperature: p.MaxTemperature,
+		AnnualRainfall: p.AnnualRainfall,
+		DaysToMaturity: p.DaysToMaturity,
+	}
 }
 
 type UserLocation struct {
@@ -21,11 +56,81 @@ type Request struct {
 		Longitude float64 `json:"longitude"`
 	} `json:"location"`
 	PlantID string `json:"plant_id"`
+	Units   string `json:"units,omitempty"`
+	// Download asks for the PDF itself rather than a link to it
+	Download bool `json:"download,omitempty"`
+	// Email also sends the report to this address
+	Email string `json:"email,omitempty"`
+	// CallbackURL is POSTed a signed notification when the report is ready or has failed
+	CallbackURL string `json:"callback_url,omitempty"`
+	// Neighbours are the IDs of other plants grown alongside plant_id, checked for bad pairs
+	Neighbours []string `json:"neighbours,omitempty"`
+}
+
+// Alternative is a plant recommended in place of one that suits the location poorly
+type Alternative struct {
+	PlantID string              `json:"plant_id"`
+	Name    string              `json:"name"`
+	Score   int                 `json:"score"`
+	Verdict suitability.Verdict `json:"verdict"`
+	Reason  string              `json:"reason"`
+}
+
+// Report bundles everything needed to render a plant report
+type Report struct {
+	Location     UserLocation
+	Plant        PlantInfo
+	Units        units.System
+	Climate      *climate.Normals
+	Schedule     *frost.Schedule
+	Suitability  *suitability.Assessment
+	Alternatives []Alternative
+	Companions   *companion.Plan
+	PestRisks    []pests.Risk
+
+	// LocationRounding is the grid cell size in degrees the location was rounded to, zero when exact
+	LocationRounding float64
 }
 
 // Response represents the response returned by the Lambda function
 type Response struct {
-	Message string `json:"message"`
-	PDFUrl  string `json:"pdf_url"`
-	Error   string `json:"error,omitempty"`
+	Message      string                  `json:"message"`
+	ReportID     string                  `json:"report_id,omitempty"`
+	PDFUrl       string                  `json:"pdf_url"`
+	Suitability  *suitability.Assessment `json:"suitability,omitempty"`
+	Alternatives []Alternative           `json:"alternatives,omitempty"`
+	// EmailStatus is "sent" or "failed" when the report was emailed
+	EmailStatus string `json:"email_status,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// ReportSummary describes a previously generated report with a fresh download link
+type ReportSummary struct {
+	ReportID    string    `json:"report_id"`
+	PlantID     string    `json:"plant_id"`
+	Latitude    float64   `json:"latitude"`
+	Longitude   float64   `json:"longitude"`
+	CreatedAt   time.Time `json:"created_at"`
+	DownloadURL string    `json:"download_url"`
+	EmailStatus string    `json:"email_status,omitempty"`
+}
+
+// ReportHistoryResponse is the body returned by GET /me/reports
+type ReportHistoryResponse struct {
+	Reports []ReportSummary `json:"reports"`
+}
+
+// ReportMetadata is the body returned by GET /report/{id}
+type ReportMetadata struct {
+	ReportID        string    `json:"report_id"`
+	PlantIDs        []string  `json:"plant_ids"`
+	Latitude        float64   `json:"latitude"`
+	Longitude       float64   `json:"longitude"`
+	Format          string    `json:"format"`
+	Language        string    `json:"language"`
+	TemplateVersion string    `json:"template_version"`
+	Size            int64     `json:"size"`
+	Checksum        string    `json:"checksum"`
+	CreatedAt       time.Time `json:"created_at"`
+	DownloadURL     string    `json:"download_url"`
 }
@@ -0,0 +1,70 @@
+package apigw
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/HealthyTechGuy/plant-report-app/models"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// HandlerFunc is the signature shared by API Gateway proxy handlers such as App.HandleRequest
+type HandlerFunc func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
+
+// Middleware wraps a handler with extra behaviour such as authentication
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// Chain wraps h in the middleware, the first middleware being the outermost
+func Chain(h HandlerFunc, middleware ...Middleware) HandlerFunc {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		h = middleware[i](h)
+	}
+	return h
+}
+
+// Header returns a request header, matching the name case-insensitively as HTTP requires
+func Header(request events.APIGatewayProxyRequest, name string) string {
+	if v, ok := request.Headers[name]; ok {
+		return v
+	}
+	for key, v := range request.Headers {
+		if strings.EqualFold(key, name) {
+			return v
+		}
+	}
+	for key, values := range request.MultiValueHeaders {
+		if strings.EqualFold(key, name) && len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+// ContentTypeJSON is the Content-Type of every JSON response
+const ContentTypeJSON = "application/json"
+
+// JSON creates a response with v encoded as the JSON body, Content-Type set and optional extra headers
+func JSON(statusCode int, v any, headers map[string]string) events.APIGatewayProxyResponse {
+	body, err := json.Marshal(v)
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		body, _ = json.Marshal(models.Response{Message: "Failed to encode response"})
+	}
+	responseHeaders := make(map[string]string, len(headers)+1)
+	for name, value := range headers {
+		responseHeaders[name] = value
+	}
+	responseHeaders["Content-Type"] = ContentTypeJSON
+	return events.APIGatewayProxyResponse{
+		StatusCode: statusCode,
+		Headers:    responseHeaders,
+		Body:       string(body),
+	}
+}
+
+// ErrorResponse creates a JSON error response with optional extra headers
+func ErrorResponse(statusCode int, message string, headers map[string]string) events.APIGatewayProxyResponse {
+	return JSON(statusCode, models.Response{Message: message}, headers)
+}
@@ -0,0 +1,88 @@
+package apigw
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const (
+	corsAllowMethods  = "GET, POST, OPTIONS"
+	corsAllowHeaders  = "Authorization, Content-Type, X-Api-Key"
+	corsExposeHeaders = "Content-Disposition, Retry-After, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, X-Report-Id"
+	// corsMaxAge is how many seconds browsers may cache a preflight answer
+	corsMaxAge = "600"
+)
+
+// CORS lets browser clients on allowed origins call the API directly
+type CORS struct {
+	origins   map[string]bool
+	anyOrigin bool
+}
+
+// NewCORS creates a CORS policy for the given origins, such as https://app.example.com.
+// The origin "*" allows every origin.
+func NewCORS(allowedOrigins []string) *CORS {
+	c := &CORS{origins: make(map[string]bool, len(allowedOrigins))}
+	for _, origin := range allowedOrigins {
+		if origin == "*" {
+			c.anyOrigin = true
+		}
+		c.origins[strings.TrimSuffix(origin, "/")] = true
+	}
+	return c
+}
+
+// Middleware answers preflight requests from allowed origins with 204, and from other origins
+// with 403, without calling next. Other requests from allowed origins get Access-Control-*
+// headers added to whatever next returns, including errors from later middleware. It should
+// be the outermost middleware, since browsers send preflights without credentials.
+func (c *CORS) Middleware(next HandlerFunc) HandlerFunc {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		origin := Header(request, "Origin")
+		preflight := request.HTTPMethod == http.MethodOptions && origin != "" &&
+			Header(request, "Access-Control-Request-Method") != ""
+
+		if preflight {
+			if !c.allows(origin) {
+				return ErrorResponse(http.StatusForbidden, "Origin not allowed", nil), nil
+			}
+			headers := c.headers(origin)
+			headers["Access-Control-Allow-Methods"] = corsAllowMethods
+			headers["Access-Control-Allow-Headers"] = corsAllowHeaders
+			headers["Access-Control-Max-Age"] = corsMaxAge
+			return events.APIGatewayProxyResponse{StatusCode: http.StatusNoContent, Headers: headers}, nil
+		}
+
+		response, err := next(ctx, request)
+		if err != nil || origin == "" || !c.allows(origin) {
+			return response, err
+		}
+		if response.Headers == nil {
+			response.Headers = make(map[string]string)
+		}
+		for name, value := range c.headers(origin) {
+			response.Headers[name] = value
+		}
+		response.Headers["Access-Control-Expose-Headers"] = corsExposeHeaders
+		return response, nil
+	}
+}
+
+func (c *CORS) allows(origin string) bool {
+	return c.anyOrigin || c.origins[origin]
+}
+
+// headers returns the headers naming the allowed origin. Responses that echo a specific origin
+// vary by Origin, so caches must not share them between origins.
+func (c *CORS) headers(origin string) map[string]string {
+	if c.anyOrigin {
+		return map[string]string{"Access-Control-Allow-Origin": "*"}
+	}
+	return map[string]string{
+		"Access-Control-Allow-Origin": origin,
+		"Vary":                        "Origin",
+	}
+}
@@ -0,0 +1,94 @@
+package apigw
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCORS_Preflight(t *testing.T) {
+	t.Parallel()
+	called := false
+	handler := NewCORS([]string{"https://app.example.com"}).Middleware(func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		called = true
+		return ErrorResponse(401, "Missing API key or bearer token", nil), nil
+	})
+
+	preflight := func(origin string) events.APIGatewayProxyResponse {
+		response, err := handler(context.TODO(), events.APIGatewayProxyRequest{
+			HTTPMethod: "OPTIONS",
+			Path:       "/report",
+			Headers: map[string]string{
+				"origin":                         origin,
+				"access-control-request-method":  "POST",
+				"access-control-request-headers": "content-type, x-api-key",
+			},
+		})
+		require.NoError(t, err)
+		return response
+	}
+
+	response := preflight("https://app.example.com")
+	assert.Equal(t, 204, response.StatusCode)
+	assert.Equal(t, "https://app.example.com", response.Headers["Access-Control-Allow-Origin"])
+	assert.Equal(t, "GET, POST, OPTIONS", response.Headers["Access-Control-Allow-Methods"])
+	assert.Contains(t, response.Headers["Access-Control-Allow-Headers"], "X-Api-Key")
+	assert.Equal(t, "Origin", response.Headers["Vary"])
+
+	response = preflight("https://evil.example.com")
+	assert.Equal(t, 403, response.StatusCode)
+	assert.Empty(t, response.Headers["Access-Control-Allow-Origin"])
+
+	// Preflights never reach authentication
+	assert.False(t, called)
+}
+
+func TestCORS_AddsHeadersToResponses(t *testing.T) {
+	t.Parallel()
+	next := func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		return ErrorResponse(429, "Too many requests, please slow down", map[string]string{"Retry-After": "10"}), nil
+	}
+
+	tests := []struct {
+		name    string
+		allowed []string
+		origin  string
+		want    string
+	}{
+		{"allowed origin", []string{"https://app.example.com"}, "https://app.example.com", "https://app.example.com"},
+		{"any origin", []string{"*"}, "https://anyone.example.com", "*"},
+		{"other origin", []string{"https://app.example.com"}, "https://evil.example.com", ""},
+		{"no origin", []string{"https://app.example.com"}, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := NewCORS(tt.allowed).Middleware(next)(context.TODO(), events.APIGatewayProxyRequest{
+				HTTPMethod: "POST",
+				Headers:    map[string]string{"Origin": tt.origin},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, 429, response.StatusCode)
+			assert.Equal(t, "10", response.Headers["Retry-After"])
+			assert.Equal(t, tt.want, response.Headers["Access-Control-Allow-Origin"])
+			if tt.want != "" {
+				assert.Contains(t, response.Headers["Access-Control-Expose-Headers"], "Retry-After")
+			}
+		})
+	}
+}
+
+func TestJSON_SetsContentType(t *testing.T) {
+	t.Parallel()
+	response := JSON(200, map[string]string{"message": "ok"}, map[string]string{"Retry-After": "1"})
+	assert.Equal(t, "application/json", response.Headers["Content-Type"])
+	assert.Equal(t, "1", response.Headers["Retry-After"])
+	assert.JSONEq(t, `{"message":"ok"}`, response.Body)
+
+	response = JSON(200, func() {}, nil)
+	assert.Equal(t, 500, response.StatusCode)
+	assert.Equal(t, "application/json", ErrorResponse(400, "bad", nil).Headers["Content-Type"])
+}
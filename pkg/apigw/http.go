@@ -0,0 +1,80 @@
+package apigw
+
+import (
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// HTTPHandler adapts an API Gateway proxy handler to net/http, so the local server
+// runs exactly the same code as the Lambda
+func HTTPHandler(handle HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "failed to read request body", http.StatusBadRequest)
+			return
+		}
+
+		response, err := handle(r.Context(), proxyRequest(r, body))
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+			return
+		}
+		writeProxyResponse(w, response)
+	})
+}
+
+// proxyRequest builds the API Gateway event an HTTP request would have produced
+func proxyRequest(r *http.Request, body []byte) events.APIGatewayProxyRequest {
+	request := events.APIGatewayProxyRequest{
+		HTTPMethod:        r.Method,
+		Path:              r.URL.Path,
+		Headers:           make(map[string]string, len(r.Header)),
+		MultiValueHeaders: make(map[string][]string, len(r.Header)),
+		Body:              string(body),
+	}
+	for name, values := range r.Header {
+		request.Headers[name] = values[0]
+		request.MultiValueHeaders[name] = values
+	}
+	if query := r.URL.Query(); len(query) > 0 {
+		request.QueryStringParameters = make(map[string]string, len(query))
+		request.MultiValueQueryStringParameters = query
+		for name, values := range query {
+			request.QueryStringParameters[name] = values[0]
+		}
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		request.RequestContext.Identity.SourceIP = host
+	}
+	return request
+}
+
+// writeProxyResponse writes an API Gateway proxy response to w
+func writeProxyResponse(w http.ResponseWriter, response events.APIGatewayProxyResponse) {
+	for name, value := range response.Headers {
+		w.Header().Set(name, value)
+	}
+	for name, values := range response.MultiValueHeaders {
+		for _, value := range values {
+			w.Header().Add(name, value)
+		}
+	}
+
+	body := []byte(response.Body)
+	if response.IsBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(response.Body)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+			return
+		}
+		body = decoded
+	}
+
+	w.WriteHeader(response.StatusCode)
+	_, _ = w.Write(body)
+}
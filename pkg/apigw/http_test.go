@@ -0,0 +1,68 @@
+package apigw
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPHandler_TranslatesRequestAndResponse(t *testing.T) {
+	t.Parallel()
+	var got events.APIGatewayProxyRequest
+	handler := HTTPHandler(func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		got = request
+		return events.APIGatewayProxyResponse{
+			StatusCode: 201,
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       `{"message":"ok"}`,
+		}, nil
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/report?units=metric", strings.NewReader(`{"plant_id":"kale"}`))
+	require.NoError(t, err)
+	req.Header.Set("X-Api-Key", "secret")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.MethodPost, got.HTTPMethod)
+	assert.Equal(t, "/report", got.Path)
+	assert.Equal(t, `{"plant_id":"kale"}`, got.Body)
+	assert.Equal(t, "secret", got.Headers["X-Api-Key"])
+	assert.Equal(t, "metric", got.QueryStringParameters["units"])
+	assert.Equal(t, "127.0.0.1", got.RequestContext.Identity.SourceIP)
+
+	assert.Equal(t, 201, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.Equal(t, `{"message":"ok"}`, string(body))
+}
+
+func TestHTTPHandler_DecodesBase64Bodies(t *testing.T) {
+	t.Parallel()
+	handler := HTTPHandler(func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		return events.APIGatewayProxyResponse{
+			StatusCode:      200,
+			Body:            base64.StdEncoding.EncodeToString([]byte("%PDF-1.3")),
+			IsBase64Encoded: true,
+		}, nil
+	})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/report", nil))
+
+	assert.Equal(t, 200, rec.Code)
+	assert.Equal(t, "%PDF-1.3", rec.Body.String())
+}
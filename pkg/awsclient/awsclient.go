@@ -0,0 +1,99 @@
+package awsclient
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/ses"
+	"github.com/aws/aws-sdk-go/service/ses/sesiface"
+)
+
+// Config holds the settings shared by every AWS client
+type Config struct {
+	// Region is the AWS region, left to the SDK's own resolution when empty
+	Region string
+	// Endpoint overrides the service endpoint, e.g. http://localhost:4566 for LocalStack
+	Endpoint string
+	// MaxRetries is the SDK retry count, -1 keeps the SDK default
+	MaxRetries int
+	// S3ForcePathStyle addresses buckets as endpoint/bucket/key, needed by MinIO and LocalStack
+	S3ForcePathStyle bool
+}
+
+// ObjectURLFunc builds the URL of an object in a bucket
+type ObjectURLFunc func(bucket, key string) string
+
+// DefaultObjectURL returns the virtual-hosted style URL of an object on AWS
+func DefaultObjectURL(bucket, key string) string {
+	return fmt.Sprintf("https://%s.s3.amazonaws.com/%s", bucket, key)
+}
+
+// Factory creates AWS clients from a single shared session, so connections and
+// credentials are reused across Lambda invocations
+type Factory struct {
+	cfg  Config
+	sess *session.Session
+}
+
+// NewFactory creates a Factory, building the shared session once
+func NewFactory(cfg Config) (*Factory, error) {
+	awsCfg := aws.NewConfig()
+	if cfg.Region != "" {
+		awsCfg = awsCfg.WithRegion(cfg.Region)
+	}
+	if cfg.Endpoint != "" {
+		awsCfg = awsCfg.WithEndpoint(cfg.Endpoint)
+	}
+	if cfg.MaxRetries >= 0 {
+		awsCfg = awsCfg.WithMaxRetries(cfg.MaxRetries)
+	}
+
+	sess, err := session.NewSession(awsCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session: %w", err)
+	}
+	return &Factory{cfg: cfg, sess: sess}, nil
+}
+
+// Session returns the shared session
+func (f *Factory) Session() *session.Session {
+	return f.sess
+}
+
+// DynamoDB returns a DynamoDB client, with optional per-client overrides
+func (f *Factory) DynamoDB(overrides ...*aws.Config) dynamodbiface.DynamoDBAPI {
+	return dynamodb.New(f.sess, overrides...)
+}
+
+// S3 returns an S3 client, with optional per-client overrides
+func (f *Factory) S3(overrides ...*aws.Config) s3iface.S3API {
+	cfgs := append([]*aws.Config{aws.NewConfig().WithS3ForcePathStyle(f.cfg.S3ForcePathStyle)}, overrides...)
+	return s3.New(f.sess, cfgs...)
+}
+
+// SES returns an SES client, with optional per-client overrides
+func (f *Factory) SES(overrides ...*aws.Config) sesiface.SESAPI {
+	return ses.New(f.sess, overrides...)
+}
+
+// ObjectURL returns the URL of an object, pointing at the endpoint override when one is set
+func (f *Factory) ObjectURL(bucket, key string) string {
+	if f.cfg.Endpoint == "" {
+		return DefaultObjectURL(bucket, key)
+	}
+	endpoint := strings.TrimRight(f.cfg.Endpoint, "/")
+	if f.cfg.S3ForcePathStyle {
+		return fmt.Sprintf("%s/%s/%s", endpoint, bucket, key)
+	}
+	scheme, host, found := strings.Cut(endpoint, "://")
+	if !found {
+		return fmt.Sprintf("%s.%s/%s", bucket, endpoint, key)
+	}
+	return fmt.Sprintf("%s://%s.%s/%s", scheme, bucket, host, key)
+}
@@ -0,0 +1,50 @@
+package awsclient
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/ses"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFactory_ClientsShareSessionAndEndpoint(t *testing.T) {
+	f, err := NewFactory(Config{Region: "us-east-1", Endpoint: "http://localhost:4566", MaxRetries: 1, S3ForcePathStyle: true})
+	require.NoError(t, err)
+
+	ddb := f.DynamoDB(aws.NewConfig().WithMaxRetries(0)).(*dynamodb.DynamoDB)
+	assert.Equal(t, "http://localhost:4566", ddb.Endpoint)
+	assert.Equal(t, 0, ddb.MaxRetries())
+
+	s3Client := f.S3().(*s3.S3)
+	assert.Equal(t, "http://localhost:4566", s3Client.Endpoint)
+	assert.Equal(t, 1, s3Client.MaxRetries())
+	assert.True(t, aws.BoolValue(s3Client.Config.S3ForcePathStyle))
+
+	sesClient := f.SES().(*ses.SES)
+	assert.Equal(t, "http://localhost:4566", sesClient.Endpoint)
+	assert.Same(t, f.Session(), f.Session())
+}
+
+func TestFactory_ObjectURL(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{"aws", Config{Region: "us-east-1"}, "https://plant-report-bucket.s3.amazonaws.com/reports/kale.pdf"},
+		{"path style", Config{Region: "us-east-1", Endpoint: "http://localhost:9000/", S3ForcePathStyle: true}, "http://localhost:9000/plant-report-bucket/reports/kale.pdf"},
+		{"virtual host", Config{Region: "us-east-1", Endpoint: "http://s3.local:4566"}, "http://plant-report-bucket.s3.local:4566/reports/kale.pdf"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewFactory(tt.cfg)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, f.ObjectURL("plant-report-bucket", "reports/kale.pdf"))
+		})
+	}
+}
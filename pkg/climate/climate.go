@@ -0,0 +1,65 @@
+package climate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/HealthyTechGuy/plant-report-app/pkg/frost"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/units"
+)
+
+var (
+	// ErrNoData is returned when no climate data is available for a coordinate
+	ErrNoData = errors.New("no climate data for location")
+)
+
+// ClimateProvider defines the methods for looking up climate normals for a location
+type ClimateProvider interface {
+	Normals(ctx context.Context, latitude, longitude float64) (Normals, error)
+}
+
+// MonthlyNormal holds the long-term averages for one calendar month
+type MonthlyNormal struct {
+	Month    time.Month        `json:"month"`
+	MinTemp  units.Temperature `json:"min_temp_c"`
+	MaxTemp  units.Temperature `json:"max_temp_c"`
+	Rainfall units.Length      `json:"rainfall_mm"`
+}
+
+// MeanTemp returns the average of the monthly minimum and maximum temperatures
+func (m MonthlyNormal) MeanTemp() units.Temperature {
+	return (m.MinTemp + m.MaxTemp) / 2
+}
+
+// Normals holds the climate normals for a location
+type Normals struct {
+	Station   string          `json:"station"`
+	Latitude  float64         `json:"latitude"`
+	Longitude float64         `json:"longitude"`
+	Months    []MonthlyNormal `json:"months"`
+	Frost     frost.Dates     `json:"frost"`
+}
+
+// AnnualRainfall returns the total rainfall over all months
+func (n Normals) AnnualRainfall() units.Length {
+	var total units.Length
+	for _, m := range n.Months {
+		total += m.Rainfall
+	}
+	return total
+}
+
+// Validate checks that the normals cover all twelve months in order
+func (n Normals) Validate() error {
+	if len(n.Months) != 12 {
+		return fmt.Errorf("expected 12 monthly normals, got %d", len(n.Months))
+	}
+	for i, m := range n.Months {
+		if m.Month != time.Month(i+1) {
+			return fmt.Errorf("monthly normal %d has month %d", i+1, m.Month)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package climate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/HealthyTechGuy/plant-report-app/pkg/frost"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddedProvider_NearestStation(t *testing.T) {
+	provider, err := NewEmbeddedProvider()
+	require.NoError(t, err)
+
+	// Brooklyn should resolve to the New York station
+	normals, err := provider.Normals(context.TODO(), 40.65, -73.95)
+	require.NoError(t, err)
+	assert.Equal(t, "New York, NY", normals.Station)
+	assert.Len(t, normals.Months, 12)
+	assert.Equal(t, time.January, normals.Months[0].Month)
+	assert.InDelta(t, 1238, normals.AnnualRainfall().Millimetres(), 1e-9)
+	assert.Equal(t, 91, normals.Frost.LastSpring)
+}
+
+func TestEmbeddedProvider_FrostFromNearestFrostStation(t *testing.T) {
+	provider, err := NewEmbeddedProvider()
+	require.NoError(t, err)
+
+	// Boston has no climate station of its own but does have frost data
+	normals, err := provider.Normals(context.TODO(), 42.36, -71.06)
+	require.NoError(t, err)
+	assert.Equal(t, "New York, NY", normals.Station)
+	assert.Equal(t, 97, normals.Frost.LastSpring)
+	assert.Equal(t, 311, normals.Frost.FirstFall)
+}
+
+func TestEmbeddedProvider_NoData(t *testing.T) {
+	provider, err := NewEmbeddedProvider()
+	require.NoError(t, err)
+
+	// Middle of the Pacific is thousands of kilometres from any station
+	_, err = provider.Normals(context.TODO(), 0, -140)
+	assert.ErrorIs(t, err, ErrNoData)
+}
+
+func TestHTTPProvider_Success(t *testing.T) {
+	provider, err := NewEmbeddedProvider()
+	require.NoError(t, err)
+	expected, err := provider.Normals(context.TODO(), 51.5, -0.1)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/normals", r.URL.Path)
+		assert.Equal(t, "51.5", r.URL.Query().Get("lat"))
+		assert.Equal(t, "-0.1", r.URL.Query().Get("lon"))
+		_ = json.NewEncoder(w).Encode(expected)
+	}))
+	defer server.Close()
+
+	normals, err := NewHTTPProvider(server.URL+"/", nil, nil).Normals(context.TODO(), 51.5, -0.1)
+	require.NoError(t, err)
+	assert.Equal(t, expected, normals)
+}
+
+func TestHTTPProvider_MissingFrostDates(t *testing.T) {
+	provider, err := NewEmbeddedProvider()
+	require.NoError(t, err)
+	london, err := provider.Normals(context.TODO(), 51.5, -0.1)
+	require.NoError(t, err)
+	require.False(t, london.Frost.FrostFree())
+
+	// The service leaves the frost field out
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"station": london.Station, "latitude": london.Latitude, "longitude": london.Longitude, "months": london.Months,
+		})
+	}))
+	defer server.Close()
+
+	estimator, err := frost.NewEstimator()
+	require.NoError(t, err)
+	normals, err := NewHTTPProvider(server.URL, nil, estimator).Normals(context.TODO(), 51.5, -0.1)
+	require.NoError(t, err)
+	assert.Equal(t, london.Frost, normals.Frost)
+
+	// Without an estimator there is nothing to fill the dates in from
+	_, err = NewHTTPProvider(server.URL, nil, nil).Normals(context.TODO(), 51.5, -0.1)
+	assert.Error(t, err)
+}
+
+func TestHTTPProvider_FrostFree(t *testing.T) {
+	provider, err := NewEmbeddedProvider()
+	require.NoError(t, err)
+	expected, err := provider.Normals(context.TODO(), 51.5, -0.1)
+	require.NoError(t, err)
+	expected.Frost = frost.Dates{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(expected)
+	}))
+	defer server.Close()
+
+	estimator, err := frost.NewEstimator()
+	require.NoError(t, err)
+	normals, err := NewHTTPProvider(server.URL, nil, estimator).Normals(context.TODO(), 51.5, -0.1)
+	require.NoError(t, err)
+	assert.True(t, normals.Frost.FrostFree())
+}
+
+func TestHTTPProvider_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		wantErr error
+	}{
+		{
+			name:    "not found",
+			handler: func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNotFound) },
+			wantErr: ErrNoData,
+		},
+		{
+			name:    "server error",
+			handler: func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusInternalServerError) },
+		},
+		{
+			name:    "incomplete normals",
+			handler: func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write([]byte(`{"months":[]}`)) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			_, err := NewHTTPProvider(server.URL, nil, nil).Normals(context.TODO(), 1, 1)
+			require.Error(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package climate
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/HealthyTechGuy/plant-report-app/pkg/frost"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/geo"
+)
+
+//go:embed data/normals.json
+var embeddedNormals []byte
+
+// DefaultMaxDistanceKm is how far away the nearest station may be before a location is treated as having no data
+const DefaultMaxDistanceKm = 1500
+
+// EmbeddedProvider is an offline ClimateProvider backed by a dataset compiled into the binary.
+// Frost dates come from the bundled frost station dataset, which is denser than the climate stations.
+type EmbeddedProvider struct {
+	stations      []Normals
+	frost         *frost.Estimator
+	maxDistanceKm float64
+}
+
+// NewEmbeddedProvider creates an EmbeddedProvider from the bundled station dataset
+func NewEmbeddedProvider() (*EmbeddedProvider, error) {
+	var stations []Normals
+	if err := json.Unmarshal(embeddedNormals, &stations); err != nil {
+		return nil, fmt.Errorf("failed to parse embedded climate data: %w", err)
+	}
+	for _, s := range stations {
+		if err := s.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid embedded climate data for %s: %w", s.Station, err)
+		}
+	}
+	estimator, err := frost.NewEstimator()
+	if err != nil {
+		return nil, err
+	}
+	return &EmbeddedProvider{
+		stations:      stations,
+		frost:         estimator,
+		maxDistanceKm: DefaultMaxDistanceKm,
+	}, nil
+}
+
+// Normals returns the normals of the station nearest to the given coordinate
+func (p *EmbeddedProvider) Normals(ctx context.Context, latitude, longitude float64) (Normals, error) {
+	best := -1
+	bestDistance := math.Inf(1)
+	for i, s := range p.stations {
+		d := geo.DistanceKm(latitude, longitude, s.Latitude, s.Longitude)
+		if d < bestDistance {
+			best, bestDistance = i, d
+		}
+	}
+	if best < 0 || bestDistance > p.maxDistanceKm {
+		return Normals{}, ErrNoData
+	}
+
+	normals := p.stations[best]
+	estimate, err := p.frost.Estimate(latitude, longitude)
+	if err != nil {
+		return Normals{}, fmt.Errorf("failed to estimate frost dates: %w", err)
+	}
+	normals.Frost = estimate.Dates
+	return normals, nil
+}
@@ -0,0 +1,91 @@
+package climate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HealthyTechGuy/plant-report-app/pkg/frost"
+)
+
+// HTTPProvider is a ClimateProvider that fetches normals from a remote service.
+// It calls GET {BaseURL}/normals?lat={latitude}&lon={longitude} and expects a Normals JSON document.
+// Documents without a frost field take their frost dates from the frost estimator instead, an
+// empty frost object means the location is frost-free.
+type HTTPProvider struct {
+	baseURL string
+	client  *http.Client
+	frost   *frost.Estimator
+}
+
+// NewHTTPProvider creates an HTTPProvider for the given base URL. estimator fills in frost dates
+// the service leaves out, with a nil estimator such normals are rejected.
+func NewHTTPProvider(baseURL string, client *http.Client, estimator *frost.Estimator) *HTTPProvider {
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Second}
+	}
+	return &HTTPProvider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  client,
+		frost:   estimator,
+	}
+}
+
+// Normals fetches the climate normals for the given coordinate
+func (p *HTTPProvider) Normals(ctx context.Context, latitude, longitude float64) (Normals, error) {
+	query := url.Values{}
+	query.Set("lat", strconv.FormatFloat(latitude, 'f', -1, 64))
+	query.Set("lon", strconv.FormatFloat(longitude, 'f', -1, 64))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/normals?"+query.Encode(), nil)
+	if err != nil {
+		return Normals{}, fmt.Errorf("failed to build climate request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return Normals{}, fmt.Errorf("failed to fetch climate normals: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return Normals{}, ErrNoData
+	case resp.StatusCode != http.StatusOK:
+		return Normals{}, fmt.Errorf("climate service returned status %d", resp.StatusCode)
+	}
+
+	// A missing frost field must not decode as zero dates, which would read as frost-free
+	var doc struct {
+		Normals
+		Frost *frost.Dates `json:"frost"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return Normals{}, fmt.Errorf("failed to decode climate normals: %w", err)
+	}
+	normals := doc.Normals
+	if err := normals.Validate(); err != nil {
+		return Normals{}, fmt.Errorf("invalid climate normals: %w", err)
+	}
+
+	switch {
+	case doc.Frost != nil:
+		normals.Frost = *doc.Frost
+	case p.frost != nil:
+		estimate, err := p.frost.Estimate(latitude, longitude)
+		if err != nil {
+			return Normals{}, fmt.Errorf("failed to estimate frost dates: %w", err)
+		}
+		normals.Frost = estimate.Dates
+	default:
+		return Normals{}, errors.New("invalid climate normals: no frost dates")
+	}
+	return normals, nil
+}
@@ -0,0 +1,245 @@
+// Package companion models which plants grow well or badly next to each other and plans the
+// companion planting for a plant, or a set of plants grown together
+package companion
+
+import (
+	"sort"
+	"strings"
+)
+
+// Relation is how two plants affect each other when grown side by side
+type Relation string
+
+const (
+	// Good means the plants help each other, for example by deterring pests or improving growth
+	Good Relation = "good"
+	// Bad means the plants hinder each other, for example by competing or sharing diseases
+	Bad Relation = "bad"
+)
+
+// ParseRelation parses good or bad, case-insensitively
+func ParseRelation(s string) (Relation, bool) {
+	switch r := Relation(strings.ToLower(strings.TrimSpace(s))); r {
+	case Good, Bad:
+		return r, true
+	}
+	return "", false
+}
+
+// Link is a relationship recorded on one plant in the catalog
+type Link struct {
+	PlantID  string   `json:"plant_id"`
+	Relation Relation `json:"relation"`
+	Reason   string   `json:"reason"`
+}
+
+// Plant is a catalog plant and the relationships recorded on it
+type Plant struct {
+	ID    string
+	Name  string
+	Links []Link
+}
+
+// Neighbour is a plant related to the plant being planned for
+type Neighbour struct {
+	PlantID  string   `json:"plant_id"`
+	Name     string   `json:"name"`
+	Relation Relation `json:"relation"`
+	Reason   string   `json:"reason"`
+}
+
+// Conflict is a pair of plants in the same planting that are bad neighbours
+type Conflict struct {
+	PlantID   string `json:"plant_id"`
+	Name      string `json:"name"`
+	OtherID   string `json:"other_id"`
+	OtherName string `json:"other_name"`
+	Reason    string `json:"reason"`
+}
+
+// Candidate is a plant that could join a planting, a good neighbour to at least one of its
+// plants and a bad neighbour to none
+type Candidate struct {
+	PlantID string `json:"plant_id"`
+	Name    string `json:"name"`
+	// Partners lists the IDs of the planting's plants it is a good neighbour to
+	Partners []string `json:"partners"`
+	// Reason is the reason given for its first partner
+	Reason string `json:"reason"`
+}
+
+// Plan is the companion planting section of a report
+type Plan struct {
+	// Neighbours are the good and bad neighbours of the report's plant, good ones first
+	Neighbours []Neighbour `json:"neighbours"`
+	// Conflicts are the bad pairs among the report's plant and the neighbours it is grown with
+	Conflicts []Conflict `json:"conflicts,omitempty"`
+	// Suggestions are plants that suit the whole planting, only made when it has several plants
+	Suggestions []Candidate `json:"suggestions,omitempty"`
+}
+
+type edge struct {
+	relation Relation
+	reason   string
+}
+
+// Graph holds the relationships between plants. Relationships go both ways, so a link recorded
+// on either plant relates the pair. When the two plants disagree, bad wins.
+type Graph struct {
+	names map[string]string
+	edges map[string]map[string]edge
+}
+
+// NewGraph builds a Graph from catalog plants. Links to plants missing from the catalog, to the
+// plant itself or with an unknown relation are ignored.
+func NewGraph(plants []Plant) *Graph {
+	g := &Graph{
+		names: make(map[string]string, len(plants)),
+		edges: make(map[string]map[string]edge, len(plants)),
+	}
+	for _, p := range plants {
+		g.names[p.ID] = p.Name
+	}
+
+	// Plants are linked in ID order, so the reason kept for a pair does not depend on catalog order
+	sorted := append([]Plant(nil), plants...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+	for _, p := range sorted {
+		for _, link := range p.Links {
+			if _, ok := g.names[link.PlantID]; !ok || link.PlantID == p.ID {
+				continue
+			}
+			if link.Relation != Good && link.Relation != Bad {
+				continue
+			}
+			existing, ok := g.edges[p.ID][link.PlantID]
+			if ok && (existing.relation == Bad || link.Relation == Good) {
+				continue
+			}
+			g.link(p.ID, link.PlantID, edge{relation: link.Relation, reason: link.Reason})
+			g.link(link.PlantID, p.ID, edge{relation: link.Relation, reason: link.Reason})
+		}
+	}
+	return g
+}
+
+func (g *Graph) link(from, to string, e edge) {
+	if g.edges[from] == nil {
+		g.edges[from] = make(map[string]edge)
+	}
+	g.edges[from][to] = e
+}
+
+// Has reports whether the plant is in the graph
+func (g *Graph) Has(plantID string) bool {
+	_, ok := g.names[plantID]
+	return ok
+}
+
+// Neighbours returns the plants related to a plant, good neighbours first and then by name
+func (g *Graph) Neighbours(plantID string) []Neighbour {
+	var neighbours []Neighbour
+	for id, e := range g.edges[plantID] {
+		neighbours = append(neighbours, Neighbour{PlantID: id, Name: g.names[id], Relation: e.relation, Reason: e.reason})
+	}
+	sort.Slice(neighbours, func(i, j int) bool {
+		if neighbours[i].Relation != neighbours[j].Relation {
+			return neighbours[i].Relation == Good
+		}
+		return neighbours[i].Name < neighbours[j].Name
+	})
+	return neighbours
+}
+
+// Conflicts returns every bad pair among the given plants, in the order the plants are given
+func (g *Graph) Conflicts(plantIDs ...string) []Conflict {
+	plantIDs = unique(plantIDs)
+	var conflicts []Conflict
+	for i, a := range plantIDs {
+		for _, b := range plantIDs[i+1:] {
+			if e, ok := g.edges[a][b]; ok && e.relation == Bad {
+				conflicts = append(conflicts, Conflict{
+					PlantID:   a,
+					Name:      g.names[a],
+					OtherID:   b,
+					OtherName: g.names[b],
+					Reason:    e.reason,
+				})
+			}
+		}
+	}
+	return conflicts
+}
+
+// Compatible returns the plants outside the given set that are a good neighbour to at least one
+// of its plants and a bad neighbour to none. Plants that partner more of the set come first.
+func (g *Graph) Compatible(plantIDs ...string) []Candidate {
+	plantIDs = unique(plantIDs)
+	inSet := make(map[string]bool, len(plantIDs))
+	for _, id := range plantIDs {
+		inSet[id] = true
+	}
+
+	candidates := make(map[string]*Candidate)
+	excluded := make(map[string]bool)
+	for _, id := range plantIDs {
+		for other, e := range g.edges[id] {
+			if inSet[other] || excluded[other] {
+				continue
+			}
+			if e.relation == Bad {
+				excluded[other] = true
+				delete(candidates, other)
+				continue
+			}
+			c, ok := candidates[other]
+			if !ok {
+				c = &Candidate{PlantID: other, Name: g.names[other], Reason: e.reason}
+				candidates[other] = c
+			}
+			c.Partners = append(c.Partners, id)
+		}
+	}
+
+	compatible := make([]Candidate, 0, len(candidates))
+	for _, c := range candidates {
+		compatible = append(compatible, *c)
+	}
+	sort.Slice(compatible, func(i, j int) bool {
+		if len(compatible[i].Partners) != len(compatible[j].Partners) {
+			return len(compatible[i].Partners) > len(compatible[j].Partners)
+		}
+		return compatible[i].Name < compatible[j].Name
+	})
+	return compatible
+}
+
+// Plan builds the companion planting section for a plant grown alongside others. Suggestions
+// are limited to maxSuggestions.
+func (g *Graph) Plan(plantID string, others []string, maxSuggestions int) Plan {
+	planting := unique(append([]string{plantID}, others...))
+	plan := Plan{
+		Neighbours: g.Neighbours(plantID),
+		Conflicts:  g.Conflicts(planting...),
+	}
+	if len(planting) > 1 {
+		plan.Suggestions = g.Compatible(planting...)
+		if len(plan.Suggestions) > maxSuggestions {
+			plan.Suggestions = plan.Suggestions[:maxSuggestions]
+		}
+	}
+	return plan
+}
+
+// unique drops repeated plant IDs, keeping the first of each
+func unique(plantIDs []string) []string {
+	seen := make(map[string]bool, len(plantIDs))
+	out := make([]string, 0, len(plantIDs))
+	for _, id := range plantIDs {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
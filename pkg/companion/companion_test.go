@@ -0,0 +1,111 @@
+package companion
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var catalog = []Plant{
+	{ID: "tomato", Name: "Tomato", Links: []Link{
+		{PlantID: "basil", Relation: Good, Reason: "Repels whitefly"},
+		{PlantID: "fennel", Relation: Bad, Reason: "Stunts growth"},
+		{PlantID: "potato", Relation: Bad, Reason: "Shares blight"},
+	}},
+	{ID: "basil", Name: "Basil"},
+	{ID: "carrot", Name: "Carrot", Links: []Link{
+		{PlantID: "onion", Relation: Good, Reason: "Masks carrot fly"},
+		{PlantID: "tomato", Relation: Good, Reason: "Loosens the soil"},
+		{PlantID: "dill", Relation: Bad, Reason: "Cross-pollinates"},
+	}},
+	{ID: "onion", Name: "Onion", Links: []Link{
+		{PlantID: "bean", Relation: Bad, Reason: "Stunts beans"},
+		{PlantID: "onion", Relation: Good, Reason: "Ignored"},
+		{PlantID: "leek", Relation: Good, Reason: "Not in the catalog"},
+	}},
+	{ID: "potato", Name: "Potato", Links: []Link{
+		// Disagrees with tomato, bad wins
+		{PlantID: "tomato", Relation: Good, Reason: "Ignored"},
+	}},
+	{ID: "fennel", Name: "Fennel"},
+	{ID: "dill", Name: "Dill", Links: []Link{
+		{PlantID: "onion", Relation: Good, Reason: "Attracts hoverflies"},
+		{PlantID: "basil", Relation: "maybe", Reason: "Ignored"},
+	}},
+	{ID: "bean", Name: "Bean"},
+}
+
+func TestParseRelation(t *testing.T) {
+	r, ok := ParseRelation(" Good ")
+	assert.True(t, ok)
+	assert.Equal(t, Good, r)
+	_, ok = ParseRelation("maybe")
+	assert.False(t, ok)
+}
+
+func TestGraph_Neighbours(t *testing.T) {
+	g := NewGraph(catalog)
+	assert.True(t, g.Has("fennel"))
+	assert.False(t, g.Has("leek"))
+
+	// Links recorded on other plants count too
+	assert.Equal(t, []Neighbour{
+		{PlantID: "basil", Name: "Basil", Relation: Good, Reason: "Repels whitefly"},
+		{PlantID: "carrot", Name: "Carrot", Relation: Good, Reason: "Loosens the soil"},
+		{PlantID: "fennel", Name: "Fennel", Relation: Bad, Reason: "Stunts growth"},
+		{PlantID: "potato", Name: "Potato", Relation: Bad, Reason: "Shares blight"},
+	}, g.Neighbours("tomato"))
+	assert.Equal(t, []Neighbour{
+		{PlantID: "carrot", Name: "Carrot", Relation: Good, Reason: "Masks carrot fly"},
+		{PlantID: "dill", Name: "Dill", Relation: Good, Reason: "Attracts hoverflies"},
+		{PlantID: "bean", Name: "Bean", Relation: Bad, Reason: "Stunts beans"},
+	}, g.Neighbours("onion"))
+	assert.Empty(t, g.Neighbours("leek"))
+}
+
+func TestGraph_Conflicts(t *testing.T) {
+	g := NewGraph(catalog)
+	assert.Equal(t, []Conflict{
+		{PlantID: "tomato", Name: "Tomato", OtherID: "potato", OtherName: "Potato", Reason: "Shares blight"},
+		{PlantID: "carrot", Name: "Carrot", OtherID: "dill", OtherName: "Dill", Reason: "Cross-pollinates"},
+	}, g.Conflicts("tomato", "carrot", "potato", "dill", "tomato"))
+	assert.Empty(t, g.Conflicts("tomato", "basil", "carrot"))
+}
+
+func TestGraph_Compatible(t *testing.T) {
+	g := NewGraph(catalog)
+
+	assert.Equal(t, []Candidate{
+		{PlantID: "basil", Name: "Basil", Partners: []string{"tomato"}, Reason: "Repels whitefly"},
+		{PlantID: "carrot", Name: "Carrot", Partners: []string{"tomato"}, Reason: "Loosens the soil"},
+	}, g.Compatible("tomato"))
+
+	// Plants partnering more of the set come first
+	assert.Equal(t, []Candidate{
+		{PlantID: "carrot", Name: "Carrot", Partners: []string{"tomato", "onion"}, Reason: "Loosens the soil"},
+		{PlantID: "basil", Name: "Basil", Partners: []string{"tomato"}, Reason: "Repels whitefly"},
+		{PlantID: "dill", Name: "Dill", Partners: []string{"onion"}, Reason: "Attracts hoverflies"},
+	}, g.Compatible("tomato", "onion"))
+
+	// Dill is a good neighbour to onion but a bad one to carrot
+	assert.Equal(t, []Candidate{
+		{PlantID: "tomato", Name: "Tomato", Partners: []string{"carrot"}, Reason: "Loosens the soil"},
+	}, g.Compatible("onion", "carrot"))
+}
+
+func TestGraph_Plan(t *testing.T) {
+	g := NewGraph(catalog)
+
+	plan := g.Plan("tomato", nil, 3)
+	assert.Len(t, plan.Neighbours, 4)
+	assert.Empty(t, plan.Conflicts)
+	assert.Empty(t, plan.Suggestions)
+
+	plan = g.Plan("tomato", []string{"onion", "potato"}, 1)
+	assert.Equal(t, []Conflict{
+		{PlantID: "tomato", Name: "Tomato", OtherID: "potato", OtherName: "Potato", Reason: "Shares blight"},
+	}, plan.Conflicts)
+	assert.Equal(t, []Candidate{
+		{PlantID: "carrot", Name: "Carrot", Partners: []string{"tomato", "onion"}, Reason: "Loosens the soil"},
+	}, plan.Suggestions)
+}
@@ -0,0 +1,135 @@
+package frost
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/HealthyTechGuy/plant-report-app/pkg/geo"
+)
+
+//go:embed data/stations.csv
+var embeddedStations []byte
+
+// DefaultMaxDistanceKm is how far away the nearest station may be before a location is treated as unknown
+const DefaultMaxDistanceKm = 1500
+
+var (
+	// ErrNoStation is returned when no frost station is close enough to a location
+	ErrNoStation = errors.New("no frost station near location")
+)
+
+// Dates holds the median last spring and first fall frost as days of the year (1-366).
+// Zero values mean the location does not normally see frost.
+type Dates struct {
+	LastSpring int `json:"last_spring"`
+	FirstFall  int `json:"first_fall"`
+}
+
+// FrostFree reports whether the location normally has no frost at all
+func (d Dates) FrostFree() bool {
+	return d.LastSpring == 0 && d.FirstFall == 0
+}
+
+// SeasonLength returns the number of frost-free days between the last spring and first fall frost.
+// In the southern hemisphere the season wraps around the end of the year.
+func (d Dates) SeasonLength() int {
+	if d.FrostFree() {
+		return 365
+	}
+	if d.FirstFall > d.LastSpring {
+		return d.FirstFall - d.LastSpring
+	}
+	return 365 - d.LastSpring + d.FirstFall
+}
+
+// Estimate is the result of a frost date lookup for a location
+type Estimate struct {
+	Station    string
+	DistanceKm float64
+	Dates      Dates
+}
+
+// station is one row of the bundled frost station dataset
+type station struct {
+	name      string
+	latitude  float64
+	longitude float64
+	dates     Dates
+}
+
+// Estimator looks up frost dates from the nearest station in the bundled dataset
+type Estimator struct {
+	stations      []station
+	maxDistanceKm float64
+}
+
+// NewEstimator creates an Estimator from the bundled station dataset
+func NewEstimator() (*Estimator, error) {
+	stations, err := parseStations(embeddedStations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse embedded frost stations: %w", err)
+	}
+	return &Estimator{
+		stations:      stations,
+		maxDistanceKm: DefaultMaxDistanceKm,
+	}, nil
+}
+
+// Estimate returns the frost dates of the station nearest to the given coordinate
+func (e *Estimator) Estimate(latitude, longitude float64) (Estimate, error) {
+	best := -1
+	bestDistance := math.Inf(1)
+	for i, s := range e.stations {
+		d := geo.DistanceKm(latitude, longitude, s.latitude, s.longitude)
+		if d < bestDistance {
+			best, bestDistance = i, d
+		}
+	}
+	if best < 0 || bestDistance > e.maxDistanceKm {
+		return Estimate{}, ErrNoStation
+	}
+	return Estimate{
+		Station:    e.stations[best].name,
+		DistanceKm: bestDistance,
+		Dates:      e.stations[best].dates,
+	}, nil
+}
+
+// parseStations reads the station CSV: station,latitude,longitude,last_spring,first_fall
+func parseStations(data []byte) ([]station, error) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) < 2 {
+		return nil, errors.New("no stations")
+	}
+
+	stations := make([]station, 0, len(records)-1)
+	for i, record := range records[1:] {
+		if len(record) != 5 {
+			return nil, fmt.Errorf("line %d: expected 5 fields, got %d", i+2, len(record))
+		}
+		var values [4]float64
+		for j, field := range record[1:] {
+			v, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+2, err)
+			}
+			values[j] = v
+		}
+		stations = append(stations, station{
+			name:      record[0],
+			latitude:  values[0],
+			longitude: values[1],
+			dates:     Dates{LastSpring: int(values[2]), FirstFall: int(values[3])},
+		})
+	}
+	return stations, nil
+}
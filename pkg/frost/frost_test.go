@@ -0,0 +1,110 @@
+package frost
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimator_NearestStation(t *testing.T) {
+	estimator, err := NewEstimator()
+	require.NoError(t, err)
+
+	// Cambridge, MA is a few kilometres from the Boston station
+	estimate, err := estimator.Estimate(42.37, -71.11)
+	require.NoError(t, err)
+	assert.Equal(t, "Boston, MA", estimate.Station)
+	assert.Less(t, estimate.DistanceKm, 10.0)
+	assert.Equal(t, Dates{LastSpring: 97, FirstFall: 311}, estimate.Dates)
+}
+
+func TestEstimator_NoStation(t *testing.T) {
+	estimator, err := NewEstimator()
+	require.NoError(t, err)
+
+	_, err = estimator.Estimate(-60, -140)
+	assert.ErrorIs(t, err, ErrNoStation)
+}
+
+func TestParseStations_Invalid(t *testing.T) {
+	_, err := parseStations([]byte("station,latitude,longitude,last_spring,first_fall\nX,abc,1,2,3\n"))
+	assert.Error(t, err)
+
+	_, err = parseStations([]byte("station,latitude,longitude,last_spring,first_fall\n"))
+	assert.Error(t, err)
+}
+
+func TestDates_SeasonLength(t *testing.T) {
+	assert.Equal(t, 224, Dates{LastSpring: 91, FirstFall: 315}.SeasonLength())
+	// Southern hemisphere seasons wrap around the new year
+	assert.Equal(t, 266, Dates{LastSpring: 244, FirstFall: 145}.SeasonLength())
+	assert.Equal(t, 365, Dates{}.SeasonLength())
+}
+
+func TestParseTolerance(t *testing.T) {
+	assert.Equal(t, Hardy, ParseTolerance("Hardy"))
+	assert.Equal(t, HalfHardy, ParseTolerance("half-hardy"))
+	assert.Equal(t, Tender, ParseTolerance(""))
+	assert.Equal(t, Tender, ParseTolerance("unknown"))
+}
+
+func TestPlan(t *testing.T) {
+	newYork := Dates{LastSpring: 91, FirstFall: 315} // Apr 1, Nov 11
+	jan := time.Date(2027, time.January, 10, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		maturity   int
+		tolerance  Tolerance
+		transplant time.Time
+		harvest    time.Time
+		matures    bool
+	}{
+		{"tender goes out after the last frost", 70, Tender, date(2027, time.April, 15), date(2027, time.June, 24), true},
+		{"hardy goes out before the last frost", 60, Hardy, date(2027, time.March, 18), date(2027, time.May, 17), true},
+		{"half-hardy goes out at the last frost", 0, HalfHardy, date(2027, time.April, 1), time.Time{}, true},
+		{"long season crop misses the first frost", 240, Tender, date(2027, time.April, 15), date(2027, time.December, 11), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := Plan(tt.maturity, tt.tolerance, newYork, jan)
+			assert.False(t, schedule.FrostFree)
+			assert.Equal(t, date(2027, time.April, 1), schedule.LastFrost)
+			assert.Equal(t, date(2027, time.November, 11), schedule.FirstFrost)
+			assert.Equal(t, tt.transplant, schedule.Transplant)
+			assert.Equal(t, tt.transplant.AddDate(0, 0, -7*DefaultIndoorWeeks), schedule.SowIndoors)
+			assert.Equal(t, tt.harvest, schedule.Harvest)
+			assert.Equal(t, tt.matures, schedule.MaturesBeforeFrost)
+		})
+	}
+}
+
+func TestPlan_RollsOverToNextSeason(t *testing.T) {
+	newYork := Dates{LastSpring: 91, FirstFall: 315}
+	schedule := Plan(70, Tender, newYork, time.Date(2027, time.June, 1, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, date(2028, time.April, 14), schedule.Transplant)
+}
+
+func TestPlan_SouthernHemisphere(t *testing.T) {
+	melbourne := Dates{LastSpring: 244, FirstFall: 145}
+	schedule := Plan(90, Tender, melbourne, time.Date(2027, time.January, 10, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, date(2027, time.September, 1), schedule.LastFrost)
+	assert.Equal(t, date(2028, time.May, 24), schedule.FirstFrost) // 2028 is a leap year
+	assert.True(t, schedule.MaturesBeforeFrost)
+}
+
+func TestPlan_FrostFree(t *testing.T) {
+	now := time.Date(2027, time.March, 3, 9, 30, 0, 0, time.UTC)
+	schedule := Plan(30, Tender, Dates{}, now)
+	assert.True(t, schedule.FrostFree)
+	assert.True(t, schedule.SowIndoors.IsZero())
+	assert.Equal(t, date(2027, time.March, 3), schedule.Transplant)
+	assert.Equal(t, date(2027, time.April, 2), schedule.Harvest)
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
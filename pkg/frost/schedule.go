@@ -0,0 +1,112 @@
+package frost
+
+import (
+	"strings"
+	"time"
+)
+
+// Tolerance describes how well a plant copes with frost
+type Tolerance string
+
+const (
+	// Tender plants are killed by frost and go out after all risk has passed
+	Tender Tolerance = "tender"
+	// HalfHardy plants survive a light frost and go out around the last frost date
+	HalfHardy Tolerance = "half-hardy"
+	// Hardy plants tolerate frost and can go out before the last frost date
+	Hardy Tolerance = "hardy"
+)
+
+// ParseTolerance converts a stored tolerance value, treating anything unrecognised as Tender
+func ParseTolerance(s string) Tolerance {
+	switch Tolerance(strings.ToLower(strings.TrimSpace(s))) {
+	case Hardy:
+		return Hardy
+	case HalfHardy:
+		return HalfHardy
+	default:
+		return Tender
+	}
+}
+
+// DefaultIndoorWeeks is how long seedlings are raised indoors before transplanting
+const DefaultIndoorWeeks = 6
+
+// Schedule holds concrete planting dates worked out from a location's frost dates
+type Schedule struct {
+	FrostFree          bool
+	LastFrost          time.Time
+	FirstFrost         time.Time
+	SowIndoors         time.Time
+	Transplant         time.Time
+	Harvest            time.Time
+	MaturesBeforeFrost bool
+}
+
+// transplantOffset is how many days after the last frost a plant can go outside
+var transplantOffset = map[Tolerance]int{
+	Hardy:     -14,
+	HalfHardy: 0,
+	Tender:    14,
+}
+
+// harvestGrace is how many days past the first frost a plant can keep growing
+var harvestGrace = map[Tolerance]int{
+	Hardy:     28,
+	HalfHardy: 7,
+	Tender:    0,
+}
+
+// Plan works out the next planting schedule on or after now.
+// A daysToMaturity of zero leaves the harvest date unset.
+func Plan(daysToMaturity int, tolerance Tolerance, dates Dates, now time.Time) Schedule {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	if dates.FrostFree() {
+		schedule := Schedule{
+			FrostFree:          true,
+			Transplant:         today,
+			MaturesBeforeFrost: true,
+		}
+		if daysToMaturity > 0 {
+			schedule.Harvest = today.AddDate(0, 0, daysToMaturity)
+		}
+		return schedule
+	}
+
+	schedule := planSeason(daysToMaturity, tolerance, dates, today.Year())
+	if schedule.SowIndoors.Before(today) {
+		schedule = planSeason(daysToMaturity, tolerance, dates, today.Year()+1)
+	}
+	return schedule
+}
+
+// planSeason works out the schedule for the growing season starting in the given year
+func planSeason(daysToMaturity int, tolerance Tolerance, dates Dates, year int) Schedule {
+	lastFrost := dayOfYear(year, dates.LastSpring)
+	firstFrost := dayOfYear(year, dates.FirstFall)
+	if dates.FirstFall <= dates.LastSpring {
+		// Southern hemisphere seasons run into the following year
+		firstFrost = dayOfYear(year+1, dates.FirstFall)
+	}
+
+	transplant := lastFrost.AddDate(0, 0, transplantOffset[tolerance])
+	schedule := Schedule{
+		LastFrost:          lastFrost,
+		FirstFrost:         firstFrost,
+		SowIndoors:         transplant.AddDate(0, 0, -7*DefaultIndoorWeeks),
+		Transplant:         transplant,
+		MaturesBeforeFrost: true,
+	}
+	if daysToMaturity > 0 {
+		schedule.Harvest = transplant.AddDate(0, 0, daysToMaturity)
+		deadline := firstFrost.AddDate(0, 0, harvestGrace[tolerance])
+		schedule.MaturesBeforeFrost = !schedule.Harvest.After(deadline)
+	}
+	return schedule
+}
+
+// dayOfYear returns the date of the given day of the year
+func dayOfYear(year, day int) time.Time {
+	return time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, day-1)
+}
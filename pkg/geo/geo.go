@@ -0,0 +1,48 @@
+package geo
+
+import (
+	"math"
+	"strconv"
+)
+
+// EarthRadiusKm is the mean radius of the Earth
+const EarthRadiusKm = 6371.0
+
+// DistanceKm returns the great-circle distance between two coordinates in kilometres using the haversine formula
+func DistanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	dLat := toRadians(lat2 - lat1)
+	dLon := toRadians(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * EarthRadiusKm * math.Asin(math.Sqrt(math.Min(1, a)))
+}
+
+func toRadians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+// Cell is a square of a latitude/longitude grid with sides of Size degrees
+type Cell struct {
+	Row  int
+	Col  int
+	Size float64
+}
+
+// CellOf returns the grid cell containing a coordinate
+func CellOf(latitude, longitude, size float64) Cell {
+	return Cell{
+		Row:  int(math.Floor(latitude / size)),
+		Col:  int(math.Floor(longitude / size)),
+		Size: size,
+	}
+}
+
+// Centre returns the coordinate at the middle of the cell
+func (c Cell) Centre() (latitude, longitude float64) {
+	return (float64(c.Row) + 0.5) * c.Size, (float64(c.Col) + 0.5) * c.Size
+}
+
+// String identifies the cell, e.g. "0.1:407_-741"
+func (c Cell) String() string {
+	return strconv.FormatFloat(c.Size, 'f', -1, 64) + ":" + strconv.Itoa(c.Row) + "_" + strconv.Itoa(c.Col)
+}
@@ -0,0 +1,30 @@
+package geo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistanceKm(t *testing.T) {
+	// London to Paris is roughly 344 km
+	assert.InDelta(t, 344, DistanceKm(51.5074, -0.1278, 48.8566, 2.3522), 5)
+	// Antipodal points are half the circumference apart
+	assert.InDelta(t, 20015, DistanceKm(0, 0, 0, 180), 1)
+	assert.Zero(t, DistanceKm(10, 10, 10, 10))
+}
+
+func TestCellOf(t *testing.T) {
+	// Two points a few hundred metres apart in New York share a cell
+	a := CellOf(40.7128, -74.0060, 0.1)
+	b := CellOf(40.7150, -74.0100, 0.1)
+	assert.Equal(t, a, b)
+	assert.Equal(t, "0.1:407_-741", a.String())
+
+	lat, lon := a.Centre()
+	assert.InDelta(t, 40.75, lat, 1e-9)
+	assert.InDelta(t, -74.05, lon, 1e-9)
+
+	// Crossing a cell boundary gives a different cell
+	assert.NotEqual(t, a, CellOf(40.69, -74.0060, 0.1))
+}
@@ -15,6 +15,10 @@ func InitLogger(level string) {
 		logLevel = zap.DebugLevel
 	case "info":
 		logLevel = zap.InfoLevel
+	case "warn":
+		logLevel = zap.WarnLevel
+	case "error":
+		logLevel = zap.ErrorLevel
 	default:
 		logLevel = zap.InfoLevel
 	}
@@ -0,0 +1,119 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// ErrInvalidAddress is returned for email addresses that cannot be delivered to
+var ErrInvalidAddress = errors.New("invalid email address")
+
+// Attachment is a file sent with a message
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Message is a plain text email
+type Message struct {
+	To          string
+	Subject     string
+	Text        string
+	Attachments []Attachment
+}
+
+// Mailer sends email
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// ValidateAddress checks that addr is a single bare address such as gardener@example.com
+// and returns it trimmed
+func ValidateAddress(addr string) (string, error) {
+	addr = strings.TrimSpace(addr)
+	parsed, err := mail.ParseAddress(addr)
+	if err != nil || parsed.Address != addr || len(addr) > 254 {
+		return "", fmt.Errorf("%w: %q", ErrInvalidAddress, addr)
+	}
+	local, domain, _ := strings.Cut(addr, "@")
+	if local == "" || !strings.Contains(domain, ".") || strings.ContainsAny(addr, "\r\n") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidAddress, addr)
+	}
+	return addr, nil
+}
+
+// build encodes msg as a MIME message from from. Messages with attachments are multipart/mixed.
+func build(from string, msg Message, now time.Time) ([]byte, error) {
+	if _, err := ValidateAddress(msg.To); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	header := func(name, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", name, value)
+	}
+	header("From", from)
+	header("To", msg.To)
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+
+	if len(msg.Attachments) == 0 {
+		header("Content-Type", `text/plain; charset="utf-8"`)
+		header("Content-Transfer-Encoding", "base64")
+		buf.WriteString("\r\n")
+		writeBase64(&buf, []byte(msg.Text))
+		return buf.Bytes(), nil
+	}
+
+	boundary := newBoundary()
+	header("Content-Type", fmt.Sprintf(`multipart/mixed; boundary="%s"`, boundary))
+	buf.WriteString("\r\n")
+
+	fmt.Fprintf(&buf, "--%s\r\n", boundary)
+	header("Content-Type", `text/plain; charset="utf-8"`)
+	header("Content-Transfer-Encoding", "base64")
+	buf.WriteString("\r\n")
+	writeBase64(&buf, []byte(msg.Text))
+
+	for _, a := range msg.Attachments {
+		fmt.Fprintf(&buf, "--%s\r\n", boundary)
+		header("Content-Type", a.ContentType)
+		header("Content-Transfer-Encoding", "base64")
+		header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename}))
+		buf.WriteString("\r\n")
+		writeBase64(&buf, a.Data)
+	}
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+	return buf.Bytes(), nil
+}
+
+// writeBase64 writes data base64 encoded in 76 character lines, as MIME requires
+func writeBase64(buf *bytes.Buffer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76])
+		buf.WriteString("\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded)
+	buf.WriteString("\r\n")
+}
+
+func newBoundary() string {
+	var b [12]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic("crypto/rand failed: " + err.Error())
+	}
+	return "plant-report-" + hex.EncodeToString(b[:])
+}
@@ -0,0 +1,188 @@
+package mailer
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ses"
+	"github.com/aws/aws-sdk-go/service/ses/sesiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var sentAt = time.Date(2026, time.April, 2, 8, 0, 0, 0, time.UTC)
+
+func TestValidateAddress(t *testing.T) {
+	for _, valid := range []string{"gardener@example.com", " first.last+plants@mail.example.co.uk "} {
+		addr, err := ValidateAddress(valid)
+		assert.NoError(t, err, valid)
+		assert.Equal(t, strings.TrimSpace(valid), addr)
+	}
+	for _, invalid := range []string{"", "gardener", "gardener@localhost", "Gardener <gardener@example.com>", "a@example.com, b@example.com", "a@example.com\r\nBcc: b@example.com"} {
+		_, err := ValidateAddress(invalid)
+		assert.ErrorIs(t, err, ErrInvalidAddress, invalid)
+	}
+}
+
+// smtpStub is a minimal SMTP server that records the last message it received
+type smtpStub struct {
+	listener net.Listener
+	from     string
+	to       string
+	data     chan string
+}
+
+func newSMTPStub(t *testing.T) *smtpStub {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	stub := &smtpStub{listener: listener, data: make(chan string, 1)}
+	t.Cleanup(func() { listener.Close() })
+	go stub.serve()
+	return stub
+}
+
+func (s *smtpStub) serve() {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = io.WriteString(conn, line+"\r\n") }
+
+	reply("220 localhost stub")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			s.from = strings.Trim(strings.TrimSpace(line)[len("MAIL FROM:"):], "<>")
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			s.to = strings.Trim(strings.TrimSpace(line)[len("RCPT TO:"):], "<>")
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var body strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				body.WriteString(strings.TrimPrefix(l, "."))
+			}
+			s.data <- body.String()
+			reply("250 OK queued")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func TestSMTPMailer_SendsAttachment(t *testing.T) {
+	stub := newSMTPStub(t)
+	m := NewSMTPMailer(stub.listener.Addr().String(), "reports@example.com", "", "")
+	m.now = func() time.Time { return sentAt }
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := m.Send(ctx, Message{
+		To:      "gardener@example.com",
+		Subject: "Your Kale growing report",
+		Text:    "Your report is attached.",
+		Attachments: []Attachment{
+			{Filename: "kale-report.pdf", ContentType: "application/pdf", Data: []byte("%PDF-1.3 kale")},
+		},
+	})
+	require.NoError(t, err)
+
+	parsed, err := mail.ReadMessage(strings.NewReader(<-stub.data))
+	require.NoError(t, err)
+	assert.Equal(t, "reports@example.com", stub.from)
+	assert.Equal(t, "gardener@example.com", stub.to)
+	assert.Equal(t, "Your Kale growing report", parsed.Header.Get("Subject"))
+	assert.Equal(t, "gardener@example.com", parsed.Header.Get("To"))
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/mixed", mediaType)
+
+	parts := multipart.NewReader(parsed.Body, params["boundary"])
+	text, err := parts.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "Your report is attached.", decodePart(t, text))
+
+	attachment, err := parts.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "kale-report.pdf", attachment.FileName())
+	assert.Equal(t, "%PDF-1.3 kale", decodePart(t, attachment))
+}
+
+// decodePart reads a base64 encoded MIME part
+func decodePart(t *testing.T, part *multipart.Part) string {
+	t.Helper()
+	require.Equal(t, "base64", part.Header.Get("Content-Transfer-Encoding"))
+	data, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, part))
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestSMTPMailer_RejectsInvalidRecipient(t *testing.T) {
+	m := NewSMTPMailer("127.0.0.1:1", "reports@example.com", "", "")
+	err := m.Send(context.TODO(), Message{To: "not-an-address"})
+	assert.ErrorIs(t, err, ErrInvalidAddress)
+}
+
+// fakeSES records raw emails
+type fakeSES struct {
+	sesiface.SESAPI
+	input *ses.SendRawEmailInput
+}
+
+func (f *fakeSES) SendRawEmailWithContext(ctx aws.Context, input *ses.SendRawEmailInput, _ ...request.Option) (*ses.SendRawEmailOutput, error) {
+	f.input = input
+	return &ses.SendRawEmailOutput{MessageId: aws.String("1")}, nil
+}
+
+func TestSESMailer_Send(t *testing.T) {
+	client := &fakeSES{}
+	m := NewSESMailer(client, "reports@example.com")
+	m.now = func() time.Time { return sentAt }
+
+	err := m.Send(context.TODO(), Message{
+		To:      "gardener@example.com",
+		Subject: "Your Kale growing report",
+		Text:    "Download it at https://example.com/kale.pdf",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "reports@example.com", aws.StringValue(client.input.Source))
+	assert.Equal(t, []*string{aws.String("gardener@example.com")}, client.input.Destinations)
+	parsed, err := mail.ReadMessage(strings.NewReader(string(client.input.RawMessage.Data)))
+	require.NoError(t, err)
+	assert.Equal(t, "Thu, 02 Apr 2026 08:00:00 +0000", parsed.Header.Get("Date"))
+	assert.Contains(t, parsed.Header.Get("Content-Type"), "text/plain")
+}
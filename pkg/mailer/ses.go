@@ -0,0 +1,40 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ses"
+	"github.com/aws/aws-sdk-go/service/ses/sesiface"
+)
+
+// SESMailer sends email through Amazon SES. from must be a verified SES identity.
+type SESMailer struct {
+	client sesiface.SESAPI
+	from   string
+	now    func() time.Time
+}
+
+// NewSESMailer creates an SESMailer
+func NewSESMailer(client sesiface.SESAPI, from string) *SESMailer {
+	return &SESMailer{client: client, from: from, now: time.Now}
+}
+
+// Send delivers msg as a raw MIME message, which SES needs for attachments
+func (m *SESMailer) Send(ctx context.Context, msg Message) error {
+	data, err := build(m.from, msg, m.now())
+	if err != nil {
+		return err
+	}
+	_, err = m.client.SendRawEmailWithContext(ctx, &ses.SendRawEmailInput{
+		Source:       aws.String(m.from),
+		Destinations: []*string{aws.String(msg.To)},
+		RawMessage:   &ses.RawMessage{Data: data},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send email through SES: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package pdf
+
+import (
+	"fmt"
+
+	models "github.com/HealthyTechGuy/plant-report-app/models"
+	"github.com/jung-kurt/gofpdf"
+)
+
+// drawAlternatives renders the "You might also try" list of similar plants that suit the location
+func drawAlternatives(pdf *gofpdf.Fpdf, alternatives []models.Alternative) {
+	pdf.SetFont("Arial", "B", 12)
+	pdf.Cell(190, 10, "You might also try")
+	pdf.Ln(10)
+
+	pdf.SetFont("Arial", "", 11)
+	for _, alt := range alternatives {
+		pdf.Cell(60, 8, alt.Name)
+		pdf.Cell(30, 8, fmt.Sprintf("Score %d/100", alt.Score))
+		pdf.Cell(90, 8, alt.Reason)
+		pdf.Ln(8)
+	}
+	pdf.Ln(4)
+}
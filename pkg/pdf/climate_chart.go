@@ -0,0 +1,151 @@
+package pdf
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	models "github.com/HealthyTechGuy/plant-report-app/models"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/climate"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/units"
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Chart layout, in millimetres
+const (
+	chartWidth   = 115.0
+	chartHeight  = 55.0
+	needsGap     = 5.0
+	needsWidth   = 60.0
+	chartPadding = 2.0
+)
+
+// drawClimateSection renders the monthly climate chart with the plant's needs alongside it
+func drawClimateSection(pdf *gofpdf.Fpdf, tr func(string) string, normals climate.Normals, plantInfo models.PlantInfo, system units.System) {
+	pdf.SetFont("Arial", "B", 12)
+	pdf.Cell(190, 10, "Local Climate")
+	pdf.Ln(10)
+	pdf.SetFont("Arial", "I", 9)
+	pdf.Cell(190, 5, tr(fmt.Sprintf("Climate normals from the nearest station: %s", normals.Station)))
+	pdf.Ln(7)
+
+	x, y := pdf.GetX(), pdf.GetY()
+	drawClimateChart(pdf, tr, x, y, normals, system)
+	drawPlantNeeds(pdf, tr, x+chartWidth+needsGap, y, normals, plantInfo, system)
+
+	pdf.SetXY(x, y+chartHeight+12)
+}
+
+// drawClimateChart draws monthly rainfall as bars with min/max temperature lines over the top
+func drawClimateChart(pdf *gofpdf.Fpdf, tr func(string) string, x, y float64, normals climate.Normals, system units.System) {
+	pdf.SetDrawColor(160, 160, 160)
+	pdf.Rect(x, y, chartWidth, chartHeight, "D")
+
+	// Scales: rainfall from zero, temperatures across the observed range
+	maxRain := 1.0
+	minTemp, maxTemp := math.Inf(1), math.Inf(-1)
+	for _, m := range normals.Months {
+		maxRain = math.Max(maxRain, m.Rainfall.Millimetres())
+		minTemp = math.Min(minTemp, m.MinTemp.Celsius())
+		maxTemp = math.Max(maxTemp, m.MaxTemp.Celsius())
+	}
+	minTemp, maxTemp = minTemp-2, maxTemp+2
+
+	plotTop := y + chartPadding
+	plotHeight := chartHeight - 2*chartPadding
+	slot := chartWidth / float64(len(normals.Months))
+	tempY := func(t units.Temperature) float64 {
+		return plotTop + plotHeight*(1-(t.Celsius()-minTemp)/(maxTemp-minTemp))
+	}
+
+	// Rainfall bars
+	pdf.SetFillColor(150, 190, 230)
+	for i, m := range normals.Months {
+		h := plotHeight * m.Rainfall.Millimetres() / maxRain
+		pdf.Rect(x+float64(i)*slot+slot*0.2, plotTop+plotHeight-h, slot*0.6, h, "F")
+	}
+
+	// Temperature lines
+	pdf.SetLineWidth(0.5)
+	for i := 1; i < len(normals.Months); i++ {
+		prev, cur := normals.Months[i-1], normals.Months[i]
+		x1, x2 := x+(float64(i)-0.5)*slot, x+(float64(i)+0.5)*slot
+		pdf.SetDrawColor(200, 60, 40)
+		pdf.Line(x1, tempY(prev.MaxTemp), x2, tempY(cur.MaxTemp))
+		pdf.SetDrawColor(60, 90, 180)
+		pdf.Line(x1, tempY(prev.MinTemp), x2, tempY(cur.MinTemp))
+	}
+	pdf.SetLineWidth(0.2)
+	pdf.SetDrawColor(0, 0, 0)
+
+	// Month labels
+	pdf.SetFont("Arial", "", 7)
+	for i, m := range normals.Months {
+		pdf.SetXY(x+float64(i)*slot, y+chartHeight+0.5)
+		pdf.CellFormat(slot, 4, m.Month.String()[:1], "", 0, "C", false, 0, "")
+	}
+
+	// Legend
+	pdf.SetXY(x, y+chartHeight+5)
+	pdf.CellFormat(chartWidth, 4, tr(fmt.Sprintf("Bars: rainfall (max %s/month)   Lines: min/max temperature (%s to %s)",
+		system.FormatRainfall(units.Length(maxRain)),
+		system.FormatTemperature(units.Celsius(minTemp+2)),
+		system.FormatTemperature(units.Celsius(maxTemp-2)))), "", 0, "L", false, 0, "")
+}
+
+// drawPlantNeeds lists the plant's requirements next to the equivalent local values
+func drawPlantNeeds(pdf *gofpdf.Fpdf, tr func(string) string, x, y float64, normals climate.Normals, plantInfo models.PlantInfo, system units.System) {
+	pdf.SetDrawColor(160, 160, 160)
+	pdf.Rect(x, y, needsWidth, chartHeight, "D")
+	pdf.SetDrawColor(0, 0, 0)
+
+	coldest, warmest := normals.Months[0].MinTemp, normals.Months[0].MaxTemp
+	for _, m := range normals.Months {
+		coldest = units.Temperature(math.Min(float64(coldest), float64(m.MinTemp)))
+		warmest = units.Temperature(math.Max(float64(warmest), float64(m.MaxTemp)))
+	}
+
+	rows := [][2]string{
+		{"Plant needs", ""},
+	}
+	if plantInfo.MinTemperature != 0 || plantInfo.MaxTemperature != 0 {
+		rows = append(rows, [2]string{"Temperature", fmt.Sprintf("%s to %s",
+			system.FormatTemperature(plantInfo.MinTemperature), system.FormatTemperature(plantInfo.MaxTemperature))})
+	}
+	if plantInfo.AnnualRainfall > 0 {
+		rows = append(rows, [2]string{"Rainfall", system.FormatRainfall(plantInfo.AnnualRainfall) + "/yr"})
+	}
+	rows = append(rows,
+		[2]string{"Your location", ""},
+		[2]string{"Coldest night", system.FormatTemperature(coldest)},
+		[2]string{"Warmest day", system.FormatTemperature(warmest)},
+		[2]string{"Rainfall", system.FormatRainfall(normals.AnnualRainfall()) + "/yr"},
+	)
+	if normals.Frost.FrostFree() {
+		rows = append(rows, [2]string{"Frost", "Frost free"})
+	} else {
+		rows = append(rows,
+			[2]string{"Last frost", dayOfYearLabel(normals.Frost.LastSpring)},
+			[2]string{"First frost", dayOfYearLabel(normals.Frost.FirstFall)},
+		)
+	}
+
+	pdf.SetXY(x+chartPadding, y+chartPadding)
+	for _, row := range rows {
+		pdf.SetX(x + chartPadding)
+		if row[1] == "" {
+			pdf.SetFont("Arial", "B", 9)
+			pdf.Cell(needsWidth-2*chartPadding, 5, row[0])
+		} else {
+			pdf.SetFont("Arial", "", 8)
+			pdf.Cell(24, 5, row[0]+":")
+			pdf.Cell(needsWidth-24-2*chartPadding, 5, tr(row[1]))
+		}
+		pdf.Ln(5)
+	}
+}
+
+// dayOfYearLabel formats a day of the year as a month and day, e.g. "Apr 1"
+func dayOfYearLabel(day int) string {
+	return time.Date(2001, time.January, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, day-1).Format("Jan 2")
+}
@@ -0,0 +1,56 @@
+package pdf
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/HealthyTechGuy/plant-report-app/pkg/companion"
+	"github.com/jung-kurt/gofpdf"
+)
+
+// relationLabel is the text shown in the companions table for each relation
+var relationLabel = map[companion.Relation]string{
+	companion.Good: "Good neighbour",
+	companion.Bad:  "Keep apart",
+}
+
+// drawCompanions renders the companion planting table, warnings for bad pairs among the plants
+// grown together and plants that would suit the whole planting
+func drawCompanions(pdf *gofpdf.Fpdf, tr func(string) string, plan companion.Plan) {
+	pdf.SetFont("Arial", "B", 12)
+	pdf.Cell(190, 10, "Companion Planting")
+	pdf.Ln(10)
+
+	for _, c := range plan.Conflicts {
+		pdf.SetFont("Arial", "I", 10)
+		pdf.SetTextColor(180, 40, 40)
+		pdf.MultiCell(180, 6, tr(fmt.Sprintf("Warning: %s and %s should not be grown together. %s.",
+			c.Name, c.OtherName, strings.TrimSuffix(c.Reason, "."))), "", "L", false)
+		pdf.SetTextColor(0, 0, 0)
+	}
+	if len(plan.Conflicts) > 0 {
+		pdf.Ln(2)
+	}
+
+	pdf.SetFont("Arial", "", 11)
+	for _, n := range plan.Neighbours {
+		pdf.Cell(50, 8, tr(n.Name))
+		pdf.Cell(35, 8, relationLabel[n.Relation])
+		pdf.Cell(95, 8, tr(n.Reason))
+		pdf.Ln(8)
+	}
+
+	if len(plan.Suggestions) > 0 {
+		pdf.Ln(2)
+		pdf.SetFont("Arial", "B", 11)
+		pdf.Cell(190, 8, "Also good with this planting")
+		pdf.Ln(8)
+		pdf.SetFont("Arial", "", 11)
+		for _, s := range plan.Suggestions {
+			pdf.Cell(50, 8, tr(s.Name))
+			pdf.Cell(130, 8, tr(s.Reason))
+			pdf.Ln(8)
+		}
+	}
+	pdf.Ln(4)
+}
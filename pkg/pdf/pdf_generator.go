@@ -1,11 +1,25 @@
 package pdf
 
 import (
+	"io"
+
 	models "github.com/HealthyTechGuy/plant-report-app/models" // Import shared models
 )
 
-// PDFGenerator defines the methods for generating PDF reports
-type PDFGenerator interface {
-	GeneratePDF(userLocation models.UserLocation, plantInfo models.PlantInfo) ([]byte, error)
-	UploadToS3(data []byte, bucket, key string) (string, error)
+// TemplateVersion identifies the report layout. Bump it whenever the rendered output changes
+// so cached reports built from the old layout are no longer served.
+const TemplateVersion = "4"
+
+// Language is the language reports are written in
+const Language = "en"
+
+// Renderer renders a report into PDF bytes
+type Renderer interface {
+	GeneratePDF(report models.Report) ([]byte, error)
+}
+
+// StreamRenderer renders a report straight into w, so it can be uploaded while it is written
+// rather than held in memory as a whole first
+type StreamRenderer interface {
+	RenderPDF(w io.Writer, report models.Report) error
 }
@@ -3,21 +3,43 @@ package pdf
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"log"
 
 	models "github.com/HealthyTechGuy/plant-report-app/models"
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/units"
 	"github.com/jung-kurt/gofpdf"
 )
 
-// PDFService is a concrete implementation of the PDFGenerator interface
+// PDFService renders reports with gofpdf, it implements Renderer and StreamRenderer
 type PDFService struct{}
 
+// NewPDFService creates a PDFService
+func NewPDFService() *PDFService {
+	return &PDFService{}
+}
+
 // GeneratePDF creates a nicely formatted PDF report for given plant information
-func (s *PDFService) GeneratePDF(userLocation models.UserLocation, plantInfo models.PlantInfo) ([]byte, error) {
+func (s *PDFService) GeneratePDF(report models.Report) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := s.RenderPDF(&buf, report); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// RenderPDF writes the PDF report to w
+func (s *PDFService) RenderPDF(w io.Writer, report models.Report) error {
+	userLocation := report.Location
+	plantInfo := report.Plant
+	system := report.Units
+	if system == "" {
+		system = units.Metric
+	}
+
 	pdf := gofpdf.New("P", "mm", "A4", "")
+	// Core fonts are cp1252 encoded, translate UTF-8 strings such as "°C"
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
 
 	// Set margins
 	pdf.SetMargins(15, 10, 15)
@@ -30,6 +52,11 @@ func (s *PDFService) GeneratePDF(userLocation models.UserLocation, plantInfo mod
 	pdf.Cell(190, 30, "Plant Growth Report") // Adjust y-position to fit after the image
 	pdf.Ln(25)
 
+	// Growability headline, shown when the plant could be scored against local climate
+	if report.Suitability != nil {
+		drawSuitabilityBox(pdf, tr, *report.Suitability)
+	}
+
 	// Sub-header (Plant name, date, location)
 	pdf.SetFont("Arial", "I", 12)
 	pdf.Cell(190, 8, fmt.Sprintf("Report for: %s", plantInfo.Name))
@@ -37,7 +64,13 @@ func (s *PDFService) GeneratePDF(userLocation models.UserLocation, plantInfo mod
 	pdf.Cell(190, 8, fmt.Sprintf("Location latitude: %.6f", userLocation.UserLatitude)) // Corrected precision formatting for lat/long
 	pdf.Ln(8)
 	pdf.Cell(190, 8, fmt.Sprintf("Location longitude: %.6f", userLocation.UserLongitude))
-	pdf.Ln(12)
+	pdf.Ln(8)
+	// Shared reports are built for a grid cell, so the coordinates above are its centre
+	if report.LocationRounding > 0 {
+		pdf.Cell(190, 8, tr(fmt.Sprintf("Location rounded to the centre of its %g° grid cell", report.LocationRounding)))
+		pdf.Ln(8)
+	}
+	pdf.Ln(4)
 
 	// Plant Information Section
 	pdf.SetFont("Arial", "B", 12)
@@ -57,39 +90,65 @@ func (s *PDFService) GeneratePDF(userLocation models.UserLocation, plantInfo mod
 	pdf.Ln(8)
 	pdf.Cell(50, 10, "Hardiness Zone:")
 	pdf.Cell(100, 10, plantInfo.HardinessZone)
-	pdf.Ln(10)
+	pdf.Ln(8)
+	if plantInfo.Spacing > 0 {
+		pdf.Cell(50, 10, "Spacing:")
+		pdf.Cell(100, 10, system.FormatLength(plantInfo.Spacing))
+		pdf.Ln(8)
+	}
+	if plantInfo.PlantingDepth > 0 {
+		pdf.Cell(50, 10, "Planting Depth:")
+		pdf.Cell(100, 10, system.FormatLength(plantInfo.PlantingDepth))
+		pdf.Ln(8)
+	}
+	if plantInfo.MinTemperature != 0 || plantInfo.MaxTemperature != 0 {
+		pdf.Cell(50, 10, "Temperature Range:")
+		pdf.Cell(100, 10, tr(fmt.Sprintf("%s to %s",
+			system.FormatTemperature(plantInfo.MinTemperature),
+			system.FormatTemperature(plantInfo.MaxTemperature))))
+		pdf.Ln(8)
+	}
+	if plantInfo.AnnualRainfall > 0 {
+		pdf.Cell(50, 10, "Annual Rainfall:")
+		pdf.Cell(100, 10, system.FormatRainfall(plantInfo.AnnualRainfall))
+		pdf.Ln(8)
+	}
+	pdf.Ln(2)
+
+	// Local climate section, shown when climate data is available for the location
+	if report.Climate != nil {
+		drawClimateSection(pdf, tr, *report.Climate, plantInfo, system)
+	}
+
+	// Planting calendar worked out from local frost dates
+	if report.Schedule != nil {
+		drawPlantingCalendar(pdf, *report.Schedule)
+	}
+
+	// Similar plants that grow better here
+	if len(report.Alternatives) > 0 {
+		drawAlternatives(pdf, report.Alternatives)
+	}
+
+	// Pests and diseases ranked by how much the local climate favours them
+	if len(report.PestRisks) > 0 {
+		drawPestRisks(pdf, tr, report.PestRisks, system)
+	}
+
+	// Good and bad neighbours, and warnings for bad pairs among the plants grown together
+	if report.Companions != nil && (len(report.Companions.Neighbours) > 0 || len(report.Companions.Conflicts) > 0) {
+		drawCompanions(pdf, tr, *report.Companions)
+	}
 
 	// Footer
 	pdf.SetY(-15)
 	pdf.SetFont("Arial", "I", 8)
 	pdf.Cell(0, 10, fmt.Sprintf("Page %d", pdf.PageNo()))
 
-	// Output the PDF to a buffer
-	var buf bytes.Buffer
-	err := pdf.Output(&buf)
-	if err != nil {
+	// Output the PDF
+	if err := pdf.Output(w); err != nil {
 		log.Printf("Error generating PDF: %v", err)
-		return nil, err
+		return err
 	}
-
-	return buf.Bytes(), nil
-}
-
-func (s *PDFService) UploadToS3(data []byte, bucket, key string) (string, error) {
-	sess := session.Must(session.NewSession())
-	svc := s3.New(sess)
-
-	_, err := svc.PutObject(&s3.PutObjectInput{
-		Bucket:      aws.String(bucket),
-		Key:         aws.String(key),
-		Body:        bytes.NewReader(data),
-		ContentType: aws.String("application/pdf"),
-	})
-	if err != nil {
-		return "", fmt.Errorf("failed to upload file to S3: %w", err)
-	}
-
-	s3URL := fmt.Sprintf("https://%s.s3.amazonaws.com/%s", bucket, key)
-	log.Printf("File uploaded to: %s", s3URL)
-	return s3URL, nil
+	return nil
 }
@@ -1,9 +1,18 @@
 package pdf
 
 import (
+	"bytes"
+	"context"
 	"testing"
+	"time"
 
 	models "github.com/HealthyTechGuy/plant-report-app/models" // Import shared models
+	"github.com/HealthyTechGuy/plant-report-app/pkg/climate"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/companion"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/frost"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/pests"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/suitability"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/units"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -31,11 +40,56 @@ func TestGeneratePDF_Success(t *testing.T) {
 		UserLongitude: 999.99,
 	}
 
-	pdfBytes, err := pdfService.GeneratePDF(userLocation, plantInfo)
+	pdfBytes, err := pdfService.GeneratePDF(models.Report{Location: userLocation, Plant: plantInfo})
 	require.NoError(t, err)
 	assert.NotEmpty(t, pdfBytes)
 }
 
+func TestGeneratePDF_RoundedLocation(t *testing.T) {
+	pdfService := &PDFService{}
+	report := models.Report{
+		Location:         models.UserLocation{UserLatitude: 40.75, UserLongitude: -74.05},
+		LocationRounding: 0.1,
+		Plant:            models.PlantInfo{ID: "1", Name: "Kale"},
+	}
+
+	pdfBytes, err := pdfService.GeneratePDF(report)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(pdfBytes, []byte("%PDF-")))
+}
+
+func TestRenderPDF_WritesToWriter(t *testing.T) {
+	pdfService := &PDFService{}
+	report := models.Report{Plant: models.PlantInfo{ID: "1", Name: "Blueberry Bush"}}
+
+	var buf bytes.Buffer
+	require.NoError(t, pdfService.RenderPDF(&buf, report))
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")))
+
+	pdfBytes, err := pdfService.GeneratePDF(report)
+	require.NoError(t, err)
+	assert.Equal(t, len(pdfBytes), buf.Len())
+}
+
+func TestGeneratePDF_WithMeasurements(t *testing.T) {
+	pdfService := &PDFService{}
+	plantInfo := models.PlantInfo{
+		ID:             "1",
+		Name:           "Kale",
+		Spacing:        45 * units.Centimetre,
+		PlantingDepth:  1 * units.Centimetre,
+		MinTemperature: units.Celsius(-10),
+		MaxTemperature: units.Celsius(27),
+		AnnualRainfall: 600 * units.Millimetre,
+	}
+
+	for _, system := range []units.System{units.Metric, units.Imperial} {
+		pdfBytes, err := pdfService.GeneratePDF(models.Report{Plant: plantInfo, Units: system})
+		require.NoError(t, err)
+		assert.NotEmpty(t, pdfBytes)
+	}
+}
+
 func TestMockGeneratePDF(t *testing.T) {
 	mockPDFGenerator := &MockPDFGenerator{}
 	plantInfo := models.PlantInfo{
@@ -50,3 +104,78 @@ func TestMockGeneratePDF(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "mock PDF content", string(pdfBytes))
 }
+
+func TestGeneratePDF_WithClimate(t *testing.T) {
+	provider, err := climate.NewEmbeddedProvider()
+	require.NoError(t, err)
+	normals, err := provider.Normals(context.TODO(), 51.5, -0.12)
+	require.NoError(t, err)
+
+	plantInfo := models.PlantInfo{ID: "1", Name: "Kale", AnnualRainfall: 600 * units.Millimetre, DaysToMaturity: 240}
+	schedule := frost.Plan(plantInfo.DaysToMaturity, plantInfo.FrostTolerance, normals.Frost, time.Now())
+	assessment := suitability.Assess(plantInfo.Requirements(), normals, units.Imperial)
+
+	pdfService := &PDFService{}
+	pdfBytes, err := pdfService.GeneratePDF(models.Report{
+		Plant:       plantInfo,
+		Units:       units.Imperial,
+		Climate:     &normals,
+		Schedule:    &schedule,
+		Suitability: &assessment,
+		Alternatives: []models.Alternative{
+			{PlantID: "cabbage", Name: "Cabbage", Score: 88, Verdict: suitability.Yes, Reason: "Also a vegetable"},
+		},
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, pdfBytes)
+}
+
+func TestGeneratePDF_WithCompanions(t *testing.T) {
+	pdfService := &PDFService{}
+	report := models.Report{Plant: models.PlantInfo{ID: "tomato", Name: "Tomato"}}
+	plain, err := pdfService.GeneratePDF(report)
+	require.NoError(t, err)
+
+	report.Companions = &companion.Plan{
+		Neighbours: []companion.Neighbour{
+			{PlantID: "basil", Name: "Basil", Relation: companion.Good, Reason: "Repels whitefly"},
+			{PlantID: "potato", Name: "Potato", Relation: companion.Bad, Reason: "Shares blight"},
+		},
+		Conflicts: []companion.Conflict{
+			{PlantID: "tomato", Name: "Tomato", OtherID: "potato", OtherName: "Potato", Reason: "Shares blight"},
+		},
+		Suggestions: []companion.Candidate{
+			{PlantID: "carrot", Name: "Carrot", Partners: []string{"tomato"}, Reason: "Loosens the soil"},
+		},
+	}
+	pdfBytes, err := pdfService.GeneratePDF(report)
+	require.NoError(t, err)
+	assert.Greater(t, len(pdfBytes), len(plain))
+}
+
+func TestGeneratePDF_WithPestRisks(t *testing.T) {
+	catalog, err := pests.NewEmbeddedCatalog()
+	require.NoError(t, err)
+	provider, err := climate.NewEmbeddedProvider()
+	require.NoError(t, err)
+	normals, err := provider.Normals(context.TODO(), 51.5, -0.12)
+	require.NoError(t, err)
+
+	pdfService := &PDFService{}
+	report := models.Report{Plant: models.PlantInfo{ID: "kale", Name: "Kale"}}
+	plain, err := pdfService.GeneratePDF(report)
+	require.NoError(t, err)
+
+	for _, system := range []units.System{units.Metric, units.Imperial} {
+		report.Units = system
+		report.PestRisks = catalog.Assess("kale", &normals)
+		pdfBytes, err := pdfService.GeneratePDF(report)
+		require.NoError(t, err)
+		assert.Greater(t, len(pdfBytes), len(plain))
+
+		// Without climate data the risks are still listed
+		report.PestRisks = catalog.Assess("kale", nil)
+		_, err = pdfService.GeneratePDF(report)
+		require.NoError(t, err)
+	}
+}
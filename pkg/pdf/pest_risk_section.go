@@ -0,0 +1,64 @@
+package pdf
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/HealthyTechGuy/plant-report-app/pkg/pests"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/units"
+	"github.com/jung-kurt/gofpdf"
+)
+
+// riskStyle is the label and text colour of each risk level
+var riskStyle = map[pests.Level]struct {
+	label   string
+	r, g, b int
+}{
+	pests.High:     {"High risk", 180, 40, 40},
+	pests.Moderate: {"Moderate risk", 190, 120, 0},
+	pests.Low:      {"Low risk", 40, 120, 40},
+	pests.Unknown:  {"Risk unknown", 90, 90, 90},
+}
+
+// drawPestRisks renders the pests and diseases of the plant, ranked by risk under the local climate,
+// with how to recognise, prevent and treat each one
+func drawPestRisks(pdf *gofpdf.Fpdf, tr func(string) string, risks []pests.Risk, system units.System) {
+	pdf.SetFont("Arial", "B", 12)
+	pdf.Cell(190, 10, "Pest and Disease Risk")
+	pdf.Ln(10)
+
+	row := func(label, value string) {
+		if value == "" {
+			return
+		}
+		pdf.SetFont("Arial", "B", 9)
+		pdf.Cell(35, 5, label)
+		pdf.SetFont("Arial", "", 9)
+		pdf.MultiCell(145, 5, tr(value), "", "L", false)
+	}
+
+	for _, risk := range risks {
+		style := riskStyle[risk.Level]
+		pdf.SetFont("Arial", "B", 11)
+		pdf.Cell(110, 7, tr(fmt.Sprintf("%s (%s)", risk.Name, risk.Kind)))
+		pdf.SetTextColor(style.r, style.g, style.b)
+		pdf.Cell(70, 7, style.label)
+		pdf.SetTextColor(0, 0, 0)
+		pdf.Ln(7)
+
+		if len(risk.Months) > 0 {
+			months := make([]string, len(risk.Months))
+			for i, m := range risk.Months {
+				months[i] = m.String()[:3]
+			}
+			row("Most likely in:", strings.Join(months, ", "))
+		}
+		row("Thrives in:", risk.Conditions.Describe(system))
+		row("Symptoms:", risk.Symptoms)
+		row("Prevention:", risk.Prevention)
+		row("Organic treatment:", risk.OrganicTreatment)
+		row("Chemical treatment:", risk.ChemicalTreatment)
+		pdf.Ln(3)
+	}
+	pdf.Ln(2)
+}
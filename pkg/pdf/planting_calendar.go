@@ -0,0 +1,46 @@
+package pdf
+
+import (
+	"time"
+
+	"github.com/HealthyTechGuy/plant-report-app/pkg/frost"
+	"github.com/jung-kurt/gofpdf"
+)
+
+// drawPlantingCalendar renders the sow, transplant and harvest dates worked out from local frost dates
+func drawPlantingCalendar(pdf *gofpdf.Fpdf, schedule frost.Schedule) {
+	pdf.SetFont("Arial", "B", 12)
+	pdf.Cell(190, 10, "Planting Calendar")
+	pdf.Ln(10)
+
+	pdf.SetFont("Arial", "", 11)
+	row := func(label, value string) {
+		pdf.Cell(50, 10, label)
+		pdf.Cell(100, 10, value)
+		pdf.Ln(8)
+	}
+
+	if schedule.FrostFree {
+		row("Frost:", "No frost expected, plant outdoors any time")
+	} else {
+		row("Last spring frost:", formatDate(schedule.LastFrost))
+		row("First fall frost:", formatDate(schedule.FirstFrost))
+		row("Sow indoors:", formatDate(schedule.SowIndoors))
+	}
+	row("Plant outdoors:", formatDate(schedule.Transplant))
+	if !schedule.Harvest.IsZero() {
+		row("Expected harvest:", formatDate(schedule.Harvest))
+	}
+	if !schedule.MaturesBeforeFrost {
+		pdf.SetFont("Arial", "I", 10)
+		pdf.SetTextColor(180, 40, 40)
+		pdf.MultiCell(180, 6, "Warning: the season may be too short for this plant to mature before the first frost. Consider a cold frame, fleece or a faster variety.", "", "L", false)
+		pdf.SetTextColor(0, 0, 0)
+	}
+	pdf.Ln(4)
+}
+
+// formatDate formats a schedule date, e.g. "Apr 15, 2027"
+func formatDate(t time.Time) string {
+	return t.Format("Jan 2, 2006")
+}
@@ -0,0 +1,45 @@
+package pdf
+
+import (
+	"fmt"
+
+	"github.com/HealthyTechGuy/plant-report-app/pkg/suitability"
+	"github.com/jung-kurt/gofpdf"
+)
+
+// verdictStyle is the headline text and background colour for each verdict
+var verdictStyle = map[suitability.Verdict]struct {
+	headline string
+	r, g, b  int
+}{
+	suitability.Yes:      {"Grows well here", 215, 240, 215},
+	suitability.Marginal: {"Marginal, needs extra care", 252, 240, 200},
+	suitability.No:       {"Unlikely to grow here", 248, 215, 210},
+}
+
+// drawSuitabilityBox renders the growability score as a headline box with one line per factor
+func drawSuitabilityBox(pdf *gofpdf.Fpdf, tr func(string) string, assessment suitability.Assessment) {
+	style := verdictStyle[assessment.Verdict]
+	x, y := pdf.GetX(), pdf.GetY()
+	height := 14 + 5*float64(len(assessment.Factors))
+
+	pdf.SetFillColor(style.r, style.g, style.b)
+	pdf.SetDrawColor(160, 160, 160)
+	pdf.Rect(x, y, 180, height, "FD")
+	pdf.SetDrawColor(0, 0, 0)
+
+	pdf.SetXY(x+3, y+3)
+	pdf.SetFont("Arial", "B", 13)
+	pdf.Cell(174, 7, fmt.Sprintf("Growability: %d/100 - %s", assessment.Score, style.headline))
+	pdf.Ln(8)
+
+	pdf.SetFont("Arial", "", 8)
+	for _, f := range assessment.Factors {
+		pdf.SetX(x + 3)
+		pdf.Cell(28, 5, fmt.Sprintf("%s (%d)", f.Name, f.Score))
+		pdf.Cell(146, 5, tr(f.Explanation))
+		pdf.Ln(5)
+	}
+
+	pdf.SetXY(x, y+height+5)
+}
@@ -0,0 +1,199 @@
+// Package pests holds a catalog of the pests and diseases that attack each plant and ranks how
+// much of a risk they are under a location's climate
+package pests
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/HealthyTechGuy/plant-report-app/pkg/climate"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/units"
+)
+
+//go:embed data/pests.json
+var embeddedPests []byte
+
+// Kind says whether an entry is a pest or a disease
+type Kind string
+
+const (
+	// Pest is an insect or other animal that feeds on the plant
+	Pest Kind = "pest"
+	// Disease is a fungal, bacterial or viral infection
+	Disease Kind = "disease"
+)
+
+// Level is how likely a pest or disease is to cause trouble at a location
+type Level string
+
+const (
+	// High means conditions favour it for most of the year
+	High Level = "high"
+	// Moderate means conditions favour it for part of the year
+	Moderate Level = "moderate"
+	// Low means conditions rarely favour it
+	Low Level = "low"
+	// Unknown means no climate data was available to judge the risk
+	Unknown Level = "unknown"
+)
+
+// Months of favourable conditions needed for each level
+const (
+	highMonths     = 4
+	moderateMonths = 2
+)
+
+// Conditions describes the monthly climate in which a pest or disease thrives. A month favours it
+// when its mean temperature is within the range and its rainfall within the limits. Zero rainfall
+// limits are left out.
+type Conditions struct {
+	MinTemperature units.Temperature `json:"min_temp_c"`
+	MaxTemperature units.Temperature `json:"max_temp_c"`
+	MinRainfall    units.Length      `json:"min_rainfall_mm"`
+	MaxRainfall    units.Length      `json:"max_rainfall_mm"`
+}
+
+// Favours reports whether a month's climate favours the pest or disease
+func (c Conditions) Favours(m climate.MonthlyNormal) bool {
+	mean := m.MeanTemp()
+	if mean < c.MinTemperature || mean > c.MaxTemperature {
+		return false
+	}
+	if c.MinRainfall > 0 && m.Rainfall < c.MinRainfall {
+		return false
+	}
+	if c.MaxRainfall > 0 && m.Rainfall > c.MaxRainfall {
+		return false
+	}
+	return true
+}
+
+// Describe explains the conditions in the given measurement system
+func (c Conditions) Describe(system units.System) string {
+	parts := []string{fmt.Sprintf("mean temperatures of %s to %s",
+		system.FormatTemperature(c.MinTemperature), system.FormatTemperature(c.MaxTemperature))}
+	if c.MinRainfall > 0 {
+		parts = append(parts, fmt.Sprintf("over %s of rain a month", system.FormatRainfall(c.MinRainfall)))
+	}
+	if c.MaxRainfall > 0 {
+		parts = append(parts, fmt.Sprintf("under %s of rain a month", system.FormatRainfall(c.MaxRainfall)))
+	}
+	return strings.Join(parts, " and ")
+}
+
+// Entry is a pest or disease and how to deal with it
+type Entry struct {
+	ID                string     `json:"id"`
+	Name              string     `json:"name"`
+	Kind              Kind       `json:"kind"`
+	PlantIDs          []string   `json:"plant_ids"`
+	Symptoms          string     `json:"symptoms"`
+	Prevention        string     `json:"prevention"`
+	OrganicTreatment  string     `json:"organic_treatment"`
+	ChemicalTreatment string     `json:"chemical_treatment"`
+	Conditions        Conditions `json:"conditions"`
+}
+
+// Validate checks that an entry is complete enough to report on
+func (e Entry) Validate() error {
+	switch {
+	case e.ID == "" || e.Name == "":
+		return fmt.Errorf("missing id or name")
+	case e.Kind != Pest && e.Kind != Disease:
+		return fmt.Errorf("kind must be pest or disease, got %q", e.Kind)
+	case len(e.PlantIDs) == 0:
+		return fmt.Errorf("no plant_ids")
+	case e.Conditions.MinTemperature > e.Conditions.MaxTemperature:
+		return fmt.Errorf("min_temp_c is above max_temp_c")
+	}
+	return nil
+}
+
+// Risk is a pest or disease ranked against a location's climate
+type Risk struct {
+	Entry
+	Level Level `json:"level"`
+	// Months are the months whose climate favours it, in calendar order
+	Months []time.Month `json:"months,omitempty"`
+}
+
+// Catalog looks up the pests and diseases of a plant
+type Catalog struct {
+	byPlant map[string][]Entry
+}
+
+// NewCatalog creates a Catalog from entries, rejecting invalid or repeated ones
+func NewCatalog(entries []Entry) (*Catalog, error) {
+	c := &Catalog{byPlant: make(map[string][]Entry)}
+	seen := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		if err := e.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid pest %q: %w", e.ID, err)
+		}
+		if seen[e.ID] {
+			return nil, fmt.Errorf("invalid pest %q: listed twice", e.ID)
+		}
+		seen[e.ID] = true
+		for _, plantID := range e.PlantIDs {
+			c.byPlant[plantID] = append(c.byPlant[plantID], e)
+		}
+	}
+	return c, nil
+}
+
+// NewEmbeddedCatalog creates a Catalog from the bundled pest and disease dataset
+func NewEmbeddedCatalog() (*Catalog, error) {
+	var entries []Entry
+	if err := json.Unmarshal(embeddedPests, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse embedded pest data: %w", err)
+	}
+	return NewCatalog(entries)
+}
+
+// For returns the pests and diseases that attack a plant
+func (c *Catalog) For(plantID string) []Entry {
+	return c.byPlant[plantID]
+}
+
+// Assess ranks the pests and diseases of a plant by how many months of the location's climate
+// favour them, highest risk first and then by name. Without climate normals every risk is
+// Unknown and they are listed by name.
+func (c *Catalog) Assess(plantID string, normals *climate.Normals) []Risk {
+	entries := c.For(plantID)
+	risks := make([]Risk, 0, len(entries))
+	for _, e := range entries {
+		risk := Risk{Entry: e, Level: Unknown}
+		if normals != nil {
+			for _, m := range normals.Months {
+				if e.Conditions.Favours(m) {
+					risk.Months = append(risk.Months, m.Month)
+				}
+			}
+			risk.Level = level(len(risk.Months))
+		}
+		risks = append(risks, risk)
+	}
+	sort.SliceStable(risks, func(i, j int) bool {
+		if len(risks[i].Months) != len(risks[j].Months) {
+			return len(risks[i].Months) > len(risks[j].Months)
+		}
+		return risks[i].Name < risks[j].Name
+	})
+	return risks
+}
+
+// level converts months of favourable conditions into a risk level
+func level(months int) Level {
+	switch {
+	case months >= highMonths:
+		return High
+	case months >= moderateMonths:
+		return Moderate
+	default:
+		return Low
+	}
+}
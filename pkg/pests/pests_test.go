@@ -0,0 +1,86 @@
+package pests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/HealthyTechGuy/plant-report-app/pkg/climate"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/units"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEmbeddedCatalog(t *testing.T) {
+	catalog, err := NewEmbeddedCatalog()
+	require.NoError(t, err)
+	for _, plantID := range []string{"blueberry", "orange", "kale"} {
+		assert.NotEmpty(t, catalog.For(plantID), plantID)
+	}
+	assert.Empty(t, catalog.For("unknown"))
+}
+
+func TestNewCatalog_RejectsInvalidEntries(t *testing.T) {
+	valid := Entry{ID: "aphids", Name: "Aphids", Kind: Pest, PlantIDs: []string{"kale"}}
+	_, err := NewCatalog([]Entry{valid})
+	require.NoError(t, err)
+
+	noPlants := valid
+	noPlants.PlantIDs = nil
+	badKind := valid
+	badKind.Kind = "weed"
+	badRange := valid
+	badRange.Conditions = Conditions{MinTemperature: units.Celsius(20), MaxTemperature: units.Celsius(10)}
+	for _, entries := range [][]Entry{{noPlants}, {badKind}, {badRange}, {valid, valid}} {
+		_, err := NewCatalog(entries)
+		assert.Error(t, err)
+	}
+}
+
+func TestConditions(t *testing.T) {
+	mildAndWet := Conditions{MinTemperature: units.Celsius(10), MaxTemperature: units.Celsius(25), MinRainfall: 80 * units.Millimetre}
+	assert.True(t, mildAndWet.Favours(climate.MonthlyNormal{MinTemp: units.Celsius(10), MaxTemp: units.Celsius(20), Rainfall: 90}))
+	assert.False(t, mildAndWet.Favours(climate.MonthlyNormal{MinTemp: units.Celsius(10), MaxTemp: units.Celsius(20), Rainfall: 50}))
+	assert.False(t, mildAndWet.Favours(climate.MonthlyNormal{MinTemp: units.Celsius(0), MaxTemp: units.Celsius(8), Rainfall: 90}))
+
+	dry := Conditions{MinTemperature: units.Celsius(18), MaxTemperature: units.Celsius(35), MaxRainfall: 60 * units.Millimetre}
+	assert.False(t, dry.Favours(climate.MonthlyNormal{MinTemp: units.Celsius(20), MaxTemp: units.Celsius(30), Rainfall: 100}))
+
+	assert.Equal(t, "mean temperatures of 10 °C to 25 °C and over 80 mm of rain a month", mildAndWet.Describe(units.Metric))
+	assert.Equal(t, "mean temperatures of 64 °F to 95 °F and under 2.4 in of rain a month", dry.Describe(units.Imperial))
+}
+
+func TestCatalog_Assess(t *testing.T) {
+	catalog, err := NewCatalog([]Entry{
+		{ID: "frost-mould", Name: "Frost mould", Kind: Disease, PlantIDs: []string{"kale"},
+			Conditions: Conditions{MinTemperature: units.Celsius(-20), MaxTemperature: units.Celsius(0)}},
+		{ID: "aphids", Name: "Aphids", Kind: Pest, PlantIDs: []string{"kale"},
+			Conditions: Conditions{MinTemperature: units.Celsius(10), MaxTemperature: units.Celsius(26)}},
+		{ID: "blight", Name: "Blight", Kind: Disease, PlantIDs: []string{"kale"},
+			Conditions: Conditions{MinTemperature: units.Celsius(15), MaxTemperature: units.Celsius(20), MinRainfall: 50 * units.Millimetre}},
+	})
+	require.NoError(t, err)
+
+	provider, err := climate.NewEmbeddedProvider()
+	require.NoError(t, err)
+	london, err := provider.Normals(context.TODO(), 51.5, -0.12)
+	require.NoError(t, err)
+
+	risks := catalog.Assess("kale", &london)
+	require.Len(t, risks, 3)
+	assert.Equal(t, "aphids", risks[0].ID)
+	assert.Equal(t, High, risks[0].Level)
+	assert.Equal(t, []time.Month{time.April, time.May, time.June, time.July, time.August, time.September, time.October}, risks[0].Months)
+	assert.Equal(t, "blight", risks[1].ID)
+	assert.Equal(t, []time.Month{time.August}, risks[1].Months)
+	assert.Equal(t, Low, risks[1].Level)
+	assert.Equal(t, "frost-mould", risks[2].ID)
+	assert.Equal(t, Low, risks[2].Level)
+	assert.Empty(t, risks[2].Months)
+
+	// Without climate data the risks are listed by name
+	risks = catalog.Assess("kale", nil)
+	require.Len(t, risks, 3)
+	assert.Equal(t, []string{"aphids", "blight", "frost-mould"}, []string{risks[0].ID, risks[1].ID, risks[2].ID})
+	assert.Equal(t, Unknown, risks[0].Level)
+}
@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryStore is an in-memory Store for tests and local development
+type MemoryStore struct {
+	mu       sync.RWMutex
+	objects  map[string][]byte
+	modified map[string]time.Time
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{objects: make(map[string][]byte), modified: make(map[string]time.Time)}
+}
+
+// Put reads body and stores it under key
+func (s *MemoryStore) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = data
+	s.modified[key] = time.Now()
+	return nil
+}
+
+// Get returns a copy of the object stored under key
+func (s *MemoryStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data, ok := s.objects[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// List returns the objects whose keys start with prefix, in key order
+func (s *MemoryStore) List(ctx context.Context, prefix string) ([]Object, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var objects []Object
+	for key, data := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, Object{Key: key, Size: int64(len(data)), LastModified: s.modified[key]})
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+// Delete removes the object stored under key
+func (s *MemoryStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	delete(s.modified, key)
+	return nil
+}
+
+// SetModified backdates an object, for tests of what happens to old objects
+func (s *MemoryStore) SetModified(key string, modified time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.objects[key]; ok {
+		s.modified[key] = modified
+	}
+}
+
+// URL returns a memory:// URL for the object
+func (s *MemoryStore) URL(key string) string {
+	return "memory://" + key
+}
+
+// PresignURL returns the memory:// URL with the link lifetime as a query parameter
+func (s *MemoryStore) PresignURL(key string, ttl time.Duration) (string, error) {
+	return s.URL(key) + "?expires_in=" + ttl.String(), nil
+}
+
+// Keys returns the keys of all stored objects
+func (s *MemoryStore) Keys() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	keys := make([]string, 0, len(s.objects))
+	for key := range s.objects {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/HealthyTechGuy/plant-report-app/pkg/awsclient"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager/s3manageriface"
+)
+
+// Multipart upload settings. At most uploadPartSize * uploadConcurrency bytes of a streamed
+// object are held in memory at once, however large it is.
+const (
+	uploadPartSize    = s3manager.MinUploadPartSize
+	uploadConcurrency = 2
+)
+
+// S3Store is a Store backed by an S3 bucket
+type S3Store struct {
+	client    s3iface.S3API
+	uploader  s3manageriface.UploaderAPI
+	bucket    string
+	objectURL awsclient.ObjectURLFunc
+}
+
+// NewS3Store creates an S3Store for the given bucket using a shared client.
+// objectURL builds the links handed out by URL, nil uses awsclient.DefaultObjectURL.
+func NewS3Store(client s3iface.S3API, bucket string, objectURL awsclient.ObjectURLFunc) *S3Store {
+	return &S3Store{
+		client: client,
+		uploader: s3manager.NewUploaderWithClient(client, func(u *s3manager.Uploader) {
+			u.PartSize = uploadPartSize
+			u.Concurrency = uploadConcurrency
+		}),
+		bucket:    bucket,
+		objectURL: objectURL,
+	}
+}
+
+// Put uploads an object as it is read from body. Objects larger than one part are sent as a
+// multipart upload, which is aborted if body fails.
+func (s *S3Store) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	_, err := s.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        body,
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload %s to S3: %w", key, err)
+	}
+	return nil
+}
+
+// Get downloads an object, returning ErrNotFound when it does not exist
+func (s *S3Store) Get(ctx context.Context, key string) ([]byte, error) {
+	out, err := s.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var aerr awserr.Error
+		if errors.As(err, &aerr) && aerr.Code() == s3.ErrCodeNoSuchKey {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get %s from S3: %w", key, err)
+	}
+	defer out.Body.Close()
+	return io.ReadAll(out.Body)
+}
+
+// List returns the objects whose keys start with prefix, in key order
+func (s *S3Store) List(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	err := s.client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, obj := range page.Contents {
+			objects = append(objects, Object{
+				Key:          aws.StringValue(obj.Key),
+				Size:         aws.Int64Value(obj.Size),
+				LastModified: aws.TimeValue(obj.LastModified),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s* in S3: %w", prefix, err)
+	}
+	return objects, nil
+}
+
+// Delete removes an object. In a versioned bucket this adds a delete marker, the bucket's
+// lifecycle rules purge the older versions.
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	_, err := s.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete %s from S3: %w", key, err)
+	}
+	return nil
+}
+
+// PresignURL returns a link that downloads the object without credentials until ttl has passed
+func (s *S3Store) PresignURL(key string, ttl time.Duration) (string, error) {
+	req, _ := s.client.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	url, err := req.Presign(ttl)
+	if err != nil {
+		return "", fmt.Errorf("failed to presign %s: %w", key, err)
+	}
+	return url, nil
+}
+
+// URL returns the location of an object in the bucket
+func (s *S3Store) URL(key string) string {
+	if s.objectURL == nil {
+		return awsclient.DefaultObjectURL(s.bucket, key)
+	}
+	return s.objectURL(s.bucket, key)
+}
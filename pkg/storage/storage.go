@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when an object does not exist
+	ErrNotFound = errors.New("object not found")
+)
+
+// Store defines the methods for persisting generated reports. Put reads body to the end, so a
+// report can be uploaded while it is still being rendered into the other end of a pipe.
+type Store interface {
+	Put(ctx context.Context, key string, body io.Reader, contentType string) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	// List returns the objects whose keys start with prefix, in key order
+	List(ctx context.Context, prefix string) ([]Object, error)
+	// Delete removes an object, deleting one that does not exist is not an error
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
+
+// Object describes a stored object
+type Object struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// Presigner is implemented by stores that can hand out time-limited download links
+type Presigner interface {
+	PresignURL(key string, ttl time.Duration) (string, error)
+}
@@ -0,0 +1,239 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager/s3manageriface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeS3 implements the subset of s3iface.S3API used by S3Store on top of a MemoryStore
+type fakeS3 struct {
+	s3iface.S3API
+	objects *MemoryStore
+}
+
+// fakeUploader stores uploads in a MemoryStore
+type fakeUploader struct {
+	s3manageriface.UploaderAPI
+	objects *MemoryStore
+}
+
+func (f fakeUploader) UploadWithContext(ctx aws.Context, in *s3manager.UploadInput, _ ...func(*s3manager.Uploader)) (*s3manager.UploadOutput, error) {
+	return &s3manager.UploadOutput{}, f.objects.Put(ctx, *in.Key, in.Body, aws.StringValue(in.ContentType))
+}
+
+// multipartS3 records the multipart upload API calls made by s3manager. Other calls go to a real
+// client that is never sent anything, s3manager only builds a presigned link with it.
+type multipartS3 struct {
+	s3iface.S3API
+	mu        sync.Mutex
+	parts     map[int64][]byte
+	completed []byte
+	aborted   bool
+}
+
+func newMultipartS3() *multipartS3 {
+	sess := session.Must(session.NewSession(aws.NewConfig().
+		WithRegion("eu-west-2").
+		WithCredentials(credentials.NewStaticCredentials("AKID", "SECRET", ""))))
+	return &multipartS3{S3API: s3.New(sess), parts: make(map[int64][]byte)}
+}
+
+func (f *multipartS3) CreateMultipartUploadWithContext(ctx aws.Context, in *s3.CreateMultipartUploadInput, _ ...request.Option) (*s3.CreateMultipartUploadOutput, error) {
+	return &s3.CreateMultipartUploadOutput{UploadId: aws.String("upload-1")}, nil
+}
+
+func (f *multipartS3) UploadPartWithContext(ctx aws.Context, in *s3.UploadPartInput, _ ...request.Option) (*s3.UploadPartOutput, error) {
+	data, err := io.ReadAll(in.Body)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.parts[*in.PartNumber] = data
+	return &s3.UploadPartOutput{ETag: aws.String(fmt.Sprintf("etag-%d", *in.PartNumber))}, nil
+}
+
+func (f *multipartS3) CompleteMultipartUploadWithContext(ctx aws.Context, in *s3.CompleteMultipartUploadInput, _ ...request.Option) (*s3.CompleteMultipartUploadOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, part := range in.MultipartUpload.Parts {
+		f.completed = append(f.completed, f.parts[*part.PartNumber]...)
+	}
+	return &s3.CompleteMultipartUploadOutput{}, nil
+}
+
+func (f *multipartS3) AbortMultipartUploadWithContext(ctx aws.Context, in *s3.AbortMultipartUploadInput, _ ...request.Option) (*s3.AbortMultipartUploadOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.aborted = true
+	return &s3.AbortMultipartUploadOutput{}, nil
+}
+
+func (f *fakeS3) GetObjectWithContext(ctx aws.Context, in *s3.GetObjectInput, _ ...request.Option) (*s3.GetObjectOutput, error) {
+	data, err := f.objects.Get(ctx, *in.Key)
+	if err != nil {
+		return nil, awserr.New(s3.ErrCodeNoSuchKey, "not found", nil)
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(data))}, nil
+}
+
+func (f *fakeS3) ListObjectsV2PagesWithContext(ctx aws.Context, in *s3.ListObjectsV2Input, fn func(*s3.ListObjectsV2Output, bool) bool, _ ...request.Option) error {
+	var contents []*s3.Object
+	for _, key := range f.objects.Keys() {
+		if bytes.HasPrefix([]byte(key), []byte(*in.Prefix)) {
+			data, _ := f.objects.Get(ctx, key)
+			contents = append(contents, &s3.Object{Key: aws.String(key), Size: aws.Int64(int64(len(data))), LastModified: aws.Time(time.Now())})
+		}
+	}
+	// S3 lists keys in order
+	sort.Slice(contents, func(i, j int) bool { return *contents[i].Key < *contents[j].Key })
+	fn(&s3.ListObjectsV2Output{Contents: contents}, true)
+	return nil
+}
+
+func (f *fakeS3) DeleteObjectWithContext(ctx aws.Context, in *s3.DeleteObjectInput, _ ...request.Option) (*s3.DeleteObjectOutput, error) {
+	return &s3.DeleteObjectOutput{}, f.objects.Delete(ctx, *in.Key)
+}
+
+func TestStores(t *testing.T) {
+	s3Objects := NewMemoryStore()
+	fileStore, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	stores := map[string]Store{
+		"memory": NewMemoryStore(),
+		"file":   fileStore,
+		"s3": &S3Store{
+			client:   &fakeS3{objects: s3Objects},
+			uploader: fakeUploader{objects: s3Objects},
+			bucket:   "plant-report-bucket",
+		},
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.TODO()
+			require.NoError(t, store.Put(ctx, "reports/kale/a.pdf", strings.NewReader("a"), "application/pdf"))
+			require.NoError(t, store.Put(ctx, "reports/kale/b.pdf", strings.NewReader("b"), "application/pdf"))
+			require.NoError(t, store.Put(ctx, "reports/orange/c.pdf", strings.NewReader("c"), "application/pdf"))
+
+			data, err := store.Get(ctx, "reports/kale/a.pdf")
+			require.NoError(t, err)
+			assert.Equal(t, "a", string(data))
+
+			_, err = store.Get(ctx, "reports/missing.pdf")
+			assert.ErrorIs(t, err, ErrNotFound)
+
+			objects, err := store.List(ctx, "reports/kale/")
+			require.NoError(t, err)
+			require.Len(t, objects, 2)
+			assert.Equal(t, "reports/kale/a.pdf", objects[0].Key)
+			assert.Equal(t, "reports/kale/b.pdf", objects[1].Key)
+			assert.Equal(t, int64(1), objects[0].Size)
+			assert.WithinDuration(t, time.Now(), objects[0].LastModified, time.Minute)
+
+			require.NoError(t, store.Put(ctx, "reports/kale/c.pdf", strings.NewReader("c"), "application/pdf"))
+			require.NoError(t, store.Delete(ctx, "reports/kale/c.pdf"))
+			_, err = store.Get(ctx, "reports/kale/c.pdf")
+			assert.ErrorIs(t, err, ErrNotFound)
+			assert.NoError(t, store.Delete(ctx, "reports/kale/c.pdf"))
+		})
+	}
+}
+
+func TestS3Store_PutStreamsMultipart(t *testing.T) {
+	client := newMultipartS3()
+	store := NewS3Store(client, "plant-report-bucket", nil)
+
+	// A body without a known length, like a report still being rendered, over two parts long
+	report := bytes.Repeat([]byte("%PDF-1.3 "), int(2*uploadPartSize+1024)/9)
+	pr, pw := io.Pipe()
+	go func() {
+		_, err := io.Copy(pw, bytes.NewReader(report))
+		pw.CloseWithError(err)
+	}()
+
+	require.NoError(t, store.Put(context.TODO(), "reports/big.pdf", pr, "application/pdf"))
+	assert.Len(t, client.parts, 3)
+	assert.Equal(t, report, client.completed)
+}
+
+func TestS3Store_PutAbortsFailedUpload(t *testing.T) {
+	client := newMultipartS3()
+	store := NewS3Store(client, "plant-report-bucket", nil)
+
+	failed := errors.New("renderer failed")
+	pr, pw := io.Pipe()
+	go func() {
+		_, _ = pw.Write(bytes.Repeat([]byte("x"), int(uploadPartSize)+1))
+		pw.CloseWithError(failed)
+	}()
+
+	err := store.Put(context.TODO(), "reports/broken.pdf", pr, "application/pdf")
+	assert.ErrorContains(t, err, "renderer failed")
+	assert.True(t, client.aborted)
+}
+
+func TestFileStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put(context.TODO(), "users/u1/r1.pdf", strings.NewReader("%PDF"), "application/pdf"))
+	data, err := os.ReadFile(filepath.Join(dir, "users", "u1", "r1.pdf"))
+	require.NoError(t, err)
+	assert.Equal(t, "%PDF", string(data))
+	assert.Equal(t, "file://"+filepath.ToSlash(dir)+"/users/u1/r1.pdf", store.URL("users/u1/r1.pdf"))
+
+	// A failed body leaves no object behind
+	err = store.Put(context.TODO(), "users/u1/r2.pdf", iotest.ErrReader(errors.New("boom")), "application/pdf")
+	assert.ErrorContains(t, err, "boom")
+	_, err = store.Get(context.TODO(), "users/u1/r2.pdf")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.Error(t, store.Put(context.TODO(), "../escape.pdf", strings.NewReader("x"), "application/pdf"))
+}
+
+func TestS3Store_URL(t *testing.T) {
+	store := &S3Store{bucket: "plant-report-bucket"}
+	assert.Equal(t, "https://plant-report-bucket.s3.amazonaws.com/reports/kale.pdf", store.URL("reports/kale.pdf"))
+}
+
+func TestS3Store_PresignURL(t *testing.T) {
+	sess := session.Must(session.NewSession(aws.NewConfig().
+		WithRegion("eu-west-2").
+		WithCredentials(credentials.NewStaticCredentials("AKID", "SECRET", ""))))
+	store := NewS3Store(s3.New(sess), "plant-report-bucket", nil)
+
+	link, err := store.PresignURL("users/u1/r1.pdf", 15*time.Minute)
+	require.NoError(t, err)
+
+	parsed, err := url.Parse(link)
+	require.NoError(t, err)
+	assert.Equal(t, "/users/u1/r1.pdf", parsed.Path)
+	assert.Equal(t, "900", parsed.Query().Get("X-Amz-Expires"))
+	assert.NotEmpty(t, parsed.Query().Get("X-Amz-Signature"))
+}
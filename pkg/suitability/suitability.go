@@ -0,0 +1,298 @@
+package suitability
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/HealthyTechGuy/plant-report-app/pkg/climate"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/units"
+)
+
+// Verdict is the headline answer to "will this plant grow here?"
+type Verdict string
+
+const (
+	// Yes means the plant should grow well at the location
+	Yes Verdict = "yes"
+	// Marginal means the plant may grow with extra care or protection
+	Marginal Verdict = "marginal"
+	// No means the plant is unlikely to grow at the location
+	No Verdict = "no"
+)
+
+// Score thresholds for each verdict
+const (
+	yesThreshold      = 70
+	marginalThreshold = 40
+)
+
+// Requirements describes what a plant needs from its climate. Zero values mean "not known"
+// and the corresponding factor is left out of the score.
+type Requirements struct {
+	HardinessZone  string
+	ChillHours     int
+	MaxTemperature units.Temperature
+	AnnualRainfall units.Length
+	DaysToMaturity int
+}
+
+// Factor is one scored comparison between a requirement and the local climate
+type Factor struct {
+	Name        string `json:"name"`
+	Score       int    `json:"score"`
+	Weight      int    `json:"weight"`
+	Explanation string `json:"explanation"`
+}
+
+// Assessment is the overall 0-100 growability score with the factors behind it
+type Assessment struct {
+	Score   int      `json:"score"`
+	Verdict Verdict  `json:"verdict"`
+	Factors []Factor `json:"factors"`
+}
+
+// Assess scores how well a plant with the given requirements suits a location's climate.
+// Explanations are written in the given measurement system.
+func Assess(req Requirements, normals climate.Normals, system units.System) Assessment {
+	if err := normals.Validate(); err != nil {
+		return insufficientData("Not enough climate data for this location")
+	}
+
+	var factors []Factor
+	for _, score := range []func(Requirements, climate.Normals, units.System) (Factor, bool){
+		scoreZone,
+		scoreChill,
+		scoreHeat,
+		scoreRainfall,
+		scoreSeason,
+	} {
+		if f, ok := score(req, normals, system); ok {
+			factors = append(factors, f)
+		}
+	}
+
+	if len(factors) == 0 {
+		return insufficientData("Not enough plant data to compare against the local climate")
+	}
+
+	var total, weights float64
+	limited := false
+	for _, f := range factors {
+		total += float64(f.Score * f.Weight)
+		weights += float64(f.Weight)
+		if f.Score == 0 {
+			limited = true
+		}
+	}
+	score := int(math.Round(total / weights))
+	// A single failing factor, such as winters far too cold, can't be outweighed by the rest
+	if limited && score >= marginalThreshold {
+		score = marginalThreshold - 1
+	}
+
+	return Assessment{
+		Score:   score,
+		Verdict: verdictFor(score),
+		Factors: factors,
+	}
+}
+
+// insufficientData returns a neutral assessment when there is nothing to compare
+func insufficientData(explanation string) Assessment {
+	return Assessment{
+		Score:   50,
+		Verdict: Marginal,
+		Factors: []Factor{{Name: "Data", Score: 50, Explanation: explanation}},
+	}
+}
+
+func verdictFor(score int) Verdict {
+	switch {
+	case score >= yesThreshold:
+		return Yes
+	case score >= marginalThreshold:
+		return Marginal
+	default:
+		return No
+	}
+}
+
+var zonePattern = regexp.MustCompile(`\d+`)
+
+// ParseZoneRange parses hardiness zones such as "5", "3-7" or "5a - 8b" into a min and max zone
+func ParseZoneRange(s string) (min, max int, ok bool) {
+	matches := zonePattern.FindAllString(s, 2)
+	if len(matches) == 0 {
+		return 0, 0, false
+	}
+	min, _ = strconv.Atoi(matches[0])
+	max = min
+	if len(matches) == 2 {
+		max, _ = strconv.Atoi(matches[1])
+	}
+	if min > max {
+		min, max = max, min
+	}
+	return min, max, true
+}
+
+// extremeMinOffset approximates the gap between the coldest monthly mean minimum
+// and the average annual extreme minimum that hardiness zones are based on
+const extremeMinOffset = 10.0
+
+// EstimateZone estimates the USDA hardiness zone (1-13) from climate normals
+func EstimateZone(normals climate.Normals) int {
+	extremeMin := units.Celsius(coldest(normals).Celsius() - extremeMinOffset)
+	// Zone 1 starts at -60°F and each zone spans 10°F
+	zone := int(math.Floor((extremeMin.Fahrenheit()+60)/10)) + 1
+	return int(math.Max(1, math.Min(13, float64(zone))))
+}
+
+func scoreZone(req Requirements, normals climate.Normals, _ units.System) (Factor, bool) {
+	min, max, ok := ParseZoneRange(req.HardinessZone)
+	if !ok {
+		return Factor{}, false
+	}
+	zone := EstimateZone(normals)
+
+	f := Factor{Name: "Hardiness zone", Weight: 30}
+	switch {
+	case zone < min:
+		f.Score = clamp(100 - 50*(min-zone))
+		f.Explanation = fmt.Sprintf("Your location is about zone %d, colder than the zone %d-%d this plant needs", zone, min, max)
+	case zone > max:
+		f.Score = clamp(100 - 50*(zone-max))
+		f.Explanation = fmt.Sprintf("Your location is about zone %d, warmer than the zone %d-%d this plant prefers", zone, min, max)
+	default:
+		f.Score = 100
+		f.Explanation = fmt.Sprintf("Your location is about zone %d, within the plant's zone %d-%d range", zone, min, max)
+	}
+	return f, true
+}
+
+func scoreChill(req Requirements, normals climate.Normals, _ units.System) (Factor, bool) {
+	if req.ChillHours <= 0 {
+		return Factor{}, false
+	}
+	available := EstimateChillHours(normals)
+	ratio := float64(available) / float64(req.ChillHours)
+
+	f := Factor{Name: "Chill hours", Weight: 15}
+	f.Score = clamp(int(math.Round((ratio - 0.5) / 0.5 * 100)))
+	if ratio >= 1 {
+		f.Explanation = fmt.Sprintf("About %d winter chill hours, enough for the %d this plant needs to fruit", available, req.ChillHours)
+	} else {
+		f.Explanation = fmt.Sprintf("Only about %d winter chill hours, short of the %d this plant needs to fruit", available, req.ChillHours)
+	}
+	return f, true
+}
+
+// EstimateChillHours estimates the yearly hours between 0 and 7°C, assuming temperatures
+// move evenly between each month's mean minimum and maximum
+func EstimateChillHours(normals climate.Normals) int {
+	const low, high = 0.0, 7.0
+	var hours float64
+	for _, m := range normals.Months {
+		lo, hi := m.MinTemp.Celsius(), m.MaxTemp.Celsius()
+		var fraction float64
+		if hi <= lo {
+			if lo >= low && lo <= high {
+				fraction = 1
+			}
+		} else {
+			overlap := math.Min(hi, high) - math.Max(lo, low)
+			fraction = math.Max(0, overlap) / (hi - lo)
+		}
+		hours += fraction * 24 * float64(daysIn(m))
+	}
+	return int(math.Round(hours))
+}
+
+func scoreHeat(req Requirements, normals climate.Normals, system units.System) (Factor, bool) {
+	if req.MaxTemperature == 0 {
+		return Factor{}, false
+	}
+	hottest := warmest(normals)
+	excess := hottest.Celsius() - req.MaxTemperature.Celsius()
+
+	f := Factor{Name: "Heat tolerance", Weight: 20}
+	f.Score = clamp(int(math.Round(100 - 20*math.Max(0, excess))))
+	if excess <= 0 {
+		f.Explanation = fmt.Sprintf("Summer highs of %s stay below the plant's %s limit",
+			system.FormatTemperature(hottest), system.FormatTemperature(req.MaxTemperature))
+	} else {
+		f.Explanation = fmt.Sprintf("Summer highs of %s exceed the plant's %s limit, provide shade and water",
+			system.FormatTemperature(hottest), system.FormatTemperature(req.MaxTemperature))
+	}
+	return f, true
+}
+
+func scoreRainfall(req Requirements, normals climate.Normals, system units.System) (Factor, bool) {
+	if req.AnnualRainfall <= 0 {
+		return Factor{}, false
+	}
+	local := normals.AnnualRainfall()
+	ratio := float64(local) / float64(req.AnnualRainfall)
+
+	f := Factor{Name: "Rainfall", Weight: 15}
+	switch {
+	case ratio < 0.75:
+		f.Score = clamp(int(math.Round(ratio / 0.75 * 100)))
+		f.Explanation = fmt.Sprintf("%s of rain a year is below the %s this plant needs, plan to irrigate",
+			system.FormatRainfall(local), system.FormatRainfall(req.AnnualRainfall))
+	case ratio > 1.5:
+		f.Score = int(math.Max(40, math.Round(100-(ratio-1.5)*50)))
+		f.Explanation = fmt.Sprintf("%s of rain a year is well above the %s this plant needs, ensure good drainage",
+			system.FormatRainfall(local), system.FormatRainfall(req.AnnualRainfall))
+	default:
+		f.Score = 100
+		f.Explanation = fmt.Sprintf("%s of rain a year suits the %s this plant needs",
+			system.FormatRainfall(local), system.FormatRainfall(req.AnnualRainfall))
+	}
+	return f, true
+}
+
+func scoreSeason(req Requirements, normals climate.Normals, _ units.System) (Factor, bool) {
+	if req.DaysToMaturity <= 0 {
+		return Factor{}, false
+	}
+	season := normals.Frost.SeasonLength()
+	ratio := float64(season) / float64(req.DaysToMaturity)
+
+	f := Factor{Name: "Season length", Weight: 20}
+	f.Score = clamp(int(math.Round((ratio - 0.7) / 0.3 * 100)))
+	if ratio >= 1 {
+		f.Explanation = fmt.Sprintf("A %d day frost-free season covers the %d days this plant needs to mature", season, req.DaysToMaturity)
+	} else {
+		f.Explanation = fmt.Sprintf("A %d day frost-free season is shorter than the %d days this plant needs, start indoors or use protection", season, req.DaysToMaturity)
+	}
+	return f, true
+}
+
+func coldest(normals climate.Normals) units.Temperature {
+	t := units.Temperature(math.Inf(1))
+	for _, m := range normals.Months {
+		t = units.Temperature(math.Min(float64(t), float64(m.MinTemp)))
+	}
+	return t
+}
+
+func warmest(normals climate.Normals) units.Temperature {
+	t := units.Temperature(math.Inf(-1))
+	for _, m := range normals.Months {
+		t = units.Temperature(math.Max(float64(t), float64(m.MaxTemp)))
+	}
+	return t
+}
+
+// daysIn returns the number of days in a month of a non-leap year
+func daysIn(m climate.MonthlyNormal) int {
+	return time.Date(2001, m.Month, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, -1).Day()
+}
+
+func clamp(score int) int {
+	return int(math.Max(0, math.Min(100, float64(score))))
+}
@@ -0,0 +1,115 @@
+package suitability
+
+import (
+	"context"
+	"testing"
+
+	"github.com/HealthyTechGuy/plant-report-app/pkg/climate"
+	"github.com/HealthyTechGuy/plant-report-app/pkg/units"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	blueberry = Requirements{
+		HardinessZone:  "3-7",
+		ChillHours:     800,
+		MaxTemperature: units.Celsius(32),
+		AnnualRainfall: 900 * units.Millimetre,
+	}
+	orange = Requirements{
+		HardinessZone:  "9-11",
+		MaxTemperature: units.Celsius(38),
+		AnnualRainfall: 1000 * units.Millimetre,
+		DaysToMaturity: 240,
+	}
+)
+
+func normalsFor(t *testing.T, lat, lon float64) climate.Normals {
+	provider, err := climate.NewEmbeddedProvider()
+	require.NoError(t, err)
+	normals, err := provider.Normals(context.TODO(), lat, lon)
+	require.NoError(t, err)
+	return normals
+}
+
+func TestAssess(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      Requirements
+		lat, lon float64
+		verdict  Verdict
+	}{
+		{"blueberry in New York", blueberry, 40.78, -73.97, Yes},
+		{"blueberry in Miami", blueberry, 25.79, -80.32, No},
+		{"orange in Los Angeles", orange, 34.05, -118.24, Yes},
+		{"orange in Chicago", orange, 41.98, -87.90, No},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assessment := Assess(tt.req, normalsFor(t, tt.lat, tt.lon), units.Metric)
+			assert.Equal(t, tt.verdict, assessment.Verdict, "score %d: %+v", assessment.Score, assessment.Factors)
+			assert.GreaterOrEqual(t, assessment.Score, 0)
+			assert.LessOrEqual(t, assessment.Score, 100)
+			for _, f := range assessment.Factors {
+				assert.NotEmpty(t, f.Explanation)
+			}
+		})
+	}
+}
+
+func TestAssess_SkipsUnknownRequirements(t *testing.T) {
+	assessment := Assess(Requirements{HardinessZone: "7-9"}, normalsFor(t, 51.48, -0.45), units.Metric)
+	require.Len(t, assessment.Factors, 1)
+	assert.Equal(t, "Hardiness zone", assessment.Factors[0].Name)
+	assert.Equal(t, 100, assessment.Score)
+
+	assessment = Assess(Requirements{}, normalsFor(t, 51.48, -0.45), units.Metric)
+	assert.Equal(t, Marginal, assessment.Verdict)
+
+	// Incomplete climate data can't be scored either
+	assessment = Assess(blueberry, climate.Normals{Station: "Empty"}, units.Metric)
+	assert.Equal(t, Marginal, assessment.Verdict)
+	assert.Equal(t, 50, assessment.Score)
+}
+
+func TestAssess_FailingFactorCapsScore(t *testing.T) {
+	// Perfect rainfall and heat can't make up for winters four zones too cold
+	req := Requirements{HardinessZone: "10-11", MaxTemperature: units.Celsius(35), AnnualRainfall: 600 * units.Millimetre}
+	assessment := Assess(req, normalsFor(t, 52.52, 13.40), units.Metric)
+	assert.Equal(t, No, assessment.Verdict)
+	assert.Less(t, assessment.Score, marginalThreshold)
+}
+
+func TestParseZoneRange(t *testing.T) {
+	tests := []struct {
+		in       string
+		min, max int
+		ok       bool
+	}{
+		{"3-7", 3, 7, true},
+		{"5a - 8b", 5, 8, true},
+		{"9", 9, 9, true},
+		{"11-9", 9, 11, true},
+		{"", 0, 0, false},
+	}
+	for _, tt := range tests {
+		min, max, ok := ParseZoneRange(tt.in)
+		assert.Equal(t, tt.ok, ok, tt.in)
+		assert.Equal(t, tt.min, min, tt.in)
+		assert.Equal(t, tt.max, max, tt.in)
+	}
+}
+
+func TestEstimateZone(t *testing.T) {
+	assert.Equal(t, 7, EstimateZone(normalsFor(t, 40.78, -73.97)))  // New York
+	assert.Equal(t, 6, EstimateZone(normalsFor(t, 41.98, -87.90)))  // Chicago
+	assert.Equal(t, 11, EstimateZone(normalsFor(t, 25.79, -80.32))) // Miami
+}
+
+func TestEstimateChillHours(t *testing.T) {
+	// Miami winters are far too warm for chill, Berlin's are long and cool
+	assert.Zero(t, EstimateChillHours(normalsFor(t, 25.79, -80.32)))
+	assert.Greater(t, EstimateChillHours(normalsFor(t, 52.52, 13.40)), 1000)
+}
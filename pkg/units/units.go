@@ -0,0 +1,184 @@
+package units
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// System is a measurement system used when presenting values to a user
+type System string
+
+const (
+	// Metric presents lengths in mm/cm/m and temperatures in Celsius
+	Metric System = "metric"
+	// Imperial presents lengths in inches/feet and temperatures in Fahrenheit
+	Imperial System = "imperial"
+)
+
+// ErrUnknownSystem is returned when a units preference is not recognised
+var ErrUnknownSystem = errors.New("unknown units system")
+
+// Length is a distance or depth stored in millimetres
+type Length float64
+
+// Common length units, usable as multipliers (e.g. 30 * units.Centimetre)
+const (
+	Millimetre Length = 1
+	Centimetre Length = 10
+	Metre      Length = 1000
+	Inch       Length = 25.4
+	Foot       Length = 304.8
+)
+
+// Millimetres returns the length in millimetres
+func (l Length) Millimetres() float64 { return float64(l) }
+
+// Centimetres returns the length in centimetres
+func (l Length) Centimetres() float64 { return float64(l / Centimetre) }
+
+// Metres returns the length in metres
+func (l Length) Metres() float64 { return float64(l / Metre) }
+
+// Inches returns the length in inches
+func (l Length) Inches() float64 { return float64(l / Inch) }
+
+// Feet returns the length in feet
+func (l Length) Feet() float64 { return float64(l / Foot) }
+
+// Temperature is an absolute temperature stored in degrees Celsius
+type Temperature float64
+
+// Celsius creates a Temperature from degrees Celsius
+func Celsius(c float64) Temperature { return Temperature(c) }
+
+// Fahrenheit creates a Temperature from degrees Fahrenheit
+func Fahrenheit(f float64) Temperature { return Temperature((f - 32) * 5 / 9) }
+
+// Celsius returns the temperature in degrees Celsius
+func (t Temperature) Celsius() float64 { return float64(t) }
+
+// Fahrenheit returns the temperature in degrees Fahrenheit
+func (t Temperature) Fahrenheit() float64 { return float64(t)*9/5 + 32 }
+
+// ParseSystem converts a user supplied preference such as "metric" or "imperial" into a System
+func ParseSystem(s string) (System, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "metric", "si":
+		return Metric, nil
+	case "imperial", "us", "customary":
+		return Imperial, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownSystem, s)
+	}
+}
+
+// Resolve returns the System for an explicit preference, falling back to the
+// default for the given coordinates when no preference is supplied
+func Resolve(preference string, latitude, longitude float64) (System, error) {
+	if strings.TrimSpace(preference) == "" {
+		return DefaultFor(latitude, longitude), nil
+	}
+	return ParseSystem(preference)
+}
+
+// DefaultFor returns Imperial for coordinates inside the United States and Metric everywhere else
+func DefaultFor(latitude, longitude float64) System {
+	for _, r := range usRegions {
+		if r.contains(latitude, longitude) {
+			return Imperial
+		}
+	}
+	return Metric
+}
+
+// region is a latitude/longitude bounding box
+type region struct {
+	minLat, maxLat, minLon, maxLon float64
+}
+
+func (r region) contains(lat, lon float64) bool {
+	return lat >= r.minLat && lat <= r.maxLat && lon >= r.minLon && lon <= r.maxLon
+}
+
+// usRegions approximates the United States with bounding boxes. North of 37°N the boxes step
+// down the Canadian border and south of it they step along the Mexican border, so the cities
+// facing each other across it fall on the right side. Border areas may still fall either side,
+// which is why an explicit preference always wins.
+var usRegions = []region{
+	// Canadian border
+	{minLat: 37.0, maxLat: 48.3, minLon: -124.8, maxLon: -123.0}, // Pacific coast, south of Victoria
+	{minLat: 37.0, maxLat: 49.0, minLon: -123.0, maxLon: -95.2},  // Northwest and northern plains to the 49th parallel
+	{minLat: 37.0, maxLat: 48.7, minLon: -95.2, maxLon: -89.5},   // Minnesota
+	{minLat: 37.0, maxLat: 47.5, minLon: -89.5, maxLon: -84.8},   // Wisconsin and Upper Michigan
+	{minLat: 37.0, maxLat: 46.4, minLon: -84.8, maxLon: -82.5},   // Lower Michigan
+	{minLat: 37.0, maxLat: 42.4, minLon: -82.5, maxLon: -79.0},   // Ohio and Pennsylvania, south of Lake Erie
+	{minLat: 37.0, maxLat: 43.5, minLon: -79.0, maxLon: -76.0},   // Western New York, south of Lake Ontario
+	{minLat: 37.0, maxLat: 44.5, minLon: -76.0, maxLon: -75.3},   // Upstate New York, south of the St Lawrence
+	{minLat: 37.0, maxLat: 45.0, minLon: -75.3, maxLon: -71.5},   // New York and Vermont, south of the 45th parallel
+	{minLat: 37.0, maxLat: 45.3, minLon: -71.5, maxLon: -70.0},   // New Hampshire and southern New England
+	{minLat: 43.0, maxLat: 47.4, minLon: -70.0, maxLon: -67.8},   // Maine
+	{minLat: 44.3, maxLat: 45.2, minLon: -67.8, maxLon: -66.9},   // Downeast Maine
+	// Mexican border
+	{minLat: 32.55, maxLat: 37.0, minLon: -124.5, maxLon: -116.0},  // California, north of Tijuana
+	{minLat: 32.68, maxLat: 37.0, minLon: -116.0, maxLon: -114.7},  // Imperial Valley and Nevada, north of Mexicali
+	{minLat: 32.0, maxLat: 37.0, minLon: -114.7, maxLon: -111.0},   // Western Arizona
+	{minLat: 31.33, maxLat: 37.0, minLon: -111.0, maxLon: -108.2},  // Southeastern Arizona
+	{minLat: 31.78, maxLat: 37.0, minLon: -108.2, maxLon: -106.53}, // New Mexico
+	{minLat: 31.74, maxLat: 37.0, minLon: -106.53, maxLon: -106.0}, // El Paso, north of Ciudad Juárez
+	{minLat: 31.0, maxLat: 37.0, minLon: -106.0, maxLon: -104.5},   // West Texas
+	{minLat: 29.8, maxLat: 37.0, minLon: -104.5, maxLon: -102.0},   // Big Bend
+	{minLat: 29.4, maxLat: 37.0, minLon: -102.0, maxLon: -100.5},   // Del Rio
+	{minLat: 27.5, maxLat: 37.0, minLon: -100.5, maxLon: -99.0},    // Laredo
+	{minLat: 26.15, maxLat: 37.0, minLon: -99.0, maxLon: -97.8},    // Rio Grande Valley, north of Reynosa
+	{minLat: 25.89, maxLat: 37.0, minLon: -97.8, maxLon: -93.5},    // Brownsville and the Texas coast
+	// Southeast
+	{minLat: 31.0, maxLat: 37.0, minLon: -93.5, maxLon: -75.0},   // The South and the Mid-Atlantic
+	{minLat: 24.5, maxLat: 31.0, minLon: -93.5, maxLon: -80.0},   // Gulf coast and Florida, west of the Bahamas
+	{minLat: 54.5, maxLat: 71.5, minLon: -168.0, maxLon: -141.0}, // Alaska
+	{minLat: 54.5, maxLat: 60.0, minLon: -141.0, maxLon: -130.0}, // Alaska panhandle
+	{minLat: 18.9, maxLat: 22.3, minLon: -160.3, maxLon: -154.8}, // Hawaii
+}
+
+// FormatLength formats a spacing or depth, e.g. "45 cm", "1.2 m", "18 in" or "3 ft"
+func (s System) FormatLength(l Length) string {
+	if s == Imperial {
+		if l.Inches() >= 24 {
+			return formatNumber(l.Feet(), 1) + " ft"
+		}
+		return formatNumber(l.Inches(), 1) + " in"
+	}
+	if l.Centimetres() >= 100 {
+		return formatNumber(l.Metres(), 2) + " m"
+	}
+	return formatNumber(l.Centimetres(), 1) + " cm"
+}
+
+// FormatRainfall formats a rainfall depth, e.g. "650 mm" or "25.6 in"
+func (s System) FormatRainfall(l Length) string {
+	if s == Imperial {
+		return formatNumber(l.Inches(), 1) + " in"
+	}
+	return formatNumber(l.Millimetres(), 0) + " mm"
+}
+
+// FormatTemperature formats a temperature rounded to whole degrees, e.g. "18 °C" or "64 °F"
+func (s System) FormatTemperature(t Temperature) string {
+	if s == Imperial {
+		return formatNumber(t.Fahrenheit(), 0) + " °F"
+	}
+	return formatNumber(t.Celsius(), 0) + " °C"
+}
+
+// formatNumber rounds v to at most prec decimal places and drops trailing zeros
+func formatNumber(v float64, prec int) string {
+	out := strconv.FormatFloat(v, 'f', prec, 64)
+	if strings.Contains(out, ".") {
+		out = strings.TrimRight(strings.TrimRight(out, "0"), ".")
+	}
+	if out == "-0" {
+		out = "0"
+	}
+	return out
+}
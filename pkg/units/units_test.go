@@ -0,0 +1,93 @@
+package units
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConversions(t *testing.T) {
+	assert.InDelta(t, 2.54, (1 * Inch).Centimetres(), 1e-9)
+	assert.InDelta(t, 12, (1 * Foot).Inches(), 1e-9)
+	assert.InDelta(t, 1.5, (150 * Centimetre).Metres(), 1e-9)
+	assert.InDelta(t, 212, Celsius(100).Fahrenheit(), 1e-9)
+	assert.InDelta(t, 0, Fahrenheit(32).Celsius(), 1e-9)
+}
+
+func TestParseSystem(t *testing.T) {
+	s, err := ParseSystem(" Imperial ")
+	require.NoError(t, err)
+	assert.Equal(t, Imperial, s)
+
+	s, err = ParseSystem("metric")
+	require.NoError(t, err)
+	assert.Equal(t, Metric, s)
+
+	_, err = ParseSystem("cubits")
+	assert.ErrorIs(t, err, ErrUnknownSystem)
+}
+
+func TestDefaultFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		lat, lon float64
+		expected System
+	}{
+		{"New York", 40.71, -74.01, Imperial},
+		{"Denver", 39.74, -104.99, Imperial},
+		{"Miami", 25.76, -80.19, Imperial},
+		{"Anchorage", 61.22, -149.90, Imperial},
+		{"Honolulu", 21.31, -157.86, Imperial},
+		{"Seattle", 47.61, -122.33, Imperial},
+		{"Detroit", 42.33, -83.05, Imperial},
+		{"Buffalo", 42.89, -78.88, Imperial},
+		{"Boston", 42.36, -71.06, Imperial},
+		{"San Diego", 32.72, -117.16, Imperial},
+		{"El Paso", 31.76, -106.49, Imperial},
+		{"Laredo", 27.53, -99.51, Imperial},
+		{"Brownsville", 25.90, -97.50, Imperial},
+		{"London", 51.51, -0.13, Metric},
+		{"Toronto", 43.65, -79.38, Metric},
+		{"Montreal", 45.50, -73.57, Metric},
+		{"Ottawa", 45.42, -75.70, Metric},
+		{"Vancouver", 49.28, -123.12, Metric},
+		{"Victoria", 48.43, -123.37, Metric},
+		{"Tijuana", 32.51, -117.04, Metric},
+		{"Mexicali", 32.62, -115.45, Metric},
+		{"Ciudad Juárez", 31.69, -106.42, Metric},
+		{"Nuevo Laredo", 27.48, -99.51, Metric},
+		{"Matamoros", 25.87, -97.50, Metric},
+		{"Nassau", 25.05, -77.35, Metric},
+		{"Mexico City", 19.43, -99.13, Metric},
+		{"Sydney", -33.87, 151.21, Metric},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, DefaultFor(tt.lat, tt.lon))
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	s, err := Resolve("", 40.71, -74.01)
+	require.NoError(t, err)
+	assert.Equal(t, Imperial, s)
+
+	s, err = Resolve("metric", 40.71, -74.01)
+	require.NoError(t, err)
+	assert.Equal(t, Metric, s)
+}
+
+func TestFormat(t *testing.T) {
+	assert.Equal(t, "45 cm", Metric.FormatLength(45*Centimetre))
+	assert.Equal(t, "2.5 cm", Metric.FormatLength(25*Millimetre))
+	assert.Equal(t, "1.2 m", Metric.FormatLength(120*Centimetre))
+	assert.Equal(t, "18 in", Imperial.FormatLength(18*Inch))
+	assert.Equal(t, "3 ft", Imperial.FormatLength(36*Inch))
+	assert.Equal(t, "650 mm", Metric.FormatRainfall(650*Millimetre))
+	assert.Equal(t, "25.6 in", Imperial.FormatRainfall(650*Millimetre))
+	assert.Equal(t, "18 °C", Metric.FormatTemperature(Celsius(18)))
+	assert.Equal(t, "64 °F", Imperial.FormatTemperature(Celsius(18)))
+}